@@ -1,17 +1,31 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/bangueco/auction-api/internal/config"
+	"github.com/bangueco/auction-api/internal/events"
 	"github.com/bangueco/auction-api/internal/handlers"
 	"github.com/bangueco/auction-api/internal/handlers/helper"
 	"github.com/bangueco/auction-api/internal/lib"
 	"github.com/bangueco/auction-api/internal/middleware"
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/payments"
+	"github.com/bangueco/auction-api/internal/realtime"
 	"github.com/bangueco/auction-api/internal/repositories"
+	"github.com/bangueco/auction-api/internal/scheduler"
 	"github.com/bangueco/auction-api/internal/services"
+	"github.com/bangueco/auction-api/internal/webhooks"
 	"github.com/go-chi/chi/v5"
 	chimiddle "github.com/go-chi/chi/v5/middleware"
 	"github.com/joho/godotenv"
@@ -29,19 +43,175 @@ func main() {
 	// This loads the config from the .env file, without godotenv.Load() this will return empty values
 	cfg := config.Load()
 
+	// Stop the server and background jobs on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Initialize database connection
 	dbpool := lib.InitDBConnection()
+	defer lib.CloseDBConnection()
+
+	// Initialize the in-process event dispatcher that other subsystems subscribe to
+	dispatcher := events.NewDispatcher()
 
 	// Initialize dependencies (handlers, services, repositories)
 	itemRepository := repositories.NewItemRepository(dbpool)
-	itemService := services.NewItemService(itemRepository)
-	itemHandler := handlers.NewItemHandler(itemService)
-
+	bidRepository := repositories.NewBidRepository(dbpool)
 	userRepository := repositories.NewUserRepository(dbpool)
+	lotRepository := repositories.NewLotRepository(dbpool)
+	itemService := services.NewItemService(itemRepository, bidRepository, userRepository, lotRepository)
+
+	// Share bids and closes with the other API instances
+	var eventBus events.Bus
+
+	switch cfg.EVENT_BUS {
+	case "postgres":
+		eventBus = events.NewPostgresBus(dbpool, services.NewEventResolver(itemRepository, bidRepository))
+	case "memory":
+		eventBus = events.NewMemoryBus()
+	default:
+		log.Fatalf("Invalid EVENT_BUS: %q", cfg.EVENT_BUS)
+	}
+
+	eventBus.Connect(dispatcher, events.SharedEventTypes()...)
+
+	// Events are recorded with the changes they describe and relayed onto the dispatcher and webhooks from there
+	outboxRepository := repositories.NewOutboxRepository(dbpool)
+	outboxService := services.NewOutboxService(outboxRepository, dispatcher, webhooks.NewSender(cfg.WEBHOOK_URLS, cfg.WEBHOOK_SECRET), cfg.OUTBOX_BATCH_SIZE, cfg.OUTBOX_POLL_INTERVAL, cfg.OUTBOX_RETENTION)
+
+	exchangeRateRepository := repositories.NewExchangeRateRepository(dbpool)
+	exchangeRateService := services.NewExchangeRateService(exchangeRateRepository)
+	exchangeRateHandler := handlers.NewExchangeRateHandler(exchangeRateService)
+
+	if cfg.FX_RATES_FILE != "" {
+		err = exchangeRateService.LoadFile(cfg.FX_RATES_FILE)
+
+		if err != nil {
+			log.Fatalf("Invalid FX_RATES_FILE: %v", err)
+		}
+	}
+
+	itemHandler := handlers.NewItemHandler(itemService, exchangeRateService)
+
 	userService := services.NewUserService(userRepository)
 
 	authHandler := handlers.NewAuthHandler(userService)
 
+	allocationRepository := repositories.NewAllocationRepository(dbpool)
+	allocationService := services.NewAllocationService(itemRepository, allocationRepository)
+	allocationHandler := handlers.NewAllocationHandler(allocationService)
+
+	orderFee, err := models.ParseMoney(cfg.ORDER_FEE)
+
+	if err != nil || orderFee < 0 {
+		log.Fatalf("Invalid ORDER_FEE: %q, it must be an amount of at least 0", cfg.ORDER_FEE)
+	}
+
+	buyersPremium, err := strconv.ParseFloat(cfg.BUYERS_PREMIUM, 64)
+
+	if err != nil || buyersPremium < 0 || buyersPremium > 1 {
+		log.Fatalf("Invalid BUYERS_PREMIUM: %q, it must be a share between 0 and 1", cfg.BUYERS_PREMIUM)
+	}
+
+	taxRate, err := strconv.ParseFloat(cfg.TAX_RATE, 64)
+
+	if err != nil || taxRate < 0 || taxRate > 1 {
+		log.Fatalf("Invalid TAX_RATE: %q, it must be a share between 0 and 1", cfg.TAX_RATE)
+	}
+
+	orderCharges := services.OrderCharges{
+		BuyersPremiumBps: int64(math.Round(buyersPremium * 10000)),
+		Fee:              orderFee,
+		TaxBps:           int64(math.Round(taxRate * 10000)),
+	}
+	ledgerRepository := repositories.NewLedgerRepository(dbpool)
+	walletService := services.NewWalletService(ledgerRepository, cfg.REQUIRE_FUNDS)
+	walletHandler := handlers.NewWalletHandler(walletService)
+
+	var paymentProvider payments.PaymentProvider
+
+	if cfg.PAYMENT_WEBHOOK_SECRET == "" {
+		log.Fatal("PAYMENT_WEBHOOK_SECRET is required, payment webhooks could be forged without it")
+	}
+
+	switch cfg.PAYMENT_PROVIDER {
+	case "":
+		log.Fatal("PAYMENT_PROVIDER is required")
+	case "fake":
+		if !cfg.ALLOW_FAKE_PAYMENTS {
+			log.Fatal("PAYMENT_PROVIDER=fake approves payments without moving money, set ALLOW_FAKE_PAYMENTS=true to use it for development or tests")
+		}
+
+		log.Print("Using the fake payment provider, orders are paid without moving any money")
+		paymentProvider = payments.NewFakeProvider(cfg.PAYMENT_WEBHOOK_SECRET)
+	default:
+		log.Fatalf("Invalid PAYMENT_PROVIDER: %q", cfg.PAYMENT_PROVIDER)
+	}
+
+	orderRepository := repositories.NewOrderRepository(dbpool)
+	orderService := services.NewOrderService(orderRepository, itemRepository, userRepository, walletService, paymentProvider, outboxService, orderCharges)
+	orderHandler := handlers.NewOrderHandler(orderService)
+
+	auctionService := services.NewAuctionService(itemRepository, bidRepository, allocationRepository, orderService, walletService, outboxService, dispatcher, cfg.CLOSE_BATCH_SIZE)
+
+	defaultIncrements, err := models.ParseIncrementTable(cfg.BID_INCREMENTS)
+
+	if err != nil {
+		log.Fatalf("Invalid BID_INCREMENTS: %v", err)
+	}
+
+	incrementRepository := repositories.NewIncrementRepository(dbpool)
+	incrementService := services.NewIncrementService(incrementRepository, defaultIncrements)
+	incrementHandler := handlers.NewIncrementHandler(incrementService)
+
+	sealedRevisions := models.SealedRevisionRule(cfg.SEALED_BID_REVISIONS)
+
+	if !sealedRevisions.IsValid() {
+		log.Fatalf("Invalid SEALED_BID_REVISIONS: %q", cfg.SEALED_BID_REVISIONS)
+	}
+
+	buyNowThreshold, err := strconv.ParseFloat(cfg.BUY_NOW_THRESHOLD, 64)
+
+	if err != nil || buyNowThreshold <= 0 || buyNowThreshold > 1 {
+		log.Fatalf("Invalid BUY_NOW_THRESHOLD: %q, it must be a share above 0 and at most 1", cfg.BUY_NOW_THRESHOLD)
+	}
+
+	bidRules := services.BidRules{
+		SoftCloseWindow:    cfg.SNIPE_WINDOW,
+		SoftCloseExtension: cfg.SNIPE_EXTENSION,
+		MaxExtensions:      cfg.SNIPE_MAX_EXTENSIONS,
+		BuyNowThresholdBps: int64(math.Round(buyNowThreshold * 10000)),
+		SealedRevisions:    sealedRevisions,
+	}
+	proxyBidRepository := repositories.NewProxyBidRepository(dbpool)
+	bidService := services.NewBidService(bidRepository, itemRepository, userRepository, proxyBidRepository, incrementService, orderService, walletService, outboxService, bidRules)
+	bidHandler := handlers.NewBidHandler(bidService)
+
+	lotService := services.NewLotService(lotRepository, itemRepository, bidRepository, userRepository)
+	lotHandler := handlers.NewLotHandler(lotService, bidHandler)
+
+	catalogRepository := repositories.NewCatalogRepository(dbpool)
+	catalogService := services.NewCatalogService(catalogRepository, lotRepository, itemRepository)
+	catalogHandler := handlers.NewCatalogHandler(catalogService)
+
+	awardRepository := repositories.NewAwardRepository(dbpool)
+	awardService := services.NewAwardService(itemRepository, bidRepository, awardRepository, orderService, outboxService)
+	awardHandler := handlers.NewAwardHandler(awardService)
+
+	liveFeed := realtime.NewHub(dispatcher)
+	eventLog := realtime.NewEventLog(dispatcher, cfg.EVENT_LOG_SIZE, cfg.EVENT_LOG_ITEMS)
+	streamTicketRepository := repositories.NewStreamTicketRepository(dbpool)
+	streamTicketService := services.NewStreamTicketService(streamTicketRepository, cfg.STREAM_TICKET_TTL)
+	feedHandler := handlers.NewFeedHandler(liveFeed, eventLog, itemService, streamTicketService, cfg.ALLOWED_ORIGINS)
+
+	notificationRepository := repositories.NewNotificationRepository(dbpool)
+	notificationService := services.NewNotificationService(notificationRepository, bidRepository, allocationRepository, cfg.NOTIFY_ENDING_SOON)
+	notificationService.Subscribe(dispatcher)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+
+	// Admins are read once at startup, changing ADMIN_USER_IDS needs a restart
+	adminGuard := middleware.AdminGuard(cfg.ADMIN_USER_IDS)
+
 	// Initialize router
 	r := chi.NewRouter()
 	r.Use(chimiddle.Logger)
@@ -61,6 +231,9 @@ func main() {
 		r.Post("/login", authHandler.Login)
 	})
 
+	// EventSource cannot set headers, so the stream also accepts a single-use ticket from POST /api/feed/tickets
+	r.With(middleware.StreamAuth(streamTicketService.RedeemTicket)).Get("/api/items/{id}/events", feedHandler.StreamItemEvents)
+
 	r.Route("/api/items", func(r chi.Router) {
 		r.Use(middleware.AuthGuard)
 		r.Get("/", itemHandler.GetItems)
@@ -68,9 +241,167 @@ func main() {
 		r.Post("/", itemHandler.CreateItem)
 		r.Put("/{id}", itemHandler.UpdateItem)
 		r.Delete("/{id}", itemHandler.DeleteItem)
+		r.Patch("/{id}/status", itemHandler.UpdateItemStatus)
+		r.Get("/{id}/bids", bidHandler.GetBidHistory)
+		r.Post("/{id}/bids", bidHandler.PlaceBid)
+		r.Get("/{id}/leader", bidHandler.GetLeadingBid)
+		r.Post("/{id}/buy-now", bidHandler.BuyNow)
+		r.Post("/{id}/accept", bidHandler.AcceptPrice)
+		r.Get("/{id}/awards", awardHandler.GetAwards)
+		r.Post("/{id}/awards", awardHandler.AwardBid)
+		r.Get("/{id}/allocations", allocationHandler.GetItemAllocations)
+	})
+
+	r.Route("/api/feed", func(r chi.Router) {
+		r.With(middleware.StreamAuth(streamTicketService.RedeemTicket)).Get("/", feedHandler.ServeFeed)
+		r.With(middleware.AuthGuard).Post("/tickets", feedHandler.IssueStreamTicket)
+	})
+
+	r.Route("/api/lots", func(r chi.Router) {
+		r.Use(middleware.AuthGuard)
+		r.Get("/", lotHandler.GetLots)
+		r.Post("/", lotHandler.CreateLot)
+		r.Get("/{id}", lotHandler.GetLotByID)
+		r.Put("/{id}/items", lotHandler.SetLotItems)
+		r.Get("/{id}/bids", lotHandler.GetBidHistory)
+		r.Post("/{id}/bids", lotHandler.PlaceBid)
+	})
+
+	r.Route("/api/catalogs", func(r chi.Router) {
+		r.Use(middleware.AuthGuard)
+		r.Get("/", catalogHandler.GetCatalogs)
+		r.Post("/", catalogHandler.CreateCatalog)
+		r.Get("/{id}", catalogHandler.GetCatalogByID)
+		r.Put("/{id}/lots", catalogHandler.SetCatalogLots)
 	})
 
+	r.Route("/api/orders", func(r chi.Router) {
+		r.Use(middleware.AuthGuard)
+		r.Get("/", orderHandler.GetPurchases)
+		r.Get("/{id}", orderHandler.GetPurchase)
+		r.Get("/{id}/invoice", orderHandler.GetInvoice)
+		r.Post("/{id}/pay", orderHandler.PayOrder)
+	})
+
+	r.Post("/api/payments/webhook", orderHandler.PaymentWebhook)
+
+	r.Route("/api/sales", func(r chi.Router) {
+		r.Use(middleware.AuthGuard)
+		r.Get("/", orderHandler.GetSales)
+		r.Get("/{id}", orderHandler.GetSale)
+		r.Get("/{id}/invoice", orderHandler.GetInvoice)
+		r.Put("/{id}/payment-status", orderHandler.UpdatePaymentStatus)
+	})
+
+	r.Route("/api/wallet", func(r chi.Router) {
+		r.Use(middleware.AuthGuard)
+		r.Get("/", walletHandler.GetWallet)
+		r.Get("/holds", walletHandler.GetHolds)
+		r.Post("/withdrawals", walletHandler.Withdraw)
+	})
+
+	r.Route("/api/ledger", func(r chi.Router) {
+		r.Use(middleware.AuthGuard)
+		r.Use(adminGuard)
+		r.Post("/deposits", walletHandler.Deposit)
+		r.Get("/check", walletHandler.CheckLedger)
+	})
+
+	r.Route("/api/notifications", func(r chi.Router) {
+		r.Use(middleware.AuthGuard)
+		r.Get("/", notificationHandler.GetNotifications)
+		r.Get("/unread-count", notificationHandler.GetUnreadCount)
+		r.Post("/read", notificationHandler.MarkAllRead)
+		r.Post("/{id}/read", notificationHandler.MarkRead)
+		r.Get("/preferences", notificationHandler.GetPreferences)
+		r.Put("/preferences", notificationHandler.UpdatePreferences)
+	})
+
+	r.Route("/api/allocations", func(r chi.Router) {
+		r.Use(middleware.AuthGuard)
+		r.Get("/", allocationHandler.GetMyAllocations)
+	})
+
+	r.Route("/api/increments", func(r chi.Router) {
+		r.Use(middleware.AuthGuard)
+		r.Get("/", incrementHandler.GetIncrementTables)
+
+		r.Group(func(r chi.Router) {
+			r.Use(adminGuard)
+			r.Put("/{category}", incrementHandler.SetCategoryIncrements)
+			r.Delete("/{category}", incrementHandler.DeleteCategoryIncrements)
+		})
+	})
+
+	r.Route("/api/fx-rates", func(r chi.Router) {
+		r.Use(middleware.AuthGuard)
+		r.Get("/", exchangeRateHandler.GetExchangeRates)
+
+		r.Group(func(r chi.Router) {
+			r.Use(adminGuard)
+			r.Put("/", exchangeRateHandler.SetExchangeRates)
+		})
+	})
+
+	// Start background jobs
+	jobs := scheduler.NewScheduler()
+	jobs.Add("activate-scheduled-auctions", cfg.SCHEDULER_INTERVAL, auctionService.ActivateScheduledAuctions)
+	jobs.Add("close-expired-auctions", cfg.SCHEDULER_INTERVAL, auctionService.CloseExpiredAuctions)
+	jobs.Add("publish-price-changes", cfg.SCHEDULER_INTERVAL, auctionService.PublishPriceChanges)
+	jobs.Add("verify-ledger", cfg.LEDGER_CHECK_INTERVAL, walletService.VerifyLedger)
+	jobs.Add("notify-ending-soon", cfg.SCHEDULER_INTERVAL, notificationService.NotifyEndingSoon)
+	jobs.Add("clean-outbox", time.Hour, outboxService.CleanDelivered)
+	jobs.Add("clean-stream-tickets", time.Hour, streamTicketService.CleanExpired)
+	jobs.Start(ctx)
+
+	relayDone := make(chan struct{})
+
+	go func() {
+		defer close(relayDone)
+		outboxService.Run(ctx)
+	}()
+
+	busDone := make(chan struct{})
+
+	go func() {
+		defer close(busDone)
+
+		err := eventBus.Run(ctx)
+
+		if err != nil {
+			log.Printf("Error running event bus: %v", err)
+		}
+	}()
+
 	// Start server
-	log.Printf("Server started on port %s", cfg.PORT)
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%s", cfg.PORT), r))
+	server := &http.Server{Addr: fmt.Sprintf(":%s", cfg.PORT), Handler: r}
+	// Shutdown neither closes hijacked WebSocket connections nor waits out open event streams, end them ourselves
+	server.RegisterOnShutdown(liveFeed.Shutdown)
+	server.RegisterOnShutdown(eventLog.Shutdown)
+
+	go func() {
+		log.Printf("Server started on port %s", cfg.PORT)
+
+		err := server.ListenAndServe()
+
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = server.Shutdown(shutdownCtx)
+
+	if err != nil {
+		log.Printf("Error shutting down server: %v", err)
+	}
+
+	jobs.Wait()
+	<-relayDone
+	<-busDone
 }
@@ -1,25 +1,180 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type Config struct {
-	DATABASE_URL      string
-	DATABASE_NAME     string
-	DATABASE_USER     string
-	DATABASE_PASSWORD string
-	BASE_URL          string
-	PORT              string
-	TOKEN_SECRET      string
+	DATABASE_URL       string
+	DATABASE_NAME      string
+	DATABASE_USER      string
+	DATABASE_PASSWORD  string
+	BASE_URL           string
+	PORT               string
+	TOKEN_SECRET       string
+	SCHEDULER_INTERVAL time.Duration
+	CLOSE_BATCH_SIZE   int
+	// Bids placed within SNIPE_WINDOW of the end push it back by SNIPE_EXTENSION, at most SNIPE_MAX_EXTENSIONS times
+	SNIPE_WINDOW         time.Duration
+	SNIPE_EXTENSION      time.Duration
+	SNIPE_MAX_EXTENSIONS int
+	// Buy-now is withdrawn once the high bid reaches this share of the buy-now price, checked at startup
+	BUY_NOW_THRESHOLD string
+	// Default bid increment table as from:step pairs, for example 0:1,100:5,1000:10
+	BID_INCREMENTS string
+	// Users allowed to manage platform wide settings
+	ADMIN_USER_IDS []int64
+	// Whether sealed bids can be revised: none, increase or any
+	SEALED_BID_REVISIONS string
+	// JSON file of display exchange rates loaded at startup, admins can replace them later
+	FX_RATES_FILE string
+	// Buyer's premium as a share of the hammer price, a flat fee per order and the tax rate charged on both,
+	// checked at startup so a typo stops the server instead of silently charging nothing
+	BUYERS_PREMIUM string
+	ORDER_FEE      string
+	TAX_RATE       string
+	// Whether bids need funds on hold in the bidder's wallet
+	REQUIRE_FUNDS bool
+	// How often the ledger's invariants are checked
+	LEDGER_CHECK_INTERVAL time.Duration
+	// Gateway orders are paid through, required. Only the in-process fake is built in
+	PAYMENT_PROVIDER string
+	// Secret the payment provider signs its webhooks with, required
+	PAYMENT_WEBHOOK_SECRET string
+	// Whether the fake provider, which approves payments without moving any money, may be used
+	ALLOW_FAKE_PAYMENTS bool
+	// Events kept per item for Server-Sent Events clients to replay, for at most EVENT_LOG_ITEMS recently active items
+	EVENT_LOG_SIZE  int
+	EVENT_LOG_ITEMS int
+	// How events reach the other API instances: postgres, or memory for a single instance
+	EVENT_BUS string
+	// How long a ticket for opening the live feed or an event stream stays valid
+	STREAM_TICKET_TTL time.Duration
+	// Browser origins other than the API's own that may open the live feed
+	ALLOWED_ORIGINS []string
+	// How often the outbox relay looks for events, how many it claims at a time and how long delivered events are kept
+	OUTBOX_POLL_INTERVAL time.Duration
+	OUTBOX_BATCH_SIZE    int
+	OUTBOX_RETENTION     time.Duration
+	// Endpoints every domain event is posted to, and the secret the posts are signed with
+	WEBHOOK_URLS   []string
+	WEBHOOK_SECRET string
+	// How long before an auction ends its bidders are notified
+	NOTIFY_ENDING_SOON time.Duration
 }
 
 func Load() *Config {
 	return &Config{
-		DATABASE_URL:      os.Getenv("DATABASE_URL"),
-		PORT:              os.Getenv("PORT"),
-		DATABASE_NAME:     os.Getenv("DATABASE_NAME"),
-		DATABASE_USER:     os.Getenv("DATABASE_USER"),
-		DATABASE_PASSWORD: os.Getenv("DATABASE_PASSWORD"),
-		BASE_URL:          os.Getenv("BASE_URL"),
-		TOKEN_SECRET:      os.Getenv("TOKEN_SECRET"),
+		DATABASE_URL:           os.Getenv("DATABASE_URL"),
+		PORT:                   os.Getenv("PORT"),
+		DATABASE_NAME:          os.Getenv("DATABASE_NAME"),
+		DATABASE_USER:          os.Getenv("DATABASE_USER"),
+		DATABASE_PASSWORD:      os.Getenv("DATABASE_PASSWORD"),
+		BASE_URL:               os.Getenv("BASE_URL"),
+		TOKEN_SECRET:           os.Getenv("TOKEN_SECRET"),
+		SCHEDULER_INTERVAL:     getEnvDuration("SCHEDULER_INTERVAL", 5*time.Second),
+		CLOSE_BATCH_SIZE:       getEnvInt("CLOSE_BATCH_SIZE", 100, 1),
+		SNIPE_WINDOW:           getEnvDuration("SNIPE_WINDOW", 2*time.Minute),
+		SNIPE_EXTENSION:        getEnvDuration("SNIPE_EXTENSION", 2*time.Minute),
+		SNIPE_MAX_EXTENSIONS:   getEnvInt("SNIPE_MAX_EXTENSIONS", 10, 0),
+		BUY_NOW_THRESHOLD:      getEnvString("BUY_NOW_THRESHOLD", "0.5"),
+		BID_INCREMENTS:         getEnvString("BID_INCREMENTS", "0:1,100:5,1000:10,5000:25"),
+		ADMIN_USER_IDS:         getEnvInt64List("ADMIN_USER_IDS"),
+		SEALED_BID_REVISIONS:   getEnvString("SEALED_BID_REVISIONS", "none"),
+		FX_RATES_FILE:          os.Getenv("FX_RATES_FILE"),
+		BUYERS_PREMIUM:         getEnvString("BUYERS_PREMIUM", "0"),
+		ORDER_FEE:              getEnvString("ORDER_FEE", "0"),
+		TAX_RATE:               getEnvString("TAX_RATE", "0"),
+		REQUIRE_FUNDS:          getEnvBool("REQUIRE_FUNDS", false),
+		LEDGER_CHECK_INTERVAL:  getEnvDuration("LEDGER_CHECK_INTERVAL", time.Hour),
+		PAYMENT_PROVIDER:       os.Getenv("PAYMENT_PROVIDER"),
+		PAYMENT_WEBHOOK_SECRET: os.Getenv("PAYMENT_WEBHOOK_SECRET"),
+		ALLOW_FAKE_PAYMENTS:    getEnvBool("ALLOW_FAKE_PAYMENTS", false),
+		EVENT_LOG_SIZE:         getEnvInt("EVENT_LOG_SIZE", 100, 1),
+		EVENT_LOG_ITEMS:        getEnvInt("EVENT_LOG_ITEMS", 1000, 1),
+		EVENT_BUS:              getEnvString("EVENT_BUS", "postgres"),
+		STREAM_TICKET_TTL:      getEnvDuration("STREAM_TICKET_TTL", 30*time.Second),
+		ALLOWED_ORIGINS:        getEnvStringList("ALLOWED_ORIGINS"),
+		OUTBOX_POLL_INTERVAL:   getEnvDuration("OUTBOX_POLL_INTERVAL", time.Second),
+		OUTBOX_BATCH_SIZE:      getEnvInt("OUTBOX_BATCH_SIZE", 100, 1),
+		OUTBOX_RETENTION:       getEnvDuration("OUTBOX_RETENTION", 24*time.Hour),
+		WEBHOOK_URLS:           getEnvStringList("WEBHOOK_URLS"),
+		WEBHOOK_SECRET:         os.Getenv("WEBHOOK_SECRET"),
+		NOTIFY_ENDING_SOON:     getEnvDuration("NOTIFY_ENDING_SOON", 15*time.Minute),
 	}
 }
+
+// Read a duration such as 5s or 1m from the environment, falling back when it is unset or invalid
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+
+	if err != nil || value <= 0 {
+		return fallback
+	}
+
+	return value
+}
+
+// Read an integer of at least min from the environment, falling back when it is unset or invalid
+func getEnvInt(key string, fallback int, min int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+
+	if err != nil || value < min {
+		return fallback
+	}
+
+	return value
+}
+
+// Read a boolean such as true or 0 from the environment, falling back when it is unset or invalid
+func getEnvBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
+
+// Read a string from the environment, falling back when it is unset
+func getEnvString(key string, fallback string) string {
+	value := os.Getenv(key)
+
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
+
+// Read a comma separated list of IDs from the environment, invalid entries are skipped
+func getEnvInt64List(key string) []int64 {
+	var values []int64
+
+	for _, field := range strings.Split(os.Getenv(key), ",") {
+		value, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+
+		if err == nil {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// Read a comma separated list of strings from the environment, empty entries are skipped
+func getEnvStringList(key string) []string {
+	var values []string
+
+	for _, field := range strings.Split(os.Getenv(key), ",") {
+		if value := strings.TrimSpace(field); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,67 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Returned by a Resolver for event types it does not know how to load
+var ErrUnknownEvent = errors.New("unknown event type")
+
+// Bus carries events published on one API instance to the dispatchers of the other instances
+type Bus interface {
+	// Send the dispatcher's events of the given types to the other instances and publish theirs on it
+	Connect(dispatcher *Dispatcher, eventTypes ...EventType)
+	// Relay events until the context is cancelled
+	Run(ctx context.Context) error
+}
+
+// Events that have to reach every instance. Price changes are left out since each instance computes its own.
+func SharedEventTypes() []EventType {
+	return []EventType{BidPlaced, ItemExtended, ItemClosed}
+}
+
+// MemoryBus connects dispatchers within a single process the way PostgresBus connects instances.
+// It is meant for tests and single instance setups, events are passed on as they are.
+type MemoryBus struct {
+	mu          sync.RWMutex
+	dispatchers []*Dispatcher
+}
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{}
+}
+
+func (b *MemoryBus) Connect(dispatcher *Dispatcher, eventTypes ...EventType) {
+	b.mu.Lock()
+	b.dispatchers = append(b.dispatchers, dispatcher)
+	b.mu.Unlock()
+
+	for _, eventType := range eventTypes {
+		dispatcher.Subscribe(eventType, func(event Event) {
+			if event.Remote {
+				return
+			}
+
+			b.mu.RLock()
+			dispatchers := b.dispatchers
+			b.mu.RUnlock()
+
+			event.Remote = true
+
+			for _, other := range dispatchers {
+				if other != dispatcher {
+					other.Publish(event)
+				}
+			}
+		})
+	}
+}
+
+// Events are delivered as they are published, so there is nothing to run
+func (b *MemoryBus) Run(ctx context.Context) error {
+	<-ctx.Done()
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+type EventType string
+
+const (
+	// Published once an auction has been closed, the data is the closed models.Item
+	ItemClosed EventType = "item.closed"
+	// Published when a late bid pushed back the end of an auction, the data is the extended models.Item
+	ItemExtended EventType = "item.extended"
+	// Published for every bid recorded on an open auction, the data is the models.Bid. Sealed bids are never published.
+	BidPlaced EventType = "bid.placed"
+	// Published when the asking price of a dutch auction drops, the data is a models.PriceChange
+	PriceChanged EventType = "item.price_changed"
+	// Published for every bidder a bid pushed out of the lead, the data is a models.Outbid. Only the bidder is told, it is not part of the live feed.
+	Outbid EventType = "item.outbid"
+	// Published for every order created for a sale, the data is the models.Order
+	OrderCreated EventType = "order.created"
+	// Published when an order moves to another payment status, the data is the updated models.Order
+	OrderPaymentChanged EventType = "order.payment_changed"
+)
+
+type Event struct {
+	Type       EventType `json:"type"`
+	ItemID     int64     `json:"item_id"`
+	Data       any       `json:"data,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Set on events received from another instance over a Bus, so they are not sent back out
+	Remote bool `json:"-"`
+}
+
+type Handler func(Event)
+
+// Dispatcher delivers events to the handlers subscribed to their type within this process
+type Dispatcher struct {
+	mu       sync.RWMutex
+	handlers map[EventType][]Handler
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{handlers: make(map[EventType][]Handler)}
+}
+
+// Register a handler that is called for every event of the given type
+func (d *Dispatcher) Subscribe(eventType EventType, handler Handler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.handlers[eventType] = append(d.handlers[eventType], handler)
+}
+
+// Deliver an event to its subscribers in the order they subscribed.
+// A panicking handler is logged and does not stop the remaining handlers from running.
+func (d *Dispatcher) Publish(event Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	d.mu.RLock()
+	handlers := d.handlers[event.Type]
+	d.mu.RUnlock()
+
+	for _, handler := range handlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("Event handler for %s panicked: %v", event.Type, r)
+				}
+			}()
+
+			handler(event)
+		}()
+	}
+}
@@ -0,0 +1,232 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// Channel the instances notify each other on
+	notifyChannel = "auction_events"
+	// Postgres rejects notification payloads of 8000 bytes or more
+	maxNotifyPayload = 7999
+	// Events waiting to be sent before new ones are dropped
+	outboxSize = 1024
+	// Bounds of the wait between reconnect attempts
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// Resolver shrinks events to the IDs sent between instances and loads the rest back on the receiving side
+type Resolver interface {
+	// ID of the record an event is about besides its item, 0 when the item says it all
+	Reference(event Event) int64
+	// Load the data of an event received from another instance
+	Resolve(eventType EventType, itemID int64, refID int64) (any, error)
+}
+
+// What is sent over NOTIFY, event data stays behind and is fetched by the receivers
+type notification struct {
+	Origin     string    `json:"origin"`
+	Type       EventType `json:"type"`
+	ItemID     int64     `json:"item_id"`
+	RefID      int64     `json:"ref_id,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// PostgresBus relays events between instances over Postgres LISTEN/NOTIFY.
+// Only IDs are sent, which keeps payloads far below the notification size limit, and receivers load the data themselves.
+// The listening connection is re-established when it drops, events notified in the meantime are missed.
+type PostgresBus struct {
+	DB       *pgxpool.Pool
+	Resolver Resolver
+	// Identifies this instance so it ignores its own notifications
+	origin string
+	outbox chan notification
+
+	mu          sync.RWMutex
+	dispatchers []*Dispatcher
+}
+
+func NewPostgresBus(DB *pgxpool.Pool, Resolver Resolver) *PostgresBus {
+	origin := make([]byte, 8)
+	rand.Read(origin)
+
+	return &PostgresBus{
+		DB:       DB,
+		Resolver: Resolver,
+		origin:   hex.EncodeToString(origin),
+		outbox:   make(chan notification, outboxSize),
+	}
+}
+
+// Queue the dispatcher's local events for the other instances, publishing never waits on the database
+func (b *PostgresBus) Connect(dispatcher *Dispatcher, eventTypes ...EventType) {
+	b.mu.Lock()
+	b.dispatchers = append(b.dispatchers, dispatcher)
+	b.mu.Unlock()
+
+	for _, eventType := range eventTypes {
+		dispatcher.Subscribe(eventType, func(event Event) {
+			if event.Remote {
+				return
+			}
+
+			message := notification{
+				Origin:     b.origin,
+				Type:       event.Type,
+				ItemID:     event.ItemID,
+				RefID:      b.Resolver.Reference(event),
+				OccurredAt: event.OccurredAt,
+			}
+
+			select {
+			case b.outbox <- message:
+			default:
+				log.Printf("Event bus is backed up, dropped %s for item %d", event.Type, event.ItemID)
+			}
+		})
+	}
+}
+
+// Send queued events and listen for other instances' events until the context is cancelled
+func (b *PostgresBus) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		b.send(ctx)
+	}()
+
+	delay := minReconnectDelay
+
+	for {
+		start := time.Now()
+		err := b.listen(ctx)
+
+		if ctx.Err() != nil {
+			wg.Wait()
+			return nil
+		}
+
+		// A connection that held up for a while starts the backoff over
+		if time.Since(start) > maxReconnectDelay {
+			delay = minReconnectDelay
+		}
+
+		log.Printf("Error listening for events, reconnecting in %s: %v", delay, err)
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil
+		case <-time.After(delay):
+		}
+
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// Notify the other instances of queued events, in the order they were published
+func (b *PostgresBus) send(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message := <-b.outbox:
+			payload, err := json.Marshal(message)
+
+			if err == nil && len(payload) > maxNotifyPayload {
+				err = fmt.Errorf("payload of %d bytes is over the limit", len(payload))
+			}
+
+			if err != nil {
+				log.Printf("Error encoding %s for item %d: %v", message.Type, message.ItemID, err)
+				continue
+			}
+
+			_, err = b.DB.Exec(ctx, `SELECT pg_notify($1, $2)`, notifyChannel, string(payload))
+
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Error notifying %s for item %d: %v", message.Type, message.ItemID, err)
+			}
+		}
+	}
+}
+
+// Hold a connection listening on the channel and publish what arrives until it fails
+func (b *PostgresBus) listen(ctx context.Context) error {
+	pooled, err := b.DB.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	// The connection is taken out of the pool for good, it should not go back still listening
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(ctx, `LISTEN `+notifyChannel)
+
+	if err != nil {
+		return err
+	}
+
+	for {
+		received, err := conn.WaitForNotification(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		var message notification
+
+		err = json.Unmarshal([]byte(received.Payload), &message)
+
+		if err != nil {
+			log.Printf("Error decoding event notification: %v", err)
+			continue
+		}
+
+		if message.Origin == b.origin {
+			continue
+		}
+
+		b.deliver(message)
+	}
+}
+
+// Load the data of another instance's event and publish it on the connected dispatchers
+func (b *PostgresBus) deliver(message notification) {
+	data, err := b.Resolver.Resolve(message.Type, message.ItemID, message.RefID)
+
+	if errors.Is(err, ErrUnknownEvent) {
+		return
+	}
+
+	if err != nil {
+		log.Printf("Error loading %s for item %d: %v", message.Type, message.ItemID, err)
+		return
+	}
+
+	event := Event{Type: message.Type, ItemID: message.ItemID, Data: data, OccurredAt: message.OccurredAt, Remote: true}
+
+	b.mu.RLock()
+	dispatchers := b.dispatchers
+	b.mu.RUnlock()
+
+	for _, dispatcher := range dispatchers {
+		dispatcher.Publish(event)
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bangueco/auction-api/internal/handlers/helper"
+	"github.com/bangueco/auction-api/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type AllocationHandler struct {
+	AllocationService *services.AllocationService
+}
+
+func NewAllocationHandler(AllocationService *services.AllocationService) *AllocationHandler {
+	return &AllocationHandler{AllocationService}
+}
+
+func (a *AllocationHandler) GetItemAllocations(w http.ResponseWriter, r *http.Request) {
+	var bidderID *int64
+	idParam := chi.URLParam(r, "id")
+
+	itemID, err := helper.ConvertStringToInt64(idParam)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	if bidderParam := r.URL.Query().Get("bidder_id"); bidderParam != "" {
+		id, err := helper.ConvertStringToInt64(bidderParam)
+
+		if err != nil {
+			helper.WriteResponseMessage(w, "Invalid bidder ID", http.StatusBadRequest)
+			return
+		}
+
+		bidderID = &id
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	allocations, err := a.AllocationService.GetItemAllocations(itemID, bidderID, userId)
+
+	if errors.Is(err, services.ErrItemNotFound) {
+		helper.WriteResponseMessage(w, "Item not found", http.StatusNotFound)
+		return
+	}
+
+	if errors.Is(err, services.ErrNotAllocationViewer) {
+		helper.WriteResponseMessage(w, "Only the seller and the bidder may view a bidder's allocations", http.StatusForbidden)
+		return
+	}
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error retrieving allocations", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, allocations, http.StatusOK)
+}
+
+func (a *AllocationHandler) GetMyAllocations(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	allocations, err := a.AllocationService.GetBidderAllocations(userId)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error retrieving allocations", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, allocations, http.StatusOK)
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bangueco/auction-api/internal/handlers/helper"
+	"github.com/bangueco/auction-api/internal/lib"
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type AwardHandler struct {
+	AwardService *services.AwardService
+}
+
+func NewAwardHandler(AwardService *services.AwardService) *AwardHandler {
+	return &AwardHandler{AwardService}
+}
+
+func (a *AwardHandler) AwardBid(w http.ResponseWriter, r *http.Request) {
+	var request models.AwardRequest
+	idParam := chi.URLParam(r, "id")
+
+	itemID, err := helper.ConvertStringToInt64(idParam)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	err = helper.DecodeRequestBody(r, &request)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	errorMessages := lib.ValidateStruct(&request)
+
+	if errorMessages != nil {
+		helper.WriteResponse(w, errorMessages, http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	item, err := a.AwardService.AwardBid(itemID, userId, request)
+
+	switch {
+	case errors.Is(err, services.ErrItemNotFound):
+		helper.WriteResponseMessage(w, "Item not found", http.StatusNotFound)
+	case errors.Is(err, services.ErrBidNotFound):
+		helper.WriteResponseMessage(w, "Bid not found", http.StatusNotFound)
+	case errors.Is(err, services.ErrNotItemOwner):
+		helper.WriteResponseMessage(w, "You do not own this item", http.StatusForbidden)
+	case errors.Is(err, services.ErrNotReverseAuction):
+		helper.WriteResponseMessage(w, "Only reverse auctions can be awarded", http.StatusConflict)
+	case errors.Is(err, services.ErrAuctionNotClosed):
+		helper.WriteResponseMessage(w, "Auction has not closed yet", http.StatusConflict)
+	case errors.Is(err, services.ErrAwardReasonMissing):
+		helper.WriteResponseMessage(w, "Awarding a bid other than the lowest needs a reason", http.StatusBadRequest)
+	case errors.Is(err, services.ErrOrderSettled):
+		helper.WriteResponseMessage(w, "The contract has already been paid for", http.StatusConflict)
+	case err != nil:
+		helper.WriteResponseMessage(w, "Error awarding bid", http.StatusInternalServerError)
+	default:
+		helper.WriteResponse(w, item, http.StatusOK)
+	}
+}
+
+func (a *AwardHandler) GetAwards(w http.ResponseWriter, r *http.Request) {
+	idParam := chi.URLParam(r, "id")
+
+	itemID, err := helper.ConvertStringToInt64(idParam)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	awards, err := a.AwardService.GetAwards(itemID, userId)
+
+	if errors.Is(err, services.ErrItemNotFound) {
+		helper.WriteResponseMessage(w, "Item not found", http.StatusNotFound)
+		return
+	}
+
+	if errors.Is(err, services.ErrNotItemOwner) {
+		helper.WriteResponseMessage(w, "You do not own this item", http.StatusForbidden)
+		return
+	}
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error retrieving awards", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, awards, http.StatusOK)
+}
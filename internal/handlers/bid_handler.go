@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bangueco/auction-api/internal/handlers/helper"
+	"github.com/bangueco/auction-api/internal/lib"
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type BidHandler struct {
+	BidService *services.BidService
+}
+
+func NewBidHandler(BidService *services.BidService) *BidHandler {
+	return &BidHandler{BidService}
+}
+
+func (b *BidHandler) PlaceBid(w http.ResponseWriter, r *http.Request) {
+	idParam := chi.URLParam(r, "id")
+
+	itemID, err := helper.ConvertStringToInt64(idParam)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	b.placeBid(w, r, itemID)
+}
+
+// Place a bid on an item, shared by the item and lot bid endpoints
+func (b *BidHandler) placeBid(w http.ResponseWriter, r *http.Request, itemID int64) {
+	var bidRequest models.PlaceBidRequest
+
+	err := helper.DecodeRequestBody(r, &bidRequest)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	errorMessages := lib.ValidateStruct(&bidRequest)
+
+	if errorMessages != nil {
+		helper.WriteResponse(w, errorMessages, http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	placement, err := b.BidService.PlaceBid(itemID, userId, bidRequest)
+
+	if errors.Is(err, services.ErrItemNotFound) {
+		helper.WriteResponseMessage(w, "Item not found", http.StatusNotFound)
+		return
+	}
+
+	if errors.Is(err, services.ErrOwnItemBid) {
+		helper.WriteResponseMessage(w, "You cannot bid on your own item", http.StatusForbidden)
+		return
+	}
+
+	if errors.Is(err, services.ErrAuctionNotLive) {
+		helper.WriteResponseMessage(w, "Auction is not accepting bids", http.StatusConflict)
+		return
+	}
+
+	var bidTooLow *services.BidTooLowError
+
+	if errors.As(err, &bidTooLow) {
+		helper.WriteResponse(w, struct {
+			helper.ResponseMessage
+			MinimumBid models.Money `json:"minimum_bid"`
+		}{helper.ResponseMessage{Message: fmt.Sprintf("Bid must be at least %s", bidTooLow.MinimumBid)}, bidTooLow.MinimumBid}, http.StatusBadRequest)
+		return
+	}
+
+	var bidTooHigh *services.BidTooHighError
+
+	if errors.As(err, &bidTooHigh) {
+		helper.WriteResponse(w, struct {
+			helper.ResponseMessage
+			MaximumBid models.Money `json:"maximum_bid"`
+		}{helper.ResponseMessage{Message: fmt.Sprintf("Bid must be at most %s", bidTooHigh.MaximumBid)}, bidTooHigh.MaximumBid}, http.StatusBadRequest)
+		return
+	}
+
+	if errors.Is(err, services.ErrInvalidMaxAmount) {
+		helper.WriteResponseMessage(w, "Maximum bid must not be below the bid amount", http.StatusBadRequest)
+		return
+	}
+
+	if errors.Is(err, services.ErrWrongAuctionType) {
+		helper.WriteResponseMessage(w, "This auction does not take bids, accept its current price instead", http.StatusConflict)
+		return
+	}
+
+	if errors.Is(err, services.ErrProxyUnavailable) {
+		helper.WriteResponseMessage(w, "Proxy bids are only available in english auctions", http.StatusBadRequest)
+		return
+	}
+
+	if errors.Is(err, services.ErrSealedBidPlaced) {
+		helper.WriteResponseMessage(w, "You already placed your sealed bid on this item", http.StatusConflict)
+		return
+	}
+
+	if errors.Is(err, services.ErrSealedBidLowered) {
+		helper.WriteResponseMessage(w, "Your sealed bid can only be revised upwards", http.StatusConflict)
+		return
+	}
+
+	if errors.Is(err, services.ErrInvalidQuantity) {
+		helper.WriteResponseMessage(w, "Bid quantity must be between 1 and the number of units for sale", http.StatusBadRequest)
+		return
+	}
+
+	if errors.Is(err, services.ErrCurrencyMismatch) {
+		helper.WriteResponseMessage(w, "Bids must be placed in the item's currency", http.StatusBadRequest)
+		return
+	}
+
+	if errors.Is(err, models.ErrInvalidMoneyForCurrency) {
+		helper.WriteResponseMessage(w, "Bid amounts have more decimal places than the item's currency allows", http.StatusBadRequest)
+		return
+	}
+
+	if errors.Is(err, services.ErrInsufficientFunds) {
+		helper.WriteResponseMessage(w, "Not enough available funds in your wallet", http.StatusPaymentRequired)
+		return
+	}
+
+	if errors.Is(err, services.ErrAlreadyLeading) {
+		helper.WriteResponseMessage(w, "You already hold the leading bid, raise your maximum bid instead", http.StatusConflict)
+		return
+	}
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error placing bid", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, placement, http.StatusCreated)
+}
+
+func (b *BidHandler) GetBidHistory(w http.ResponseWriter, r *http.Request) {
+	idParam := chi.URLParam(r, "id")
+
+	itemID, err := helper.ConvertStringToInt64(idParam)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	b.writeBidHistory(w, r, itemID)
+}
+
+// Write a page of an item's bid history, shared by the item and lot bid endpoints
+func (b *BidHandler) writeBidHistory(w http.ResponseWriter, r *http.Request, itemID int64) {
+	page, limit := helper.GetPagination(r)
+
+	bidPage, err := b.BidService.GetBidHistory(itemID, page, limit)
+
+	if errors.Is(err, services.ErrItemNotFound) {
+		helper.WriteResponseMessage(w, "Item not found", http.StatusNotFound)
+		return
+	}
+
+	if errors.Is(err, services.ErrBidsSealed) {
+		helper.WriteResponseMessage(w, "Bids on this auction stay sealed until it closes", http.StatusForbidden)
+		return
+	}
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error retrieving bids", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, bidPage, http.StatusOK)
+}
+
+func (b *BidHandler) GetLeadingBid(w http.ResponseWriter, r *http.Request) {
+	idParam := chi.URLParam(r, "id")
+
+	itemID, err := helper.ConvertStringToInt64(idParam)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	leader, err := b.BidService.GetLeadingBid(itemID)
+
+	if errors.Is(err, services.ErrItemNotFound) {
+		helper.WriteResponseMessage(w, "Item not found", http.StatusNotFound)
+		return
+	}
+
+	if errors.Is(err, services.ErrBidsSealed) {
+		helper.WriteResponseMessage(w, "Bids on this auction stay sealed until it closes", http.StatusForbidden)
+		return
+	}
+
+	if errors.Is(err, services.ErrNoBids) {
+		helper.WriteResponseMessage(w, "No bids placed yet", http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error retrieving leading bid", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, leader, http.StatusOK)
+}
+
+func (b *BidHandler) BuyNow(w http.ResponseWriter, r *http.Request) {
+	idParam := chi.URLParam(r, "id")
+
+	itemID, err := helper.ConvertStringToInt64(idParam)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	item, err := b.BidService.BuyNow(itemID, userId)
+
+	if errors.Is(err, services.ErrItemNotFound) {
+		helper.WriteResponseMessage(w, "Item not found", http.StatusNotFound)
+		return
+	}
+
+	if errors.Is(err, services.ErrOwnItemBid) {
+		helper.WriteResponseMessage(w, "You cannot buy your own item", http.StatusForbidden)
+		return
+	}
+
+	if errors.Is(err, services.ErrAuctionNotLive) {
+		helper.WriteResponseMessage(w, "Auction is not accepting bids", http.StatusConflict)
+		return
+	}
+
+	if errors.Is(err, services.ErrInsufficientFunds) {
+		helper.WriteResponseMessage(w, "Not enough available funds in your wallet", http.StatusPaymentRequired)
+		return
+	}
+
+	if errors.Is(err, services.ErrBuyNowUnavailable) {
+		helper.WriteResponseMessage(w, "Buy-now is not available for this item", http.StatusConflict)
+		return
+	}
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error buying item", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, item, http.StatusOK)
+}
+
+func (b *BidHandler) AcceptPrice(w http.ResponseWriter, r *http.Request) {
+	idParam := chi.URLParam(r, "id")
+
+	itemID, err := helper.ConvertStringToInt64(idParam)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	item, err := b.BidService.AcceptPrice(itemID, userId)
+
+	if errors.Is(err, services.ErrItemNotFound) {
+		helper.WriteResponseMessage(w, "Item not found", http.StatusNotFound)
+		return
+	}
+
+	if errors.Is(err, services.ErrOwnItemBid) {
+		helper.WriteResponseMessage(w, "You cannot buy your own item", http.StatusForbidden)
+		return
+	}
+
+	if errors.Is(err, services.ErrAuctionNotLive) {
+		helper.WriteResponseMessage(w, "Auction is not accepting bids", http.StatusConflict)
+		return
+	}
+
+	if errors.Is(err, services.ErrInsufficientFunds) {
+		helper.WriteResponseMessage(w, "Not enough available funds in your wallet", http.StatusPaymentRequired)
+		return
+	}
+
+	if errors.Is(err, services.ErrWrongAuctionType) {
+		helper.WriteResponseMessage(w, "Only dutch auctions can be accepted at their current price", http.StatusConflict)
+		return
+	}
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error accepting price", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, item, http.StatusOK)
+}
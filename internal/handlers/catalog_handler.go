@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bangueco/auction-api/internal/handlers/helper"
+	"github.com/bangueco/auction-api/internal/lib"
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type CatalogHandler struct {
+	CatalogService *services.CatalogService
+}
+
+func NewCatalogHandler(CatalogService *services.CatalogService) *CatalogHandler {
+	return &CatalogHandler{CatalogService}
+}
+
+func (c *CatalogHandler) CreateCatalog(w http.ResponseWriter, r *http.Request) {
+	var request models.CreateCatalogRequest
+
+	err := helper.DecodeRequestBody(r, &request)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	errorMessages := lib.ValidateStruct(&request)
+
+	if errorMessages != nil {
+		helper.WriteResponse(w, errorMessages, http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	catalog, err := c.CatalogService.CreateCatalog(userId, request)
+
+	if err != nil {
+		writeCatalogError(w, err, "Error creating catalog")
+		return
+	}
+
+	helper.WriteResponse(w, catalog, http.StatusCreated)
+}
+
+func (c *CatalogHandler) GetCatalogs(w http.ResponseWriter, r *http.Request) {
+	catalogs, err := c.CatalogService.GetCatalogs()
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error retrieving catalogs", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, catalogs, http.StatusOK)
+}
+
+func (c *CatalogHandler) GetCatalogByID(w http.ResponseWriter, r *http.Request) {
+	catalogID, err := helper.ConvertStringToInt64(chi.URLParam(r, "id"))
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid catalog ID", http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	catalog, err := c.CatalogService.GetCatalogByID(catalogID, userId)
+
+	if err != nil {
+		writeCatalogError(w, err, "Error retrieving catalog")
+		return
+	}
+
+	helper.WriteResponse(w, catalog, http.StatusOK)
+}
+
+func (c *CatalogHandler) SetCatalogLots(w http.ResponseWriter, r *http.Request) {
+	var update models.CatalogLotsUpdate
+
+	catalogID, err := helper.ConvertStringToInt64(chi.URLParam(r, "id"))
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid catalog ID", http.StatusBadRequest)
+		return
+	}
+
+	err = helper.DecodeRequestBody(r, &update)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	errorMessages := lib.ValidateStruct(&update)
+
+	if errorMessages != nil {
+		helper.WriteResponse(w, errorMessages, http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	catalog, err := c.CatalogService.SetCatalogLots(catalogID, userId, update.LotIDs)
+
+	if err != nil {
+		writeCatalogError(w, err, "Error updating catalog lots")
+		return
+	}
+
+	helper.WriteResponse(w, catalog, http.StatusOK)
+}
+
+// Write the response for an error returned by one of the catalog operations
+func writeCatalogError(w http.ResponseWriter, err error, fallbackMessage string) {
+	switch {
+	case errors.Is(err, services.ErrCatalogNotFound):
+		helper.WriteResponseMessage(w, "Catalog not found", http.StatusNotFound)
+	case errors.Is(err, services.ErrLotNotFound):
+		helper.WriteResponseMessage(w, "Lot not found", http.StatusNotFound)
+	case errors.Is(err, services.ErrNotItemOwner):
+		helper.WriteResponseMessage(w, "You do not own this catalog or one of its lots", http.StatusForbidden)
+	case errors.Is(err, services.ErrCatalogNotEditable):
+		helper.WriteResponseMessage(w, "Catalog has already started", http.StatusConflict)
+	case errors.Is(err, services.ErrLotNotSchedulable):
+		helper.WriteResponseMessage(w, "Only lots that are not live and not in another catalog can be added", http.StatusConflict)
+	case errors.Is(err, services.ErrInvalidLotPricing):
+		helper.WriteResponseMessage(w, "One of the lots has pricing that is not valid for an auction", http.StatusBadRequest)
+	case errors.Is(err, services.ErrInvalidAuctionWindow):
+		helper.WriteResponseMessage(w, "The first lot has to close in the future", http.StatusBadRequest)
+	default:
+		helper.WriteResponseMessage(w, fallbackMessage, http.StatusInternalServerError)
+	}
+}
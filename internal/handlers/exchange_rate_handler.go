@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bangueco/auction-api/internal/handlers/helper"
+	"github.com/bangueco/auction-api/internal/lib"
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/services"
+)
+
+type ExchangeRateHandler struct {
+	ExchangeRateService *services.ExchangeRateService
+}
+
+func NewExchangeRateHandler(ExchangeRateService *services.ExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{ExchangeRateService}
+}
+
+func (e *ExchangeRateHandler) GetExchangeRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := e.ExchangeRateService.GetExchangeRates()
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error retrieving exchange rates", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, rates, http.StatusOK)
+}
+
+func (e *ExchangeRateHandler) SetExchangeRates(w http.ResponseWriter, r *http.Request) {
+	var rates models.ExchangeRates
+
+	err := helper.DecodeRequestBody(r, &rates)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	errorMessages := lib.ValidateStruct(&rates)
+
+	if errorMessages != nil {
+		helper.WriteResponse(w, errorMessages, http.StatusBadRequest)
+		return
+	}
+
+	saved, err := e.ExchangeRateService.SetExchangeRates(rates)
+
+	if errors.Is(err, models.ErrInvalidExchangeRates) {
+		helper.WriteResponseMessage(w, "Every rate must be a positive number and the base currency's rate must be 1", http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error saving exchange rates", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, saved, http.StatusOK)
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bangueco/auction-api/internal/handlers/helper"
+	"github.com/bangueco/auction-api/internal/realtime"
+	"github.com/bangueco/auction-api/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+)
+
+type FeedHandler struct {
+	Hub           *realtime.Hub
+	EventLog      *realtime.EventLog
+	ItemService   *services.ItemService
+	TicketService *services.StreamTicketService
+	upgrader      websocket.Upgrader
+}
+
+// Browsers send cookies and stream tickets from any page, so WebSocket upgrades are only accepted from the API's own
+// host and the origins in allowedOrigins. Clients that send no Origin header, which are not browsers, are let through.
+func NewFeedHandler(Hub *realtime.Hub, EventLog *realtime.EventLog, ItemService *services.ItemService, TicketService *services.StreamTicketService, allowedOrigins []string) *FeedHandler {
+	return &FeedHandler{
+		Hub:           Hub,
+		EventLog:      EventLog,
+		ItemService:   ItemService,
+		TicketService: TicketService,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+
+				if origin == "" {
+					return true
+				}
+
+				parsed, err := url.Parse(origin)
+
+				if err != nil {
+					return false
+				}
+
+				if strings.EqualFold(parsed.Host, r.Host) {
+					return true
+				}
+
+				return slices.ContainsFunc(allowedOrigins, func(allowed string) bool {
+					return strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin)
+				})
+			},
+		},
+	}
+}
+
+// How often an idle event stream gets a comment, keeps proxies from timing it out
+const streamHeartbeat = 30 * time.Second
+
+// Issue a short-lived, single-use ticket the live feed and event streams can be opened with, as browsers cannot send the bearer token on those requests
+func (fh *FeedHandler) IssueStreamTicket(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	ticket, err := fh.TicketService.IssueTicket(userId)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error issuing stream ticket", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, ticket, http.StatusCreated)
+}
+
+// Upgrade to a WebSocket streaming live events, the items to follow can be given up front with ?items=1,2
+func (fh *FeedHandler) ServeFeed(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	var itemIDs []int64
+
+	if items := r.URL.Query().Get("items"); items != "" {
+		for _, value := range strings.Split(items, ",") {
+			itemID, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+
+			if err != nil || itemID <= 0 {
+				helper.WriteResponseMessage(w, "Invalid item id", http.StatusBadRequest)
+				return
+			}
+
+			itemIDs = append(itemIDs, itemID)
+		}
+	}
+
+	if len(itemIDs) > realtime.MaxSubscriptions {
+		helper.WriteResponseMessage(w, "Too many items", http.StatusBadRequest)
+		return
+	}
+
+	if !fh.Hub.Open() {
+		helper.WriteResponseMessage(w, "Live feed is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	// The upgrader writes its own error response on failure
+	conn, err := fh.upgrader.Upgrade(w, r, nil)
+
+	if err != nil {
+		return
+	}
+
+	err = fh.Hub.Register(conn, userId, itemIDs)
+
+	if err != nil {
+		log.Printf("Error registering live feed client: %v", err)
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"))
+		conn.Close()
+	}
+}
+
+// Stream an item's live events as Server-Sent Events.
+// A reconnecting client sends the Last-Event-ID header, or ?last_event_id= when it cannot set headers, and first gets the events it missed.
+func (fh *FeedHandler) StreamItemEvents(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ConvertStringToInt64(chi.URLParam(r, "id"))
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	var lastEventID int64
+
+	lastEventParam := r.Header.Get("Last-Event-ID")
+
+	if lastEventParam == "" {
+		lastEventParam = r.URL.Query().Get("last_event_id")
+	}
+
+	if lastEventParam != "" {
+		lastEventID, err = strconv.ParseInt(lastEventParam, 10, 64)
+
+		if err != nil || lastEventID < 0 {
+			helper.WriteResponseMessage(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	_, err = fh.ItemService.GetItemByID(id, userId)
+
+	if errors.Is(err, services.ErrItemNotFound) {
+		helper.WriteResponseMessage(w, "Item not found", http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error retrieving item", http.StatusInternalServerError)
+		return
+	}
+
+	listener, missed, err := fh.EventLog.Listen(id, lastEventID)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Live feed is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	defer fh.EventLog.Unlisten(listener)
+
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Stops nginx from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", (3 * time.Second).Milliseconds())
+
+	for _, event := range missed {
+		writeStreamEvent(w, event)
+	}
+
+	if rc.Flush() != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, open := <-listener.Events():
+			// Closed when the client fell behind or the server is stopping, it reconnects and catches up
+			if !open {
+				return
+			}
+
+			writeStreamEvent(w, event)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		}
+
+		if rc.Flush() != nil {
+			return
+		}
+	}
+}
+
+func writeStreamEvent(w http.ResponseWriter, event realtime.LoggedEvent) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Event, event.Payload)
+}
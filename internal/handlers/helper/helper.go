@@ -11,6 +11,11 @@ type UserIDType string
 
 const UserIDKey UserIDType = "userId"
 
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
 type ResponseMessage struct {
 	Message string `json:"messsage"`
 }
@@ -26,6 +31,27 @@ func ConvertStringToInt64(s string) (int64, error) {
 	return i, nil
 }
 
+// Read the page and limit query parameters, falling back to the first page and the default limit
+func GetPagination(r *http.Request) (page, limit int) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err = strconv.Atoi(r.URL.Query().Get("limit"))
+
+	if err != nil || limit < 1 {
+		limit = DefaultPageLimit
+	}
+
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+
+	return page, limit
+}
+
 func WriteResponseMessage(w http.ResponseWriter, message string, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bangueco/auction-api/internal/handlers/helper"
+	"github.com/bangueco/auction-api/internal/lib"
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type IncrementHandler struct {
+	IncrementService *services.IncrementService
+}
+
+func NewIncrementHandler(IncrementService *services.IncrementService) *IncrementHandler {
+	return &IncrementHandler{IncrementService}
+}
+
+func (i *IncrementHandler) GetIncrementTables(w http.ResponseWriter, r *http.Request) {
+	tables, err := i.IncrementService.GetIncrementTables()
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error retrieving increment tables", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, tables, http.StatusOK)
+}
+
+func (i *IncrementHandler) SetCategoryIncrements(w http.ResponseWriter, r *http.Request) {
+	var table models.CategoryIncrements
+
+	err := helper.DecodeRequestBody(r, &table)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	table.Category = chi.URLParam(r, "category")
+
+	errorMessages := lib.ValidateStruct(&table)
+
+	if errorMessages != nil {
+		helper.WriteResponse(w, errorMessages, http.StatusBadRequest)
+		return
+	}
+
+	saved, err := i.IncrementService.SetCategoryIncrements(table)
+
+	if errors.Is(err, models.ErrInvalidIncrementTable) {
+		helper.WriteResponseMessage(w, "Increment table must start at 0 with increasing tiers and positive steps", http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error saving increment table", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, saved, http.StatusOK)
+}
+
+func (i *IncrementHandler) DeleteCategoryIncrements(w http.ResponseWriter, r *http.Request) {
+	err := i.IncrementService.DeleteCategoryIncrements(chi.URLParam(r, "category"))
+
+	if errors.Is(err, services.ErrIncrementTableNotFound) {
+		helper.WriteResponseMessage(w, "Increment table not found", http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error deleting increment table", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, nil, http.StatusNoContent)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import "html/template"
+
+// Printable invoice, the browser's print dialog turns it into a PDF
+var invoiceTemplate = template.Must(template.New("invoice").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Number}}</title>
+<style>
+  body { font-family: sans-serif; margin: 2rem auto; max-width: 48rem; color: #222; }
+  h1 { margin-bottom: 0; }
+  .meta, .parties { display: flex; justify-content: space-between; margin: 1.5rem 0; }
+  table { width: 100%; border-collapse: collapse; }
+  th, td { padding: 0.5rem; border-bottom: 1px solid #ddd; text-align: left; }
+  td.amount, th.amount { text-align: right; }
+  tfoot td { font-weight: bold; border-bottom: none; }
+  @media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>Invoice {{.Number}}</h1>
+<div class="meta">
+  <div>Issued {{.IssuedAt.Format "2 January 2006"}}</div>
+  <div>Payment: {{.PaymentStatus}}{{if .PaidAt}} on {{.PaidAt.Format "2 January 2006"}}{{end}}</div>
+</div>
+<div class="parties">
+  <div><strong>Seller</strong><br>{{.SellerUsername}}</div>
+  <div><strong>Buyer</strong><br>{{.BuyerUsername}}</div>
+</div>
+<p>{{.ItemName}}{{if gt .Quantity 1}} &times; {{.Quantity}}{{end}}</p>
+<table>
+  <thead>
+    <tr><th>Description</th><th class="amount">Amount ({{.Currency}})</th></tr>
+  </thead>
+  <tbody>
+  {{range .Lines}}
+    <tr><td>{{.Description}}</td><td class="amount">{{.Amount.Format $.Currency}}</td></tr>
+  {{end}}
+  </tbody>
+  <tfoot>
+    <tr><td>Total</td><td class="amount">{{.Total.Format .Currency}} {{.Currency}}</td></tr>
+    {{if gt .WalletAmount 0}}
+    <tr><td>Paid from wallet</td><td class="amount">{{.WalletAmount.Format .Currency}} {{.Currency}}</td></tr>
+    <tr><td>Amount due</td><td class="amount">{{.AmountDue.Format .Currency}} {{.Currency}}</td></tr>
+    {{end}}
+  </tfoot>
+</table>
+</body>
+</html>
+`))
@@ -3,25 +3,33 @@ package handlers
 import (
 	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/bangueco/auction-api/internal/handlers/helper"
 	"github.com/bangueco/auction-api/internal/lib"
 	"github.com/bangueco/auction-api/internal/models"
 	"github.com/bangueco/auction-api/internal/services"
 	"github.com/go-chi/chi/v5"
-	"github.com/jackc/pgx/v5"
 )
 
 type ItemHandler struct {
-	ItemService *services.ItemService
+	ItemService         *services.ItemService
+	ExchangeRateService *services.ExchangeRateService
 }
 
-func NewItemHandler(ItemService *services.ItemService) *ItemHandler {
-	return &ItemHandler{ItemService}
+func NewItemHandler(ItemService *services.ItemService, ExchangeRateService *services.ExchangeRateService) *ItemHandler {
+	return &ItemHandler{ItemService, ExchangeRateService}
 }
 
 func (i *ItemHandler) GetItems(w http.ResponseWriter, r *http.Request) {
-	items, err := i.ItemService.GetItems()
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	items, err := i.ItemService.GetItems(userId)
 
 	if errors.Is(err, services.ErrItemsNotFound) {
 		helper.WriteResponseMessage(w, "No items found", http.StatusNotFound)
@@ -38,6 +46,10 @@ func (i *ItemHandler) GetItems(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !i.convertItems(w, r, items) {
+		return
+	}
+
 	helper.WriteResponse(w, items, http.StatusOK)
 }
 
@@ -51,14 +63,32 @@ func (i *ItemHandler) GetItemByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	item, err := i.ItemService.GetItemByID(id)
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	item, err := i.ItemService.GetItemByID(id, userId)
 
 	if errors.Is(err, services.ErrItemNotFound) {
 		helper.WriteResponseMessage(w, "Item not found", http.StatusNotFound)
 		return
 	}
 
-	helper.WriteResponse(w, item, http.StatusOK)
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error retrieving item", http.StatusInternalServerError)
+		return
+	}
+
+	items := []models.Item{item}
+
+	if !i.convertItems(w, r, items) {
+		return
+	}
+
+	helper.WriteResponse(w, items[0], http.StatusOK)
 }
 
 func (i *ItemHandler) CreateItem(w http.ResponseWriter, r *http.Request) {
@@ -85,10 +115,10 @@ func (i *ItemHandler) CreateItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	item, err := i.ItemService.CreateItem(models.Item{ItemName: newItem.ItemName, BidAmount: newItem.BidAmount, AuctionedBy: userId})
+	item, err := i.ItemService.CreateItem(models.Item{ItemName: newItem.ItemName, BidAmount: newItem.BidAmount, AuctionedBy: userId, StartsAt: newItem.StartsAt, EndsAt: newItem.EndsAt, ReservePrice: newItem.ReservePrice, BuyNowPrice: newItem.BuyNowPrice, Category: newItem.Category, IncrementTable: newItem.IncrementTable, AuctionType: newItem.AuctionType, FloorPrice: newItem.FloorPrice, PriceDrop: newItem.PriceDrop, PriceDropInterval: newItem.PriceDropInterval, Quantity: newItem.Quantity, PricingRule: newItem.PricingRule})
 
 	if err != nil {
-		helper.WriteResponseMessage(w, "Error creating item", http.StatusInternalServerError)
+		writeItemError(w, err, "Error creating item")
 		return
 	}
 
@@ -120,10 +150,17 @@ func (i *ItemHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	updatedItem, err := i.ItemService.UpdateItem(id, item)
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
 
-	if errors.Is(err, services.ErrItemNotFound) {
-		helper.WriteResponseMessage(w, "Item not found", http.StatusNotFound)
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	updatedItem, err := i.ItemService.UpdateItem(id, userId, item)
+
+	if err != nil {
+		writeItemError(w, err, "Error updating item")
 		return
 	}
 
@@ -140,12 +177,125 @@ func (i *ItemHandler) DeleteItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = i.ItemService.DeleteItem(id)
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
 
-	if errors.Is(err, pgx.ErrNoRows) {
-		helper.WriteResponseMessage(w, "Item does not exist", http.StatusBadRequest)
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	err = i.ItemService.DeleteItem(id, userId)
+
+	if err != nil {
+		writeItemError(w, err, "Error deleting item")
 		return
 	}
 
 	helper.WriteResponse(w, nil, http.StatusNoContent)
 }
+
+func (i *ItemHandler) UpdateItemStatus(w http.ResponseWriter, r *http.Request) {
+	var statusUpdate models.ItemStatusUpdate
+	idParam := chi.URLParam(r, "id")
+
+	id, err := helper.ConvertStringToInt64(idParam)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	err = helper.DecodeRequestBody(r, &statusUpdate)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	errorMessages := lib.ValidateStruct(&statusUpdate)
+
+	if errorMessages != nil {
+		helper.WriteResponse(w, errorMessages, http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	updatedItem, err := i.ItemService.UpdateItemStatus(id, userId, statusUpdate.Status)
+
+	if err != nil {
+		writeItemError(w, err, "Error updating item status")
+		return
+	}
+
+	helper.WriteResponse(w, updatedItem, http.StatusOK)
+}
+
+// Convert item amounts into the currency asked for with ?display_currency=,
+// it writes the error response and returns false when the conversion fails
+func (i *ItemHandler) convertItems(w http.ResponseWriter, r *http.Request, items []models.Item) bool {
+	currency := strings.ToUpper(r.URL.Query().Get("display_currency"))
+
+	if currency == "" {
+		return true
+	}
+
+	err := i.ExchangeRateService.ConvertItems(items, currency)
+
+	if errors.Is(err, models.ErrUnknownCurrency) {
+		helper.WriteResponseMessage(w, "No exchange rate for display currency "+currency, http.StatusBadRequest)
+		return false
+	}
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error converting item amounts", http.StatusInternalServerError)
+		return false
+	}
+
+	return true
+}
+
+// Write the response for an error returned by one of the item write operations
+func writeItemError(w http.ResponseWriter, err error, fallbackMessage string) {
+	switch {
+	case errors.Is(err, services.ErrItemNotFound):
+		helper.WriteResponseMessage(w, "Item not found", http.StatusNotFound)
+	case errors.Is(err, services.ErrNotItemOwner):
+		helper.WriteResponseMessage(w, "You do not own this item", http.StatusForbidden)
+	case errors.Is(err, services.ErrItemNotEditable):
+		helper.WriteResponseMessage(w, "Item can no longer be edited", http.StatusConflict)
+	case errors.Is(err, services.ErrItemNotDeletable):
+		helper.WriteResponseMessage(w, "Item can no longer be deleted", http.StatusConflict)
+	case errors.Is(err, services.ErrItemInLot):
+		helper.WriteResponseMessage(w, "Item is sold as part of a lot, change the lot instead", http.StatusConflict)
+	case errors.Is(err, services.ErrLotAuctionItem):
+		helper.WriteResponseMessage(w, "Item is the auction of a lot, create the lot again to change it", http.StatusConflict)
+	case errors.Is(err, services.ErrInvalidTransition):
+		helper.WriteResponseMessage(w, "Item cannot move to that status", http.StatusConflict)
+	case errors.Is(err, services.ErrCatalogLotTiming):
+		helper.WriteResponseMessage(w, "The times of a catalog lot are set by its catalog, change the catalog instead", http.StatusConflict)
+	case errors.Is(err, services.ErrInvalidBuyNowPrice):
+		helper.WriteResponseMessage(w, "Buy-now price must be above the starting bid and the reserve price", http.StatusBadRequest)
+	case errors.Is(err, services.ErrInvalidDutchSchedule):
+		helper.WriteResponseMessage(w, "Dutch auctions need a floor price below the start price, a price drop and a drop interval, and no reserve or buy-now price", http.StatusBadRequest)
+	case errors.Is(err, services.ErrEnglishOnlyPricing):
+		helper.WriteResponseMessage(w, "Buy-now prices and increment tables are only available in english auctions", http.StatusBadRequest)
+	case errors.Is(err, services.ErrInvalidReversePrice):
+		helper.WriteResponseMessage(w, "Reverse auctions have no reserve or buy-now price", http.StatusBadRequest)
+	case errors.Is(err, services.ErrInvalidMultiUnit):
+		helper.WriteResponseMessage(w, "Multi-unit auctions must be english auctions without a buy-now price", http.StatusBadRequest)
+	case errors.Is(err, models.ErrInvalidMoneyForCurrency):
+		helper.WriteResponseMessage(w, "Amounts have more decimal places than the item's currency allows", http.StatusBadRequest)
+	case errors.Is(err, models.ErrInvalidIncrementTable):
+		helper.WriteResponseMessage(w, "Increment table must start at 0 with increasing tiers and positive steps", http.StatusBadRequest)
+	case errors.Is(err, services.ErrInvalidAuctionWindow):
+		helper.WriteResponseMessage(w, "Auction needs a start time and an end time in the future", http.StatusBadRequest)
+	default:
+		helper.WriteResponseMessage(w, fallbackMessage, http.StatusInternalServerError)
+	}
+}
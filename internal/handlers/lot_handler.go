@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bangueco/auction-api/internal/handlers/helper"
+	"github.com/bangueco/auction-api/internal/lib"
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+// LotHandler serves lots, bids on a lot are placed on its auction item through the bid handler
+type LotHandler struct {
+	LotService *services.LotService
+	BidHandler *BidHandler
+}
+
+func NewLotHandler(LotService *services.LotService, BidHandler *BidHandler) *LotHandler {
+	return &LotHandler{LotService, BidHandler}
+}
+
+func (l *LotHandler) CreateLot(w http.ResponseWriter, r *http.Request) {
+	var request models.CreateLotRequest
+
+	err := helper.DecodeRequestBody(r, &request)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	errorMessages := lib.ValidateStruct(&request)
+
+	if errorMessages != nil {
+		helper.WriteResponse(w, errorMessages, http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	lot, err := l.LotService.CreateLot(userId, request)
+
+	if err != nil {
+		writeLotError(w, err, "Error creating lot")
+		return
+	}
+
+	helper.WriteResponse(w, lot, http.StatusCreated)
+}
+
+func (l *LotHandler) GetLots(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	page, limit := helper.GetPagination(r)
+
+	lotPage, err := l.LotService.GetLots(userId, page, limit)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error retrieving lots", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, lotPage, http.StatusOK)
+}
+
+func (l *LotHandler) GetLotByID(w http.ResponseWriter, r *http.Request) {
+	lotID, err := helper.ConvertStringToInt64(chi.URLParam(r, "id"))
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid lot ID", http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	lot, err := l.LotService.GetLotByID(lotID, userId)
+
+	if err != nil {
+		writeLotError(w, err, "Error retrieving lot")
+		return
+	}
+
+	helper.WriteResponse(w, lot, http.StatusOK)
+}
+
+func (l *LotHandler) SetLotItems(w http.ResponseWriter, r *http.Request) {
+	var update models.LotItemsUpdate
+
+	lotID, err := helper.ConvertStringToInt64(chi.URLParam(r, "id"))
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid lot ID", http.StatusBadRequest)
+		return
+	}
+
+	err = helper.DecodeRequestBody(r, &update)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	errorMessages := lib.ValidateStruct(&update)
+
+	if errorMessages != nil {
+		helper.WriteResponse(w, errorMessages, http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	lot, err := l.LotService.SetLotItems(lotID, userId, update.ItemIDs)
+
+	if err != nil {
+		writeLotError(w, err, "Error updating lot items")
+		return
+	}
+
+	helper.WriteResponse(w, lot, http.StatusOK)
+}
+
+func (l *LotHandler) PlaceBid(w http.ResponseWriter, r *http.Request) {
+	lot, ok := l.lotFromRequest(w, r)
+
+	if !ok {
+		return
+	}
+
+	l.BidHandler.placeBid(w, r, lot.ItemID)
+}
+
+func (l *LotHandler) GetBidHistory(w http.ResponseWriter, r *http.Request) {
+	lot, ok := l.lotFromRequest(w, r)
+
+	if !ok {
+		return
+	}
+
+	l.BidHandler.writeBidHistory(w, r, lot.ItemID)
+}
+
+// Look up the lot named in the URL, it writes the error response and returns false when there is none
+func (l *LotHandler) lotFromRequest(w http.ResponseWriter, r *http.Request) (models.Lot, bool) {
+	lotID, err := helper.ConvertStringToInt64(chi.URLParam(r, "id"))
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid lot ID", http.StatusBadRequest)
+		return models.Lot{}, false
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return models.Lot{}, false
+	}
+
+	lot, err := l.LotService.GetLotByID(lotID, userId)
+
+	if err != nil {
+		writeLotError(w, err, "Error retrieving lot")
+		return lot, false
+	}
+
+	return lot, true
+}
+
+// Write the response for an error returned by one of the lot operations
+func writeLotError(w http.ResponseWriter, err error, fallbackMessage string) {
+	switch {
+	case errors.Is(err, services.ErrLotNotFound):
+		helper.WriteResponseMessage(w, "Lot not found", http.StatusNotFound)
+	case errors.Is(err, services.ErrItemNotFound):
+		helper.WriteResponseMessage(w, "Item not found", http.StatusNotFound)
+	case errors.Is(err, services.ErrNotItemOwner):
+		helper.WriteResponseMessage(w, "You do not own this lot or one of its items", http.StatusForbidden)
+	case errors.Is(err, services.ErrLotNotEditable):
+		helper.WriteResponseMessage(w, "Lot can no longer be changed", http.StatusConflict)
+	case errors.Is(err, services.ErrItemNotGroupable):
+		helper.WriteResponseMessage(w, "Only draft items that are not in another lot can be grouped", http.StatusConflict)
+	case errors.Is(err, services.ErrInvalidAuctionWindow):
+		helper.WriteResponseMessage(w, "Lot needs both a start time and an end time in the future, or neither", http.StatusBadRequest)
+	case errors.Is(err, models.ErrInvalidMoneyForCurrency):
+		helper.WriteResponseMessage(w, "Amounts have more decimal places than the lot's currency allows", http.StatusBadRequest)
+	default:
+		helper.WriteResponseMessage(w, fallbackMessage, http.StatusInternalServerError)
+	}
+}
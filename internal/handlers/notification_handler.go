@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bangueco/auction-api/internal/handlers/helper"
+	"github.com/bangueco/auction-api/internal/lib"
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type NotificationHandler struct {
+	NotificationService *services.NotificationService
+}
+
+func NewNotificationHandler(NotificationService *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{NotificationService}
+}
+
+// List the user's notifications newest first, only the unread ones with ?unread=true
+func (nh *NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	page, limit := helper.GetPagination(r)
+
+	notificationPage, err := nh.NotificationService.GetNotifications(userId, r.URL.Query().Get("unread") == "true", page, limit)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error retrieving notifications", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, notificationPage, http.StatusOK)
+}
+
+func (nh *NotificationHandler) GetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	unread, err := nh.NotificationService.GetUnreadCount(userId)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error counting notifications", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, unread, http.StatusOK)
+}
+
+func (nh *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ConvertStringToInt64(chi.URLParam(r, "id"))
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid notification ID", http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	notification, err := nh.NotificationService.MarkRead(userId, id)
+
+	if errors.Is(err, services.ErrNotificationNotFound) {
+		helper.WriteResponseMessage(w, "Notification not found", http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error updating notification", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, notification, http.StatusOK)
+}
+
+func (nh *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	err := nh.NotificationService.MarkAllRead(userId)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error updating notifications", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, models.UnreadCount{Unread: 0}, http.StatusOK)
+}
+
+func (nh *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	preferences, err := nh.NotificationService.GetPreferences(userId)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error retrieving notification preferences", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, preferences, http.StatusOK)
+}
+
+func (nh *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	var update models.NotificationPreferencesUpdate
+
+	err := helper.DecodeRequestBody(r, &update)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	errorMessages := lib.ValidateStruct(&update)
+
+	if errorMessages != nil {
+		helper.WriteResponse(w, errorMessages, http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	preferences, err := nh.NotificationService.UpdatePreferences(userId, update)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error updating notification preferences", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, preferences, http.StatusOK)
+}
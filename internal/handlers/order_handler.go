@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/bangueco/auction-api/internal/handlers/helper"
+	"github.com/bangueco/auction-api/internal/lib"
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/payments"
+	"github.com/bangueco/auction-api/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+// Largest webhook body read from the payment provider
+const maxWebhookSize = 64 << 10
+
+type OrderHandler struct {
+	OrderService *services.OrderService
+}
+
+func NewOrderHandler(OrderService *services.OrderService) *OrderHandler {
+	return &OrderHandler{OrderService}
+}
+
+func (o *OrderHandler) GetPurchases(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	orders, err := o.OrderService.GetPurchases(userId)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error retrieving orders", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, orders, http.StatusOK)
+}
+
+func (o *OrderHandler) GetSales(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	orders, err := o.OrderService.GetSales(userId)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error retrieving sales", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, orders, http.StatusOK)
+}
+
+func (o *OrderHandler) GetPurchase(w http.ResponseWriter, r *http.Request) {
+	o.writeOrder(w, r, o.OrderService.GetPurchase)
+}
+
+func (o *OrderHandler) GetSale(w http.ResponseWriter, r *http.Request) {
+	o.writeOrder(w, r, o.OrderService.GetSale)
+}
+
+// Look up the order in the URL as the authenticated user and write it
+func (o *OrderHandler) writeOrder(w http.ResponseWriter, r *http.Request, getOrder func(orderID int64, userID int64) (models.Order, error)) {
+	orderID, err := helper.ConvertStringToInt64(chi.URLParam(r, "id"))
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	order, err := getOrder(orderID, userId)
+
+	if err != nil {
+		writeOrderError(w, err, "Error retrieving order")
+		return
+	}
+
+	helper.WriteResponse(w, order, http.StatusOK)
+}
+
+// Write the invoice of an order as JSON, or as a printable HTML page with ?format=html
+func (o *OrderHandler) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	orderID, err := helper.ConvertStringToInt64(chi.URLParam(r, "id"))
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	invoice, err := o.OrderService.GetInvoice(orderID, userId)
+
+	if err != nil {
+		writeOrderError(w, err, "Error retrieving invoice")
+		return
+	}
+
+	if r.URL.Query().Get("format") != "html" {
+		helper.WriteResponse(w, invoice, http.StatusOK)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	err = invoiceTemplate.Execute(w, invoice)
+
+	if err != nil {
+		log.Printf("Error rendering invoice: %v", err)
+	}
+}
+
+func (o *OrderHandler) UpdatePaymentStatus(w http.ResponseWriter, r *http.Request) {
+	var update models.PaymentStatusUpdate
+
+	orderID, err := helper.ConvertStringToInt64(chi.URLParam(r, "id"))
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	err = helper.DecodeRequestBody(r, &update)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	errorMessages := lib.ValidateStruct(&update)
+
+	if errorMessages != nil {
+		helper.WriteResponse(w, errorMessages, http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	order, err := o.OrderService.UpdatePaymentStatus(orderID, userId, update)
+
+	if err != nil {
+		writeOrderError(w, err, "Error updating payment status")
+		return
+	}
+
+	helper.WriteResponse(w, order, http.StatusOK)
+}
+
+func (o *OrderHandler) PayOrder(w http.ResponseWriter, r *http.Request) {
+	var request models.PayOrderRequest
+
+	orderID, err := helper.ConvertStringToInt64(chi.URLParam(r, "id"))
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	err = helper.DecodeRequestBody(r, &request)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	errorMessages := lib.ValidateStruct(&request)
+
+	if errorMessages != nil {
+		helper.WriteResponse(w, errorMessages, http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	order, err := o.OrderService.Pay(orderID, userId, request)
+
+	if errors.Is(err, services.ErrPaymentDeclined) {
+		helper.WriteResponse(w, order, http.StatusPaymentRequired)
+		return
+	}
+
+	if err != nil {
+		writeOrderError(w, err, "Error paying order")
+		return
+	}
+
+	helper.WriteResponse(w, order, http.StatusOK)
+}
+
+// Receive a payment provider webhook, the signature header proves it came from the provider
+func (o *OrderHandler) PaymentWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookSize))
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = o.OrderService.HandleWebhook(payload, r.Header.Get("X-Payment-Signature"))
+
+	if errors.Is(err, payments.ErrInvalidSignature) {
+		helper.WriteResponseMessage(w, "Invalid webhook signature", http.StatusUnauthorized)
+		return
+	}
+
+	if errors.Is(err, payments.ErrUnknownTransaction) {
+		helper.WriteResponseMessage(w, "Unknown payment transaction", http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error handling webhook", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponseMessage(w, "Webhook received", http.StatusOK)
+}
+
+// Write the response for an error returned by one of the order operations
+func writeOrderError(w http.ResponseWriter, err error, fallbackMessage string) {
+	switch {
+	case errors.Is(err, services.ErrOrderNotFound):
+		helper.WriteResponseMessage(w, "Order not found", http.StatusNotFound)
+	case errors.Is(err, services.ErrNotOrderParty):
+		helper.WriteResponseMessage(w, "You are not a party to this order", http.StatusForbidden)
+	case errors.Is(err, services.ErrInvalidPaymentTransition):
+		helper.WriteResponseMessage(w, "Order cannot move to this payment status", http.StatusConflict)
+	case errors.Is(err, services.ErrPaidByProvider):
+		helper.WriteResponseMessage(w, "Orders are only marked paid once the payment provider captures the payment", http.StatusConflict)
+	case errors.Is(err, services.ErrRefundFailed):
+		helper.WriteResponseMessage(w, "Refund could not be completed by the payment provider", http.StatusBadGateway)
+	case errors.Is(err, services.ErrInsufficientFunds):
+		helper.WriteResponseMessage(w, "Not enough available funds in your wallet to refund the buyer", http.StatusConflict)
+	default:
+		helper.WriteResponseMessage(w, fallbackMessage, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bangueco/auction-api/internal/handlers/helper"
+	"github.com/bangueco/auction-api/internal/lib"
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/services"
+)
+
+type WalletHandler struct {
+	WalletService *services.WalletService
+}
+
+func NewWalletHandler(WalletService *services.WalletService) *WalletHandler {
+	return &WalletHandler{WalletService}
+}
+
+func (wh *WalletHandler) GetWallet(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	wallets, err := wh.WalletService.GetWallets(userId)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error retrieving wallet", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, wallets, http.StatusOK)
+}
+
+// List the user's holds, only the active ones with ?active=true
+func (wh *WalletHandler) GetHolds(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	holds, err := wh.WalletService.GetHolds(userId, r.URL.Query().Get("active") == "true")
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error retrieving holds", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, holds, http.StatusOK)
+}
+
+func (wh *WalletHandler) Withdraw(w http.ResponseWriter, r *http.Request) {
+	var request models.WithdrawalRequest
+
+	err := helper.DecodeRequestBody(r, &request)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	errorMessages := lib.ValidateStruct(&request)
+
+	if errorMessages != nil {
+		helper.WriteResponse(w, errorMessages, http.StatusBadRequest)
+		return
+	}
+
+	userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+	if !ok {
+		helper.WriteResponseMessage(w, "User not authenticated", http.StatusBadRequest)
+		return
+	}
+
+	wallets, err := wh.WalletService.Withdraw(userId, request)
+
+	if errors.Is(err, models.ErrInvalidMoneyForCurrency) {
+		helper.WriteResponseMessage(w, "Amount has more decimal places than the currency allows", http.StatusBadRequest)
+		return
+	}
+
+	if errors.Is(err, services.ErrInsufficientFunds) {
+		helper.WriteResponseMessage(w, "Not enough available funds in your wallet", http.StatusConflict)
+		return
+	}
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error withdrawing funds", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, wallets, http.StatusOK)
+}
+
+func (wh *WalletHandler) Deposit(w http.ResponseWriter, r *http.Request) {
+	var request models.DepositRequest
+
+	err := helper.DecodeRequestBody(r, &request)
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	errorMessages := lib.ValidateStruct(&request)
+
+	if errorMessages != nil {
+		helper.WriteResponse(w, errorMessages, http.StatusBadRequest)
+		return
+	}
+
+	wallets, err := wh.WalletService.Deposit(request)
+
+	if errors.Is(err, models.ErrInvalidMoneyForCurrency) {
+		helper.WriteResponseMessage(w, "Amount has more decimal places than the currency allows", http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error depositing funds", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, wallets, http.StatusCreated)
+}
+
+func (wh *WalletHandler) CheckLedger(w http.ResponseWriter, r *http.Request) {
+	check, err := wh.WalletService.CheckLedger()
+
+	if err != nil {
+		helper.WriteResponseMessage(w, "Error checking ledger", http.StatusInternalServerError)
+		return
+	}
+
+	helper.WriteResponse(w, check, http.StatusOK)
+}
@@ -6,7 +6,9 @@ import (
 	"log"
 	"reflect"
 	"strings"
+	"unicode"
 
+	"github.com/bangueco/auction-api/internal/models"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -28,6 +30,8 @@ func ValidateStruct(s any) (errorMessages []ValidateStructErrMessage) {
 		}
 		return name
 	})
+	validate.RegisterValidation("money_min", validateMoney(func(amount, limit models.Money) bool { return amount >= limit }))
+	validate.RegisterValidation("money_gt", validateMoney(func(amount, limit models.Money) bool { return amount > limit }))
 
 	err := validate.Struct(s)
 	if err != nil {
@@ -40,6 +44,8 @@ func ValidateStruct(s any) (errorMessages []ValidateStructErrMessage) {
 				switch e.Tag() {
 				case "required":
 					msg = fmt.Sprintf("%s is required", e.Field())
+				case "required_without":
+					msg = fmt.Sprintf("%s is required when %s is not set", e.Field(), toSnakeCase(e.Param()))
 				case "email":
 					msg = "Invalid email format"
 				case "min":
@@ -60,6 +66,16 @@ func ValidateStruct(s any) (errorMessages []ValidateStructErrMessage) {
 					msg = fmt.Sprintf("%s must be greater than %s", e.Field(), e.Param())
 				case "gte":
 					msg = fmt.Sprintf("%s must be greater than or equal to %s", e.Field(), e.Param())
+				case "gtfield":
+					msg = fmt.Sprintf("%s must be after %s", e.Field(), toSnakeCase(e.Param()))
+				case "oneof":
+					msg = fmt.Sprintf("%s must be one of: %s", e.Field(), e.Param())
+				case "money_min":
+					msg = fmt.Sprintf("%s must be at least %s", e.Field(), e.Param())
+				case "money_gt":
+					msg = fmt.Sprintf("%s must be greater than %s", e.Field(), e.Param())
+				case "iso4217":
+					msg = fmt.Sprintf("%s must be an ISO 4217 currency code", e.Field())
 				case "alphanum":
 					msg = fmt.Sprintf("%s must contain only alphanumeric characters", e.Field())
 				case "url":
@@ -83,3 +99,39 @@ func ValidateStruct(s any) (errorMessages []ValidateStructErrMessage) {
 
 	return nil
 }
+
+// Build a validation that compares a models.Money field with the decimal amount given as the tag parameter
+func validateMoney(compare func(amount, limit models.Money) bool) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		amount, ok := fl.Field().Interface().(models.Money)
+
+		if !ok {
+			return false
+		}
+
+		limit, err := models.ParseMoney(fl.Param())
+
+		if err != nil {
+			return false
+		}
+
+		return compare(amount, limit)
+	}
+}
+
+// Convert a Go field name such as StartsAt to its json name starts_at
+func toSnakeCase(s string) string {
+	var b strings.Builder
+
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/bangueco/auction-api/internal/handlers/helper"
@@ -50,3 +51,47 @@ func AuthGuard(handler http.Handler) http.Handler {
 		handler.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// Authenticates WebSocket and EventSource requests, which browsers cannot set headers on, with a single-use ?ticket=.
+// Without a ticket the Authorization header is checked as usual, so other clients keep using their bearer token.
+func StreamAuth(redeem func(ticket string) (int64, error)) func(http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		guarded := AuthGuard(handler)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ticket := r.URL.Query().Get("ticket")
+
+			if ticket == "" {
+				guarded.ServeHTTP(w, r)
+				return
+			}
+
+			userId, err := redeem(ticket)
+
+			if err != nil {
+				helper.WriteResponseMessage(w, "Invalid or expired stream ticket", http.StatusBadRequest)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), helper.UserIDKey, userId)
+
+			handler.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Only lets the given admins through, has to run after AuthGuard
+func AdminGuard(adminIDs []int64) func(http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userId, ok := r.Context().Value(helper.UserIDKey).(int64)
+
+			if !ok || !slices.Contains(adminIDs, userId) {
+				helper.WriteResponseMessage(w, "You need to be an admin to access this", http.StatusForbidden)
+				return
+			}
+
+			handler.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// How the winners of a multi-unit auction pay for their units
+type PricingRule string
+
+const (
+	// Every winner pays the lowest winning bid
+	PricingRuleUniform PricingRule = "uniform"
+	// Every winner pays what they bid, also called discriminatory pricing
+	PricingRulePayAsBid PricingRule = "pay_as_bid"
+)
+
+// Units of a multi-unit auction handed to a winning bid
+type Allocation struct {
+	ID         int64     `json:"id,omitempty"`
+	ItemID     int64     `json:"item_id"`
+	BidID      int64     `json:"bid_id"`
+	BidderID   int64     `json:"bidder_id"`
+	Quantity   int       `json:"quantity"`
+	UnitPrice  Money     `json:"unit_price"`
+	TotalPrice Money     `json:"total_price"`
+	CreatedAt  time.Time `json:"created_at,omitzero"`
+}
+
+// Hand out quantity units to bids ranked best first.
+// Bids below the reserve get nothing and the last bid to get units may only be filled in part.
+// Under uniform pricing every allocation is priced at the lowest winning bid.
+func AllocateUnits(quantity int, ranked []Bid, rule PricingRule, reserve *Money) []Allocation {
+	var allocations []Allocation
+	remaining := quantity
+
+	for _, bid := range ranked {
+		if remaining == 0 || (reserve != nil && bid.Amount < *reserve) {
+			break
+		}
+
+		units := min(remaining, bid.Quantity)
+		allocations = append(allocations, Allocation{ItemID: bid.ItemID, BidID: bid.ID, BidderID: bid.BidderID, Quantity: units, UnitPrice: bid.Amount})
+		remaining -= units
+	}
+
+	if rule == PricingRuleUniform && len(allocations) > 0 {
+		clearingPrice := allocations[len(allocations)-1].UnitPrice
+
+		for i := range allocations {
+			allocations[i].UnitPrice = clearingPrice
+		}
+	}
+
+	for i := range allocations {
+		allocations[i].TotalPrice = allocations[i].UnitPrice * Money(allocations[i].Quantity)
+	}
+
+	return allocations
+}
@@ -0,0 +1,61 @@
+package models
+
+import "testing"
+
+func TestAllocateUnits(t *testing.T) {
+	ranked := []Bid{
+		{ID: 1, ItemID: 7, BidderID: 10, Amount: 500000, Quantity: 2},
+		{ID: 2, ItemID: 7, BidderID: 11, Amount: 400000, Quantity: 3},
+		{ID: 3, ItemID: 7, BidderID: 12, Amount: 300000, Quantity: 1},
+	}
+	reserve := Money(350000)
+
+	type allocated struct {
+		bidderID  int64
+		quantity  int
+		unitPrice Money
+	}
+
+	tests := []struct {
+		name     string
+		quantity int
+		bids     []Bid
+		rule     PricingRule
+		reserve  *Money
+		want     []allocated
+	}{
+		{"pay as bid fills in rank order", 6, ranked, PricingRulePayAsBid, nil, []allocated{{10, 2, 500000}, {11, 3, 400000}, {12, 1, 300000}}},
+		{"last winner filled in part", 4, ranked, PricingRulePayAsBid, nil, []allocated{{10, 2, 500000}, {11, 2, 400000}}},
+		{"uniform prices at the lowest winning bid", 4, ranked, PricingRuleUniform, nil, []allocated{{10, 2, 400000}, {11, 2, 400000}}},
+		{"units left over", 10, ranked, PricingRuleUniform, nil, []allocated{{10, 2, 300000}, {11, 3, 300000}, {12, 1, 300000}}},
+		{"bids below the reserve get nothing", 10, ranked, PricingRuleUniform, &reserve, []allocated{{10, 2, 400000}, {11, 3, 400000}}},
+		{"no bids", 3, nil, PricingRuleUniform, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AllocateUnits(tt.quantity, tt.bids, tt.rule, tt.reserve)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("AllocateUnits() = %v, want %v", got, tt.want)
+			}
+
+			for i, want := range tt.want {
+				allocation := got[i]
+
+				if allocation.BidderID != want.bidderID || allocation.Quantity != want.quantity || allocation.UnitPrice != want.unitPrice {
+					t.Errorf("allocation %d = bidder %d, %d units at %s, want bidder %d, %d units at %s",
+						i, allocation.BidderID, allocation.Quantity, allocation.UnitPrice, want.bidderID, want.quantity, want.unitPrice)
+				}
+
+				if allocation.TotalPrice != want.unitPrice*Money(want.quantity) {
+					t.Errorf("allocation %d total = %s, want %s", i, allocation.TotalPrice, want.unitPrice*Money(want.quantity))
+				}
+
+				if allocation.ItemID != 7 {
+					t.Errorf("allocation %d item = %d, want 7", i, allocation.ItemID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+type AuctionType string
+
+const (
+	// Bids climb from BidAmount and the highest bid wins
+	AuctionTypeEnglish AuctionType = "english"
+	// The price falls from BidAmount towards FloorPrice and the first bidder to accept it wins
+	AuctionTypeDutch AuctionType = "dutch"
+	// Bids stay hidden until the close, the highest bidder wins and pays their own bid
+	AuctionTypeSealedFirstPrice AuctionType = "sealed_first_price"
+	// Bids stay hidden until the close, the highest bidder wins and pays the second highest bid
+	AuctionTypeVickrey AuctionType = "vickrey"
+	// Suppliers bid down from BidAmount and the lowest bid leads, the owner awards the contract
+	AuctionTypeReverse AuctionType = "reverse"
+)
+
+// How a bidder may change their sealed bid before the auction closes
+type SealedRevisionRule string
+
+const (
+	SealedRevisionNone     SealedRevisionRule = "none"
+	SealedRevisionIncrease SealedRevisionRule = "increase"
+	SealedRevisionAny      SealedRevisionRule = "any"
+)
+
+// Check that the rule is one of the known revision rules
+func (r SealedRevisionRule) IsValid() bool {
+	return r == SealedRevisionNone || r == SealedRevisionIncrease || r == SealedRevisionAny
+}
+
+// Check whether bids on the auction are kept hidden until it closes
+func (t AuctionType) IsSealed() bool {
+	return t == AuctionTypeSealedFirstPrice || t == AuctionTypeVickrey
+}
+
+// Return the price of a dutch auction at the given time and when it drops next.
+// The price only depends on the start time and the drop schedule, so every instance computes the same price.
+// It drops by PriceDrop once every PriceDropInterval seconds after StartsAt and never falls below FloorPrice.
+func (i Item) DutchPriceAt(now time.Time) (Money, *time.Time) {
+	if i.FloorPrice == nil || i.PriceDrop == nil || i.PriceDropInterval == nil || i.StartsAt == nil {
+		return i.BidAmount, nil
+	}
+
+	interval := time.Duration(*i.PriceDropInterval) * time.Second
+	drops := int64(0)
+
+	if now.After(*i.StartsAt) {
+		drops = int64(now.Sub(*i.StartsAt) / interval)
+	}
+
+	// Number of drops it takes to reach the floor, rounded up
+	dropsToFloor := int64((i.BidAmount - *i.FloorPrice + *i.PriceDrop - 1) / *i.PriceDrop)
+
+	if drops >= dropsToFloor {
+		return *i.FloorPrice, nil
+	}
+
+	nextDropAt := i.StartsAt.Add(time.Duration(drops+1) * interval)
+
+	return i.BidAmount - Money(drops)*(*i.PriceDrop), &nextDropAt
+}
+
+// The new asking price of a dutch auction
+type PriceChange struct {
+	ItemID          int64      `json:"item_id"`
+	CurrentPrice    Money      `json:"current_price"`
+	NextPriceDropAt *time.Time `json:"next_price_drop_at,omitempty"`
+	Currency        string     `json:"currency"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestItemDutchPriceAt(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	floor := Money(250000)
+	drop := Money(300000)
+	interval := 60
+
+	item := Item{AuctionType: AuctionTypeDutch, BidAmount: 1000000, FloorPrice: &floor, PriceDrop: &drop, PriceDropInterval: &interval, StartsAt: &start}
+
+	at := func(d time.Duration) *time.Time {
+		next := start.Add(d)
+		return &next
+	}
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		wantPrice  Money
+		wantNextAt *time.Time
+	}{
+		{"before the start", start.Add(-time.Hour), 1000000, at(time.Minute)},
+		{"at the start", start, 1000000, at(time.Minute)},
+		{"just before the first drop", start.Add(time.Minute - time.Nanosecond), 1000000, at(time.Minute)},
+		{"exactly at the first drop", start.Add(time.Minute), 700000, at(2 * time.Minute)},
+		{"between drops", start.Add(150 * time.Second), 400000, at(3 * time.Minute)},
+		{"drop that would pass the floor stops at it", start.Add(3 * time.Minute), 250000, nil},
+		{"long after the floor", start.Add(24 * time.Hour), 250000, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, nextDropAt := item.DutchPriceAt(tt.now)
+
+			if price != tt.wantPrice {
+				t.Errorf("DutchPriceAt() price = %s, want %s", price, tt.wantPrice)
+			}
+
+			if (nextDropAt == nil) != (tt.wantNextAt == nil) || (nextDropAt != nil && !nextDropAt.Equal(*tt.wantNextAt)) {
+				t.Errorf("DutchPriceAt() next drop = %v, want %v", nextDropAt, tt.wantNextAt)
+			}
+		})
+	}
+}
+
+func TestItemDutchPriceAtExactFloor(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	floor := Money(400000)
+	drop := Money(300000)
+	interval := 30
+
+	item := Item{AuctionType: AuctionTypeDutch, BidAmount: 1000000, FloorPrice: &floor, PriceDrop: &drop, PriceDropInterval: &interval, StartsAt: &start}
+
+	// The second drop lands on the floor exactly, there is nothing left to drop to
+	price, nextDropAt := item.DutchPriceAt(start.Add(time.Minute))
+
+	if price != floor || nextDropAt != nil {
+		t.Errorf("DutchPriceAt() = %s, %v, want %s, nil", price, nextDropAt, floor)
+	}
+
+	price, nextDropAt = item.DutchPriceAt(start.Add(59 * time.Second))
+
+	if price != 700000 || nextDropAt == nil || !nextDropAt.Equal(start.Add(time.Minute)) {
+		t.Errorf("DutchPriceAt() = %s, %v, want 70.00 dropping at %v", price, nextDropAt, start.Add(time.Minute))
+	}
+}
+
+func TestItemDutchPriceAtWithoutSchedule(t *testing.T) {
+	item := Item{AuctionType: AuctionTypeDutch, BidAmount: 1000000}
+
+	price, nextDropAt := item.DutchPriceAt(time.Now())
+
+	if price != 1000000 || nextDropAt != nil {
+		t.Errorf("DutchPriceAt() = %s, %v, want the start price and no drop", price, nextDropAt)
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// A reverse auction owner handing the contract to a bid, kept as an audit trail
+type Award struct {
+	ID            int64     `json:"id"`
+	ItemID        int64     `json:"item_id"`
+	BidID         int64     `json:"bid_id"`
+	PreviousBidID *int64    `json:"previous_bid_id,omitempty"`
+	AwardedBy     int64     `json:"awarded_by"`
+	Reason        *string   `json:"reason,omitempty"`
+	CreatedAt     time.Time `json:"created_at,omitzero"`
+}
+
+// Awarding the contract to a bid other than the lowest needs a reason
+type AwardRequest struct {
+	BidID  int64   `json:"bid_id" validate:"required,min=1"`
+	Reason *string `json:"reason,omitempty" validate:"omitempty,min=3,max=1000"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+type BidKind string
+
+const (
+	BidKindBid    BidKind = "bid"
+	BidKindProxy  BidKind = "proxy"
+	BidKindBuyNow BidKind = "buy_now"
+	// A bidder accepting the current price of a dutch auction
+	BidKindAccept BidKind = "accept"
+	// The single hidden bid a bidder holds in a sealed auction
+	BidKindSealed BidKind = "sealed"
+)
+
+type Bid struct {
+	ID             int64   `json:"id,omitempty"`
+	ItemID         int64   `json:"item_id,omitempty"`
+	BidderID       int64   `json:"bidder_id,omitempty"`
+	BidderUsername string  `json:"bidder_username,omitempty"`
+	Amount         Money   `json:"amount,omitempty" validate:"required,money_min=1"`
+	Kind           BidKind `json:"kind,omitempty"`
+	// Number of units the bid is for, Amount is the price of each unit
+	Quantity  int       `json:"quantity,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+}
+
+type BidPage struct {
+	Bids  []Bid `json:"bids"`
+	Page  int   `json:"page"`
+	Limit int   `json:"limit"`
+	Total int64 `json:"total"`
+}
+
+// A bid request, setting MaxAmount places a proxy bid that the system raises on the bidder's behalf
+type PlaceBidRequest struct {
+	Amount    Money  `json:"amount,omitempty" validate:"required_without=MaxAmount,omitempty,money_min=1"`
+	MaxAmount *Money `json:"max_amount,omitempty" validate:"omitempty,money_min=1"`
+	// Units wanted in a multi-unit auction, at Amount each
+	Quantity int `json:"quantity,omitempty" validate:"omitempty,min=1"`
+	// Optional, when given it must match the item's currency so a bid is never silently reinterpreted
+	Currency string `json:"currency,omitempty" validate:"omitempty,iso4217"`
+}
+
+// The outcome of a bid request as seen by the bidder who placed it.
+// MaxAmount is the bidder's own proxy maximum and is never included anywhere else.
+type BidPlacement struct {
+	Bid        Bid    `json:"bid"`
+	Leading    bool   `json:"leading"`
+	CurrentBid Money  `json:"current_bid"`
+	MaxAmount  *Money `json:"max_amount,omitempty"`
+	Currency   string `json:"currency"`
+	// Set for sealed auctions, where Leading and CurrentBid stay unknown until the close
+	Sealed bool `json:"sealed,omitempty"`
+}
+
+// The highest amount the system may bid on a bidder's behalf, kept out of every public response
+type ProxyBid struct {
+	ID        int64
+	ItemID    int64
+	BidderID  int64
+	MaxAmount Money
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
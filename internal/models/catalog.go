@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// A catalog runs lots as one sale. Every lot opens at StartsAt and the lots close one after another,
+// the first at FirstLotEndsAt and each following lot StaggerInterval seconds after the one before it.
+type Catalog struct {
+	ID              int64     `json:"id"`
+	Name            string    `json:"name"`
+	SellerID        int64     `json:"seller_id"`
+	StartsAt        time.Time `json:"starts_at"`
+	FirstLotEndsAt  time.Time `json:"first_lot_ends_at"`
+	StaggerInterval int       `json:"stagger_interval"`
+	Lots            []Lot     `json:"lots,omitempty"`
+	CreatedAt       time.Time `json:"created_at,omitzero"`
+}
+
+// A new catalog, the lots close in the order given
+type CreateCatalogRequest struct {
+	Name            string     `json:"name" validate:"required,min=3,max=100"`
+	StartsAt        *time.Time `json:"starts_at" validate:"required"`
+	FirstLotEndsAt  *time.Time `json:"first_lot_ends_at" validate:"required,gtfield=StartsAt"`
+	StaggerInterval int        `json:"stagger_interval" validate:"required,min=1,max=86400"`
+	LotIDs          []int64    `json:"lot_ids" validate:"required,min=1,max=1000,unique,dive,min=1"`
+}
+
+// Replaces the lots of a catalog and their closing order
+type CatalogLotsUpdate struct {
+	LotIDs []int64 `json:"lot_ids" validate:"required,min=1,max=1000,unique,dive,min=1"`
+}
+
+// Return the scheduled end time of the lot at a zero based position in the catalog
+func (c Catalog) LotEndsAt(position int) time.Time {
+	return c.FirstLotEndsAt.Add(time.Duration(position) * time.Duration(c.StaggerInterval) * time.Second)
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidExchangeRates = errors.New("exchange rates need a base currency and a positive rate for every other currency")
+	ErrUnknownCurrency      = errors.New("no exchange rate for currency")
+)
+
+// Rate of a currency against the base currency, as units of the currency per unit of the base
+type ExchangeRate struct {
+	Currency  string    `json:"currency"`
+	Rate      string    `json:"rate"`
+	IsBase    bool      `json:"is_base"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+}
+
+// A full set of rates as loaded from the rates file or sent by an admin,
+// for example {"base": "USD", "rates": {"EUR": "0.92", "PHP": 56.1}}
+type ExchangeRates struct {
+	Base  string                 `json:"base" validate:"required,iso4217"`
+	Rates map[string]json.Number `json:"rates" validate:"required,dive,keys,iso4217,endkeys,required"`
+}
+
+// Check that every rate is a positive decimal and that the base currency, if listed, has a rate of 1
+func (r ExchangeRates) Validate() error {
+	if !isCurrencyCode(r.Base) {
+		return ErrInvalidExchangeRates
+	}
+
+	for currency, rate := range r.Rates {
+		if !isCurrencyCode(currency) {
+			return ErrInvalidExchangeRates
+		}
+
+		value, ok := new(big.Rat).SetString(rate.String())
+
+		if !ok || value.Sign() <= 0 {
+			return ErrInvalidExchangeRates
+		}
+
+		if currency == r.Base && value.Cmp(big.NewRat(1, 1)) != 0 {
+			return ErrInvalidExchangeRates
+		}
+	}
+
+	return nil
+}
+
+// Check that a currency looks like an ISO 4217 code such as USD
+func isCurrencyCode(s string) bool {
+	return len(s) == 3 && strings.Trim(s, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") == ""
+}
+
+// Amounts of an item converted to the currency a viewer asked for.
+// They are for display only, bids are always placed and compared in the item's own currency.
+type ConvertedAmounts struct {
+	Currency     string `json:"currency"`
+	Rate         string `json:"rate"`
+	BidAmount    Money  `json:"bid_amount"`
+	ReservePrice *Money `json:"reserve_price,omitempty"`
+	BuyNowPrice  *Money `json:"buy_now_price,omitempty"`
+	CurrentPrice *Money `json:"current_price,omitempty"`
+}
+
+// Convert an amount at the given rate, rounding half away from zero to the nearest minor unit of the target currency
+func (m Money) Convert(rate *big.Rat, currency string) Money {
+	size := minorUnitSize(currency)
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(m)), rate)
+	value.Quo(value, new(big.Rat).SetInt64(size))
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(value.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(value.Sign())))
+	}
+
+	return Money(quotient.Int64() * size)
+}
+
+// Format a rate as a plain decimal without trailing zeros
+func FormatRate(rate *big.Rat) string {
+	s := rate.FloatString(10)
+
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+
+	return s
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidIncrementTable = errors.New("increment table must start at 0 with increasing tiers and positive steps")
+
+// A bid increment tier, Step applies to every amount from From up to the next tier
+type IncrementTier struct {
+	From Money `json:"from" validate:"money_min=0"`
+	Step Money `json:"step" validate:"money_gt=0"`
+}
+
+type IncrementTable []IncrementTier
+
+type CategoryIncrements struct {
+	Category string         `json:"category"`
+	Tiers    IncrementTable `json:"tiers" validate:"required,min=1,dive"`
+}
+
+type IncrementTables struct {
+	Default    IncrementTable            `json:"default"`
+	Categories map[string]IncrementTable `json:"categories"`
+}
+
+// Parse a table written as from:step pairs, for example 0:1,100:5,1000:10
+func ParseIncrementTable(s string) (IncrementTable, error) {
+	var table IncrementTable
+
+	for _, pair := range strings.Split(s, ",") {
+		from, step, ok := strings.Cut(strings.TrimSpace(pair), ":")
+
+		if !ok {
+			return nil, fmt.Errorf("%w: %q is not a from:step pair", ErrInvalidIncrementTable, pair)
+		}
+
+		fromValue, err := ParseMoney(from)
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidIncrementTable, err)
+		}
+
+		stepValue, err := ParseMoney(step)
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidIncrementTable, err)
+		}
+
+		table = append(table, IncrementTier{From: fromValue, Step: stepValue})
+	}
+
+	sort.Slice(table, func(i, j int) bool { return table[i].From < table[j].From })
+
+	return table, table.Validate()
+}
+
+// Check that the table covers every amount from 0 and that each tier has a usable step
+func (t IncrementTable) Validate() error {
+	if len(t) == 0 || t[0].From != 0 {
+		return ErrInvalidIncrementTable
+	}
+
+	for i, tier := range t {
+		if tier.Step <= 0 || (i > 0 && tier.From <= t[i-1].From) {
+			return ErrInvalidIncrementTable
+		}
+	}
+
+	return nil
+}
+
+// Return the step that applies to an amount
+func (t IncrementTable) StepFor(amount Money) Money {
+	step := Money(0)
+
+	for _, tier := range t {
+		if amount < tier.From {
+			break
+		}
+		step = tier.Step
+	}
+
+	return step
+}
+
+// Return a copy of the table with every step rounded up to a whole minor unit of the currency,
+// so that amounts raised by a step can still be paid in that currency
+func (t IncrementTable) InCurrency(currency string) IncrementTable {
+	rounded := make(IncrementTable, len(t))
+
+	for i, tier := range t {
+		step := tier.Step.RoundDown(currency)
+
+		if step < tier.Step {
+			step += MoneyFromMinorUnits(1, currency)
+		}
+
+		rounded[i] = IncrementTier{From: tier.From, Step: step}
+	}
+
+	return rounded
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseIncrementTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    IncrementTable
+		wantErr bool
+	}{
+		{"single tier", "0:1", IncrementTable{{From: 0, Step: 10000}}, false},
+		{"sorted by from", "100:5, 0:1", IncrementTable{{From: 0, Step: 10000}, {From: 1000000, Step: 50000}}, false},
+		{"decimal steps", "0:0.25,10:0.5", IncrementTable{{From: 0, Step: 2500}, {From: 100000, Step: 5000}}, false},
+		{"missing step", "0", nil, true},
+		{"not starting at zero", "10:1", nil, true},
+		{"zero step", "0:0", nil, true},
+		{"duplicate from", "0:1,0:2", nil, true},
+		{"invalid amount", "0:abc", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseIncrementTable(tt.input)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidIncrementTable) {
+					t.Fatalf("ParseIncrementTable(%q) error = %v, want ErrInvalidIncrementTable", tt.input, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseIncrementTable(%q) error = %v", tt.input, err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseIncrementTable(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("tier %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIncrementTableStepFor(t *testing.T) {
+	table, err := ParseIncrementTable("0:1,100:5,1000:10")
+
+	if err != nil {
+		t.Fatalf("ParseIncrementTable: %v", err)
+	}
+
+	tests := []struct {
+		amount Money
+		want   Money
+	}{
+		{0, 10000},
+		{999999, 10000},
+		{1000000, 50000},
+		{9999999, 50000},
+		{10000000, 100000},
+		{500000000, 100000},
+	}
+
+	for _, tt := range tests {
+		if got := table.StepFor(tt.amount); got != tt.want {
+			t.Errorf("StepFor(%s) = %s, want %s", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestIncrementTableInCurrency(t *testing.T) {
+	table := IncrementTable{{From: 0, Step: 2500}, {From: 1000000, Step: 12345}}
+
+	tests := []struct {
+		currency string
+		want     IncrementTable
+	}{
+		{"USD", IncrementTable{{From: 0, Step: 2500}, {From: 1000000, Step: 12400}}},
+		{"JPY", IncrementTable{{From: 0, Step: 10000}, {From: 1000000, Step: 20000}}},
+		{"BHD", IncrementTable{{From: 0, Step: 2500}, {From: 1000000, Step: 12350}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			got := table.InCurrency(tt.currency)
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("tier %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+
+			if table[1].Step != 12345 {
+				t.Errorf("InCurrency changed the original table")
+			}
+		})
+	}
+}
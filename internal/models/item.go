@@ -1,8 +1,70 @@
 package models
 
+import "time"
+
+type ItemStatus string
+
+const (
+	ItemStatusDraft     ItemStatus = "draft"
+	ItemStatusScheduled ItemStatus = "scheduled"
+	ItemStatusLive      ItemStatus = "live"
+	ItemStatusEnded     ItemStatus = "ended"
+	ItemStatusCancelled ItemStatus = "cancelled"
+	ItemStatusSold      ItemStatus = "sold"
+	ItemStatusUnsold    ItemStatus = "unsold"
+)
+
 type Item struct {
-	ID          int64   `json:"id,omitempty"`
-	ItemName    string  `json:"item_name,omitempty" validate:"required,min=3,max=100"`
-	BidAmount   float64 `json:"bid_amount,omitempty" validate:"required,numeric,min=1"`
-	AuctionedBy int64   `json:"auctioned_by,omitempty"`
+	ID          int64      `json:"id,omitempty"`
+	ItemName    string     `json:"item_name,omitempty" validate:"required,min=3,max=100"`
+	BidAmount   Money      `json:"bid_amount,omitempty" validate:"required,money_min=1"`
+	BidCount    int        `json:"bid_count"`
+	AuctionedBy int64      `json:"auctioned_by,omitempty"`
+	Status      ItemStatus `json:"status,omitempty"`
+	Category    *string    `json:"category,omitempty" validate:"omitempty,min=1,max=100"`
+	// Every amount of the item, its bids included, is in this currency, taken from the seller
+	Currency    string      `json:"currency,omitempty"`
+	AuctionType AuctionType `json:"auction_type,omitempty" validate:"omitempty,oneof=english dutch sealed_first_price vickrey reverse"`
+	// Identical units for sale, BidAmount and every bid are per unit when there is more than one
+	Quantity    int         `json:"quantity" validate:"omitempty,min=1,max=1000000"`
+	PricingRule PricingRule `json:"pricing_rule,omitempty" validate:"omitempty,oneof=uniform pay_as_bid"`
+
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	EndsAt   *time.Time `json:"ends_at,omitempty" validate:"omitempty,gtfield=StartsAt"`
+	// End time the seller chose, EndsAt moves past it when late bids extend the auction
+	OriginalEndsAt *time.Time `json:"original_ends_at,omitempty"`
+	ExtensionCount int        `json:"extension_count"`
+
+	// Only ever shown to the seller, bidders only learn whether it has been met
+	ReservePrice *Money `json:"reserve_price,omitempty" validate:"omitempty,money_gt=0"`
+	HasReserve   bool   `json:"has_reserve"`
+	ReserveMet   bool   `json:"reserve_met"`
+	BuyNowPrice  *Money `json:"buy_now_price,omitempty" validate:"omitempty,money_gt=0"`
+	// Overrides the category and default increment tables for this auction only
+	IncrementTable IncrementTable `json:"increment_table,omitempty" validate:"omitempty,dive"`
+
+	// Dutch auctions start at BidAmount and drop by PriceDrop every PriceDropInterval seconds down to FloorPrice
+	FloorPrice        *Money `json:"floor_price,omitempty" validate:"omitempty,money_gt=0"`
+	PriceDrop         *Money `json:"price_drop,omitempty" validate:"omitempty,money_gt=0"`
+	PriceDropInterval *int   `json:"price_drop_interval,omitempty" validate:"omitempty,min=1"`
+	// The price a dutch auction can be accepted at right now, and when it drops next
+	CurrentPrice    *Money     `json:"current_price,omitempty"`
+	NextPriceDropAt *time.Time `json:"next_price_drop_at,omitempty"`
+
+	// The lot the item is sold in and its place in the lot, bidding happens on the lot instead
+	LotID       *int64 `json:"lot_id,omitempty"`
+	LotPosition *int   `json:"lot_position,omitempty"`
+
+	// Amounts converted to the currency the viewer asked for, for display only
+	Converted *ConvertedAmounts `json:"converted,omitempty"`
+
+	WinnerID *int64 `json:"winner_id,omitempty"`
+	// What the winner pays, in a vickrey auction the second highest bid rather than their own
+	HammerPrice  *Money     `json:"hammer_price,omitempty"`
+	WinningBidID *int64     `json:"winning_bid_id,omitempty"`
+	ClosedAt     *time.Time `json:"closed_at,omitempty"`
+}
+
+type ItemStatusUpdate struct {
+	Status ItemStatus `json:"status" validate:"required,oneof=draft scheduled live ended cancelled sold unsold"`
 }
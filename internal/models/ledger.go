@@ -0,0 +1,142 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrUnbalancedEntry = errors.New("journal entry debits must equal its credits")
+
+// Every account is credit normal, its balance is its credits less its debits.
+// The funding account therefore goes negative by the money paid into the platform.
+type AccountKind string
+
+const (
+	// Money a user can bid or withdraw
+	AccountKindAvailable AccountKind = "available"
+	// Money set aside for a user's bids
+	AccountKindHeld AccountKind = "held"
+	// Money outside the platform, deposits come from it and withdrawals go back to it
+	AccountKindFunding AccountKind = "funding"
+)
+
+type LedgerAccount struct {
+	ID        int64       `json:"id"`
+	UserID    *int64      `json:"user_id,omitempty"`
+	Kind      AccountKind `json:"kind"`
+	Currency  string      `json:"currency"`
+	Balance   Money       `json:"balance"`
+	CreatedAt time.Time   `json:"created_at,omitzero"`
+}
+
+type JournalEntryKind string
+
+const (
+	JournalEntryDeposit    JournalEntryKind = "deposit"
+	JournalEntryWithdrawal JournalEntryKind = "withdrawal"
+	JournalEntryHold       JournalEntryKind = "hold"
+	JournalEntryRelease    JournalEntryKind = "release"
+	JournalEntryCapture    JournalEntryKind = "capture"
+	JournalEntryRefund     JournalEntryKind = "refund"
+)
+
+// One side of a journal entry, exactly one of Debit and Credit is set
+type JournalLine struct {
+	ID        int64 `json:"id,omitempty"`
+	EntryID   int64 `json:"entry_id,omitempty"`
+	AccountID int64 `json:"account_id"`
+	Debit     Money `json:"debit"`
+	Credit    Money `json:"credit"`
+}
+
+type JournalEntry struct {
+	ID          int64            `json:"id,omitempty"`
+	Kind        JournalEntryKind `json:"kind"`
+	ItemID      *int64           `json:"item_id,omitempty"`
+	Description string           `json:"description"`
+	Lines       []JournalLine    `json:"lines"`
+	CreatedAt   time.Time        `json:"created_at,omitzero"`
+}
+
+// Check that every line moves a positive amount on one side and that the entry balances
+func (e JournalEntry) Validate() error {
+	var debits, credits Money
+
+	if len(e.Lines) < 2 {
+		return ErrUnbalancedEntry
+	}
+
+	for _, line := range e.Lines {
+		if line.Debit < 0 || line.Credit < 0 || (line.Debit == 0) == (line.Credit == 0) {
+			return ErrUnbalancedEntry
+		}
+
+		debits += line.Debit
+		credits += line.Credit
+	}
+
+	if debits != credits {
+		return ErrUnbalancedEntry
+	}
+
+	return nil
+}
+
+// Move amount from one account to another, debiting the first and crediting the second
+func Transfer(kind JournalEntryKind, itemID *int64, description string, from, to int64, amount Money) JournalEntry {
+	return JournalEntry{
+		Kind:        kind,
+		ItemID:      itemID,
+		Description: description,
+		Lines:       []JournalLine{{AccountID: from, Debit: amount}, {AccountID: to, Credit: amount}},
+	}
+}
+
+type HoldStatus string
+
+const (
+	HoldStatusActive   HoldStatus = "active"
+	HoldStatusReleased HoldStatus = "released"
+	HoldStatusCaptured HoldStatus = "captured"
+)
+
+// Funds set aside for a user's bids on an item, a user has at most one active hold per item
+type Hold struct {
+	ID        int64      `json:"id,omitempty"`
+	UserID    int64      `json:"user_id"`
+	ItemID    int64      `json:"item_id"`
+	Currency  string     `json:"currency"`
+	Amount    Money      `json:"amount"`
+	Status    HoldStatus `json:"status"`
+	CreatedAt time.Time  `json:"created_at,omitzero"`
+	UpdatedAt time.Time  `json:"updated_at,omitzero"`
+}
+
+// A user's balances in one currency
+type Wallet struct {
+	Currency  string `json:"currency"`
+	Available Money  `json:"available"`
+	Held      Money  `json:"held"`
+}
+
+type DepositRequest struct {
+	UserID   int64  `json:"user_id" validate:"required"`
+	Amount   Money  `json:"amount" validate:"required,money_gt=0"`
+	Currency string `json:"currency" validate:"required,iso4217"`
+}
+
+type WithdrawalRequest struct {
+	Amount   Money  `json:"amount" validate:"required,money_gt=0"`
+	Currency string `json:"currency" validate:"required,iso4217"`
+}
+
+// Result of checking the ledger's invariants
+type LedgerCheck struct {
+	Balanced     bool  `json:"balanced"`
+	TotalDebits  Money `json:"total_debits"`
+	TotalCredits Money `json:"total_credits"`
+	// Entries whose debits and credits differ
+	UnbalancedEntries []int64 `json:"unbalanced_entries"`
+	// Held accounts whose balance differs from the sum of their user's active holds
+	MismatchedHeldAccounts []int64 `json:"mismatched_held_accounts"`
+}
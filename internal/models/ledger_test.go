@@ -0,0 +1,45 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTransfer(t *testing.T) {
+	itemID := int64(7)
+	entry := Transfer(JournalEntryHold, &itemID, "Hold for bids on item 7", 1, 2, 125000)
+
+	if err := entry.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+
+	if len(entry.Lines) != 2 || entry.Lines[0] != (JournalLine{AccountID: 1, Debit: 125000}) || entry.Lines[1] != (JournalLine{AccountID: 2, Credit: 125000}) {
+		t.Errorf("Transfer() lines = %v", entry.Lines)
+	}
+}
+
+func TestJournalEntryValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []JournalLine
+		wantErr bool
+	}{
+		{"balanced", []JournalLine{{AccountID: 1, Debit: 100}, {AccountID: 2, Credit: 100}}, false},
+		{"split credit", []JournalLine{{AccountID: 1, Debit: 100}, {AccountID: 2, Credit: 60}, {AccountID: 3, Credit: 40}}, false},
+		{"unbalanced", []JournalLine{{AccountID: 1, Debit: 100}, {AccountID: 2, Credit: 90}}, true},
+		{"single line", []JournalLine{{AccountID: 1, Debit: 100}}, true},
+		{"zero amount", []JournalLine{{AccountID: 1}, {AccountID: 2}}, true},
+		{"both sides on one line", []JournalLine{{AccountID: 1, Debit: 100, Credit: 100}, {AccountID: 2, Debit: 100, Credit: 100}}, true},
+		{"negative amount", []JournalLine{{AccountID: 1, Debit: -100}, {AccountID: 2, Credit: -100}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := JournalEntry{Kind: JournalEntryCapture, Lines: tt.lines}.Validate()
+
+			if tt.wantErr != errors.Is(err, ErrUnbalancedEntry) || (!tt.wantErr && err != nil) {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
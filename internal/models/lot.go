@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// A lot sells several items together as one auction.
+// Bids and timing live on the lot's own auction item, the grouped items only link back to the lot.
+type Lot struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	SellerID int64  `json:"seller_id"`
+	ItemID   int64  `json:"item_id"`
+	// The catalog the lot closes in and its place in the closing order
+	CatalogID       *int64    `json:"catalog_id,omitempty"`
+	CatalogPosition *int      `json:"catalog_position,omitempty"`
+	Auction         *Item     `json:"auction,omitempty"`
+	Items           []Item    `json:"items"`
+	CreatedAt       time.Time `json:"created_at,omitzero"`
+}
+
+type LotPage struct {
+	Lots  []Lot `json:"lots"`
+	Page  int   `json:"page"`
+	Limit int   `json:"limit"`
+	Total int64 `json:"total"`
+}
+
+// A new lot, the items are grouped in the order given
+type CreateLotRequest struct {
+	Name         string     `json:"name" validate:"required,min=3,max=100"`
+	BidAmount    Money      `json:"bid_amount" validate:"required,money_min=1"`
+	StartsAt     *time.Time `json:"starts_at,omitempty"`
+	EndsAt       *time.Time `json:"ends_at,omitempty" validate:"omitempty,gtfield=StartsAt"`
+	ReservePrice *Money     `json:"reserve_price,omitempty" validate:"omitempty,money_gt=0"`
+	Category     *string    `json:"category,omitempty" validate:"omitempty,min=1,max=100"`
+	ItemIDs      []int64    `json:"item_ids" validate:"required,min=1,max=500,unique,dive,min=1"`
+}
+
+// Replaces the items of a lot and their order
+type LotItemsUpdate struct {
+	ItemIDs []int64 `json:"item_ids" validate:"required,min=1,max=500,unique,dive,min=1"`
+}
@@ -0,0 +1,240 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Number of decimal places every amount is kept to internally.
+// It is fine enough for the minor unit of every ISO 4217 currency, how many of them a currency
+// actually uses is given by CurrencyExponent.
+const MoneyScale = 4
+
+// Currency used when a user does not pick one
+const DefaultCurrency = "USD"
+
+const scaleFactor = 10000
+
+var (
+	ErrInvalidMoney            = errors.New("amount must be a decimal number with at most 4 decimal places")
+	ErrInvalidMoneyForCurrency = errors.New("amount has more decimal places than its currency allows")
+)
+
+// ISO 4217 currencies whose minor unit is not a hundredth of the major unit
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// Money is an exact amount in ten-thousandths of a currency unit.
+// It is stored as a bigint and written to JSON as a plain decimal number such as 12.50,
+// so no amount ever passes through a float.
+type Money int64
+
+// Number of decimal places of the currency's minor unit, for example 2 for USD and 0 for JPY
+func CurrencyExponent(currency string) int {
+	if exponent, ok := currencyExponents[currency]; ok {
+		return exponent
+	}
+
+	return 2
+}
+
+// Internal units in one minor unit of the currency
+func minorUnitSize(currency string) int64 {
+	return decimalSize(CurrencyExponent(currency))
+}
+
+// Internal units in the smallest step of an amount written with the given decimal places
+func decimalSize(decimals int) int64 {
+	size := int64(1)
+
+	for range MoneyScale - decimals {
+		size *= 10
+	}
+
+	return size
+}
+
+// Parse a decimal amount and check that it has no more decimal places than the currency allows
+func ParseMoneyIn(s string, currency string) (Money, error) {
+	amount, err := ParseMoney(s)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if !amount.FitsCurrency(currency) {
+		return 0, ErrInvalidMoneyForCurrency
+	}
+
+	return amount, nil
+}
+
+// Build an amount from a count of the currency's minor units, for example cents or yen
+func MoneyFromMinorUnits(units int64, currency string) Money {
+	return Money(units * minorUnitSize(currency))
+}
+
+// Parse a decimal amount such as 12, 12.5 or -0.25
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, fraction, _ := strings.Cut(s, ".")
+
+	if whole == "" || len(fraction) > MoneyScale || strings.ContainsAny(whole+fraction, "+-eE") {
+		return 0, ErrInvalidMoney
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+
+	if err != nil {
+		return 0, ErrInvalidMoney
+	}
+
+	minor := int64(0)
+
+	if fraction != "" {
+		minor, err = strconv.ParseInt(fraction+strings.Repeat("0", MoneyScale-len(fraction)), 10, 64)
+
+		if err != nil {
+			return 0, ErrInvalidMoney
+		}
+	}
+
+	if units > (1<<63-1-minor)/scaleFactor {
+		return 0, ErrInvalidMoney
+	}
+
+	amount := Money(units*scaleFactor + minor)
+
+	if negative {
+		amount = -amount
+	}
+
+	return amount, nil
+}
+
+// Format the amount with two decimal places, or up to four when it needs them
+func (m Money) String() string {
+	s := m.format(MoneyScale)
+	whole, fraction, _ := strings.Cut(s, ".")
+
+	return whole + "." + fraction[:2] + strings.TrimRight(fraction[2:], "0")
+}
+
+// Format the amount with exactly the decimal places of the currency, such as 1500 for JPY or 1.250 for BHD.
+// Digits the currency has no room for are dropped.
+func (m Money) Format(currency string) string {
+	return m.format(CurrencyExponent(currency))
+}
+
+func (m Money) format(decimals int) string {
+	sign := ""
+	value := int64(m)
+
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+
+	value -= value % decimalSize(decimals)
+
+	if value == 0 {
+		sign = ""
+	}
+
+	units := fmt.Sprintf("%s%d", sign, value/scaleFactor)
+
+	if decimals == 0 {
+		return units
+	}
+
+	fraction := fmt.Sprintf("%0*d", MoneyScale, value%scaleFactor)
+
+	return units + "." + fraction[:decimals]
+}
+
+// Report whether the amount is a whole number of the currency's minor units
+func (m Money) FitsCurrency(currency string) bool {
+	return int64(m)%minorUnitSize(currency) == 0
+}
+
+// Return the amount as a count of the currency's minor units, as payment providers expect it
+func (m Money) MinorUnits(currency string) int64 {
+	return int64(m) / minorUnitSize(currency)
+}
+
+// Round the amount down to a whole number of the currency's minor units
+func (m Money) RoundDown(currency string) Money {
+	size := minorUnitSize(currency)
+	value := int64(m)
+
+	if remainder := value % size; remainder < 0 {
+		value -= size + remainder
+	} else {
+		value -= remainder
+	}
+
+	return Money(value)
+}
+
+// Return the share of the amount given in basis points, rounded down to the currency's minor unit
+func (m Money) MulBasisPoints(bps int64, currency string) Money {
+	return Money(int64(m) * bps / 10000).RoundDown(currency)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// Accept both a JSON number and a JSON string holding a decimal amount
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := string(data)
+
+	if s == "null" {
+		return nil
+	}
+
+	unquoted, err := strconv.Unquote(s)
+
+	if err == nil {
+		s = unquoted
+	}
+
+	amount, err := ParseMoney(s)
+
+	if err != nil {
+		return err
+	}
+
+	*m = amount
+
+	return nil
+}
+
+// Store the amount in ten-thousandths
+func (m Money) Value() (driver.Value, error) {
+	return int64(m), nil
+}
+
+// Read an amount stored in ten-thousandths
+func (m *Money) Scan(src any) error {
+	switch value := src.(type) {
+	case int64:
+		*m = Money(value)
+	case int32:
+		*m = Money(value)
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{"12", 120000, false},
+		{"12.5", 125000, false},
+		{" 0.0001 ", 1, false},
+		{"-0.25", -2500, false},
+		{"1.23456", 0, true},
+		{"", 0, true},
+		{".5", 0, true},
+		{"1e3", 0, true},
+		{"+1", 0, true},
+		{"1.-5", 0, true},
+		{"abc", 0, true},
+		{"922337203685477.5808", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseMoney(tt.input)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidMoney) {
+					t.Fatalf("ParseMoney(%q) error = %v, want ErrInvalidMoney", tt.input, err)
+				}
+				return
+			}
+
+			if err != nil || got != tt.want {
+				t.Fatalf("ParseMoney(%q) = %d, %v, want %d", tt.input, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMoneyIn(t *testing.T) {
+	tests := []struct {
+		input    string
+		currency string
+		want     Money
+		wantErr  error
+	}{
+		{"12.34", "USD", 123400, nil},
+		{"12.345", "USD", 0, ErrInvalidMoneyForCurrency},
+		{"1500", "JPY", 15000000, nil},
+		{"1500.5", "JPY", 0, ErrInvalidMoneyForCurrency},
+		{"1.125", "BHD", 11250, nil},
+		{"1.1255", "BHD", 0, ErrInvalidMoneyForCurrency},
+		{"1.1255", "CLF", 11255, nil},
+		{"x", "USD", 0, ErrInvalidMoney},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency+" "+tt.input, func(t *testing.T) {
+			got, err := ParseMoneyIn(tt.input, tt.currency)
+
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Fatalf("ParseMoneyIn(%q, %s) = %d, %v, want %d, %v", tt.input, tt.currency, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		amount Money
+		want   string
+	}{
+		{0, "0.00"},
+		{125000, "12.50"},
+		{123450, "12.345"},
+		{1, "0.0001"},
+		{-2500, "-0.25"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.String(); got != tt.want {
+			t.Errorf("Money(%d).String() = %q, want %q", int64(tt.amount), got, tt.want)
+		}
+	}
+}
+
+func TestMoneyFormat(t *testing.T) {
+	tests := []struct {
+		amount   Money
+		currency string
+		want     string
+	}{
+		{125000, "USD", "12.50"},
+		{123456, "USD", "12.34"},
+		{15000000, "JPY", "1500"},
+		{11250, "BHD", "1.125"},
+		{11255, "CLF", "1.1255"},
+		{-2500, "USD", "-0.25"},
+		{-50, "USD", "0.00"},
+		{-5000, "JPY", "0"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.Format(tt.currency); got != tt.want {
+			t.Errorf("Money(%d).Format(%s) = %q, want %q", int64(tt.amount), tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyMinorUnits(t *testing.T) {
+	tests := []struct {
+		currency string
+		units    int64
+		amount   Money
+	}{
+		{"USD", 1250, 125000},
+		{"JPY", 1500, 15000000},
+		{"BHD", 1125, 11250},
+		{"CLF", 11255, 11255},
+	}
+
+	for _, tt := range tests {
+		if got := MoneyFromMinorUnits(tt.units, tt.currency); got != tt.amount {
+			t.Errorf("MoneyFromMinorUnits(%d, %s) = %d, want %d", tt.units, tt.currency, got, tt.amount)
+		}
+
+		if got := tt.amount.MinorUnits(tt.currency); got != tt.units {
+			t.Errorf("Money(%d).MinorUnits(%s) = %d, want %d", int64(tt.amount), tt.currency, got, tt.units)
+		}
+	}
+}
+
+func TestMoneyRoundDown(t *testing.T) {
+	tests := []struct {
+		amount   Money
+		currency string
+		want     Money
+	}{
+		{123456, "USD", 123400},
+		{123400, "USD", 123400},
+		{-123456, "USD", -123500},
+		{15009999, "JPY", 15000000},
+		{11259, "BHD", 11250},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.RoundDown(tt.currency); got != tt.want {
+			t.Errorf("Money(%d).RoundDown(%s) = %d, want %d", int64(tt.amount), tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyMulBasisPoints(t *testing.T) {
+	tests := []struct {
+		amount   Money
+		bps      int64
+		currency string
+		want     Money
+	}{
+		{1000000, 1000, "USD", 100000},
+		{9999, 1250, "USD", 1200},
+		{15000000, 333, "JPY", 490000},
+		{0, 1000, "USD", 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.MulBasisPoints(tt.bps, tt.currency); got != tt.want {
+			t.Errorf("Money(%d).MulBasisPoints(%d, %s) = %d, want %d", int64(tt.amount), tt.bps, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{`12.5`, 125000, false},
+		{`"12.5"`, 125000, false},
+		{`null`, 0, false},
+		{`"12.34567"`, 0, true},
+		{`true`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var got Money
+
+			err := json.Unmarshal([]byte(tt.input), &got)
+
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Fatalf("Unmarshal(%s) = %d, %v, want %d", tt.input, got, err, tt.want)
+			}
+		})
+	}
+
+	data, err := json.Marshal(struct {
+		Amount Money `json:"amount"`
+	}{125000})
+
+	if err != nil || string(data) != `{"amount":12.50}` {
+		t.Fatalf("Marshal = %s, %v", data, err)
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+type NotificationKind string
+
+const (
+	NotificationOutbid        NotificationKind = "outbid"
+	NotificationWon           NotificationKind = "won"
+	NotificationLost          NotificationKind = "lost"
+	NotificationReserveNotMet NotificationKind = "reserve_not_met"
+	NotificationEndingSoon    NotificationKind = "ending_soon"
+)
+
+// Every kind of notification, in the order preferences are listed
+var NotificationKinds = []NotificationKind{NotificationOutbid, NotificationWon, NotificationLost, NotificationReserveNotMet, NotificationEndingSoon}
+
+type Notification struct {
+	ID      int64            `json:"id"`
+	UserID  int64            `json:"user_id"`
+	Kind    NotificationKind `json:"kind"`
+	ItemID  int64            `json:"item_id"`
+	Message string           `json:"message"`
+	// Identifies the event the notification was made for, so it is only stored once
+	DedupeKey string     `json:"-"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at,omitzero"`
+}
+
+type NotificationPage struct {
+	Notifications []Notification `json:"notifications"`
+	Page          int            `json:"page"`
+	Limit         int            `json:"limit"`
+	Unread        int64          `json:"unread"`
+}
+
+type UnreadCount struct {
+	Unread int64 `json:"unread"`
+}
+
+// Whether a user receives one kind of notification
+type NotificationPreference struct {
+	Kind    NotificationKind `json:"kind" validate:"required,oneof=outbid won lost reserve_not_met ending_soon"`
+	Enabled bool             `json:"enabled"`
+}
+
+type NotificationPreferencesUpdate struct {
+	Preferences []NotificationPreference `json:"preferences" validate:"required,min=1,dive"`
+}
+
+// A bidder who lost the lead on an item, or their place among the winners of a multi-unit auction
+type Outbid struct {
+	ItemID   int64  `json:"item_id"`
+	ItemName string `json:"item_name"`
+	BidderID int64  `json:"bidder_id"`
+	// The bid that now leads, or the lowest bid still winning a unit
+	LeadingBidID  int64  `json:"leading_bid_id"`
+	LeadingAmount Money  `json:"leading_amount"`
+	Currency      string `json:"currency"`
+}
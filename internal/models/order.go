@@ -0,0 +1,131 @@
+package models
+
+import "time"
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPending  PaymentStatus = "pending"
+	PaymentStatusPaid     PaymentStatus = "paid"
+	PaymentStatusRefunded PaymentStatus = "refunded"
+	PaymentStatusFailed   PaymentStatus = "failed"
+)
+
+// Statuses an order may move to from each payment status, a refund is final
+var paymentTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusPending: {PaymentStatusPaid, PaymentStatusFailed},
+	PaymentStatusFailed:  {PaymentStatusPending, PaymentStatusPaid},
+	PaymentStatusPaid:    {PaymentStatusRefunded},
+}
+
+// Check whether an order in this payment status may move to next
+func (s PaymentStatus) CanTransitionTo(next PaymentStatus) bool {
+	for _, allowed := range paymentTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+type OrderLineKind string
+
+const (
+	OrderLineHammer        OrderLineKind = "hammer"
+	OrderLineBuyersPremium OrderLineKind = "buyers_premium"
+	OrderLineFee           OrderLineKind = "fee"
+	OrderLineTax           OrderLineKind = "tax"
+)
+
+type OrderLine struct {
+	ID          int64         `json:"id,omitempty"`
+	OrderID     int64         `json:"order_id,omitempty"`
+	Kind        OrderLineKind `json:"kind"`
+	Description string        `json:"description"`
+	Amount      Money         `json:"amount"`
+}
+
+// The sale of a winning bid, a multi-unit auction has one order per winning bid
+type Order struct {
+	ID       int64  `json:"id,omitempty"`
+	ItemID   int64  `json:"item_id"`
+	BidID    int64  `json:"bid_id"`
+	BuyerID  int64  `json:"buyer_id"`
+	SellerID int64  `json:"seller_id"`
+	Currency string `json:"currency"`
+	Quantity int    `json:"quantity"`
+	// Sum of the order lines
+	Total Money `json:"total"`
+	// Part of the total already paid from the buyer's wallet, the rest is paid through the payment provider
+	WalletAmount   Money                `json:"wallet_amount"`
+	PaymentStatus  PaymentStatus        `json:"payment_status"`
+	PaidAt         *time.Time           `json:"paid_at,omitempty"`
+	RefundedAt     *time.Time           `json:"refunded_at,omitempty"`
+	Lines          []OrderLine          `json:"lines"`
+	PaymentHistory []PaymentTransition  `json:"payment_history,omitempty"`
+	Transactions   []PaymentTransaction `json:"transactions,omitempty"`
+	CreatedAt      time.Time            `json:"created_at,omitzero"`
+	UpdatedAt      time.Time            `json:"updated_at,omitzero"`
+}
+
+// What is left to pay through the payment provider
+func (o Order) AmountDue() Money {
+	return o.Total - o.WalletAmount
+}
+
+// A call made to the payment provider for an order, identified on the provider's side by ProviderTransactionID
+type PaymentTransaction struct {
+	ID                    int64     `json:"id,omitempty"`
+	OrderID               int64     `json:"order_id"`
+	Provider              string    `json:"provider"`
+	Kind                  string    `json:"kind"`
+	ProviderTransactionID string    `json:"provider_transaction_id,omitempty"`
+	Amount                Money     `json:"amount"`
+	Currency              string    `json:"currency"`
+	Status                string    `json:"status"`
+	FailureReason         *string   `json:"failure_reason,omitempty"`
+	CreatedAt             time.Time `json:"created_at,omitzero"`
+}
+
+type PayOrderRequest struct {
+	// Provider specific token of the card or account to charge
+	PaymentMethod string `json:"payment_method" validate:"required,max=255"`
+}
+
+type PaymentTransition struct {
+	ID         int64         `json:"id,omitempty"`
+	OrderID    int64         `json:"order_id"`
+	FromStatus PaymentStatus `json:"from_status"`
+	ToStatus   PaymentStatus `json:"to_status"`
+	// Empty when the change did not come from a user
+	ChangedBy *int64    `json:"changed_by,omitempty"`
+	Note      *string   `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+}
+
+type PaymentStatusUpdate struct {
+	Status PaymentStatus `json:"status" validate:"required,oneof=pending refunded failed"`
+	Note   *string       `json:"note,omitempty" validate:"omitempty,min=1,max=1000"`
+}
+
+// A printable summary of an order, numbered after the order it was issued for
+type Invoice struct {
+	Number         string        `json:"number"`
+	IssuedAt       time.Time     `json:"issued_at"`
+	OrderID        int64         `json:"order_id"`
+	ItemID         int64         `json:"item_id"`
+	ItemName       string        `json:"item_name"`
+	Quantity       int           `json:"quantity"`
+	BuyerID        int64         `json:"buyer_id"`
+	BuyerUsername  string        `json:"buyer_username"`
+	SellerID       int64         `json:"seller_id"`
+	SellerUsername string        `json:"seller_username"`
+	Currency       string        `json:"currency"`
+	Lines          []OrderLine   `json:"lines"`
+	Total          Money         `json:"total"`
+	WalletAmount   Money         `json:"wallet_amount"`
+	AmountDue      Money         `json:"amount_due"`
+	PaymentStatus  PaymentStatus `json:"payment_status"`
+	PaidAt         *time.Time    `json:"paid_at,omitempty"`
+}
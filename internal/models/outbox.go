@@ -0,0 +1,35 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// What an outbox event is about, events are delivered in order per aggregate
+type AggregateType string
+
+const (
+	AggregateItem  AggregateType = "item"
+	AggregateOrder AggregateType = "order"
+)
+
+// A domain event waiting in the outbox to be delivered
+type OutboxEvent struct {
+	ID            int64           `json:"id"`
+	AggregateType AggregateType   `json:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id"`
+	ItemID        int64           `json:"item_id"`
+	EventType     string          `json:"type"`
+	Payload       json.RawMessage `json:"data"`
+	// Publication to the event bus, retried until the event could be handed over
+	PublishAttempts      int        `json:"-"`
+	PublishLastError     *string    `json:"-"`
+	PublishNextAttemptAt time.Time  `json:"-"`
+	PublishedAt          *time.Time `json:"-"`
+	// Delivery to the webhooks, retried until every endpoint took the event
+	WebhookAttempts      int        `json:"-"`
+	WebhookLastError     *string    `json:"-"`
+	WebhookNextAttemptAt time.Time  `json:"-"`
+	WebhookDeliveredAt   *time.Time `json:"-"`
+	CreatedAt            time.Time  `json:"occurred_at"`
+}
@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+// A single-use ticket that opens one live feed or event stream connection, passed as ?ticket=
+type StreamTicket struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -4,4 +4,6 @@ type User struct {
 	ID       int64  `json:"id"`
 	Username string `json:"username" validate:"required,min=3,max=25"`
 	Password string `json:"password" validate:"required,min=8,max=50"`
+	// Currency the user's auctions are priced in
+	Currency string `json:"currency,omitempty" validate:"omitempty,iso4217"`
 }
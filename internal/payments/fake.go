@@ -0,0 +1,158 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/bangueco/auction-api/internal/models"
+)
+
+// Payment methods the fake provider declines, every other payment method is accepted
+const (
+	FakeMethodDeclined          = "fake_declined"
+	FakeMethodInsufficientFunds = "fake_insufficient_funds"
+)
+
+// FakeProvider is an in-process PaymentProvider for development and tests.
+// It never touches the network, numbers its transactions in order and declines only the fake decline methods,
+// so the same calls always give the same results.
+type FakeProvider struct {
+	secret []byte
+
+	mu           sync.Mutex
+	sequence     int
+	transactions map[string]Transaction
+	// Authorization and refund per reference, capture per authorization and refunded so far per capture
+	authorizations map[string]string
+	captures       map[string]string
+	refunds        map[string]string
+	refunded       map[string]models.Money
+}
+
+func NewFakeProvider(secret string) *FakeProvider {
+	return &FakeProvider{
+		secret:         []byte(secret),
+		transactions:   make(map[string]Transaction),
+		authorizations: make(map[string]string),
+		captures:       make(map[string]string),
+		refunds:        make(map[string]string),
+		refunded:       make(map[string]models.Money),
+	}
+}
+
+func (f *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (f *FakeProvider) Authorize(ctx context.Context, request AuthorizeRequest) (Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if id, ok := f.authorizations[request.Reference]; ok {
+		return f.transactions[id], nil
+	}
+
+	transaction := Transaction{Kind: TransactionAuthorize, Status: TransactionSucceeded, Amount: request.Amount, Currency: request.Currency}
+
+	switch request.PaymentMethod {
+	case FakeMethodDeclined:
+		transaction.Status, transaction.FailureReason = TransactionFailed, "card_declined"
+	case FakeMethodInsufficientFunds:
+		transaction.Status, transaction.FailureReason = TransactionFailed, "insufficient_funds"
+	}
+
+	transaction = f.record(transaction)
+	f.authorizations[request.Reference] = transaction.ID
+
+	return transaction, nil
+}
+
+func (f *FakeProvider) Capture(ctx context.Context, authorizationID string, amount models.Money) (Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	authorization, ok := f.transactions[authorizationID]
+
+	if !ok || authorization.Kind != TransactionAuthorize || authorization.Status != TransactionSucceeded {
+		return Transaction{}, ErrUnknownTransaction
+	}
+
+	if id, ok := f.captures[authorizationID]; ok {
+		return f.transactions[id], nil
+	}
+
+	if amount <= 0 || amount > authorization.Amount {
+		return Transaction{}, ErrInvalidAmount
+	}
+
+	capture := f.record(Transaction{Kind: TransactionCapture, Status: TransactionSucceeded, Amount: amount, Currency: authorization.Currency})
+	f.captures[authorizationID] = capture.ID
+
+	return capture, nil
+}
+
+func (f *FakeProvider) Refund(ctx context.Context, request RefundRequest) (Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if id, ok := f.refunds[request.Reference]; ok {
+		return f.transactions[id], nil
+	}
+
+	capture, ok := f.transactions[request.CaptureID]
+
+	if !ok || capture.Kind != TransactionCapture {
+		return Transaction{}, ErrUnknownTransaction
+	}
+
+	if request.Amount <= 0 || f.refunded[request.CaptureID]+request.Amount > capture.Amount {
+		return Transaction{}, ErrInvalidAmount
+	}
+
+	f.refunded[request.CaptureID] += request.Amount
+
+	refund := f.record(Transaction{Kind: TransactionRefund, Status: TransactionSucceeded, Amount: request.Amount, Currency: capture.Currency})
+	f.refunds[request.Reference] = refund.ID
+
+	return refund, nil
+}
+
+func (f *FakeProvider) VerifyWebhook(payload []byte, signature string) (WebhookEvent, error) {
+	var event WebhookEvent
+
+	expected, err := hex.DecodeString(signature)
+
+	if err != nil || !hmac.Equal(expected, f.sign(payload)) {
+		return event, ErrInvalidSignature
+	}
+
+	err = json.Unmarshal(payload, &event)
+
+	return event, err
+}
+
+// Sign a webhook payload the way VerifyWebhook expects, to simulate the provider calling back
+func (f *FakeProvider) SignWebhook(payload []byte) string {
+	return hex.EncodeToString(f.sign(payload))
+}
+
+func (f *FakeProvider) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, f.secret)
+	mac.Write(payload)
+
+	return mac.Sum(nil)
+}
+
+// Give a transaction the next ID and keep it, the caller holds the lock
+func (f *FakeProvider) record(transaction Transaction) Transaction {
+	f.sequence++
+	transaction.ID = fmt.Sprintf("fake_%s_%06d", transaction.Kind, f.sequence)
+	f.transactions[transaction.ID] = transaction
+
+	return transaction
+}
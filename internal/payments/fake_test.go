@@ -0,0 +1,166 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bangueco/auction-api/internal/models"
+)
+
+func TestFakeProviderCheckout(t *testing.T) {
+	tests := []struct {
+		name          string
+		paymentMethod string
+		wantStatus    TransactionStatus
+		wantReason    string
+	}{
+		{"approved", "pm_card", TransactionSucceeded, ""},
+		{"declined", FakeMethodDeclined, TransactionFailed, "card_declined"},
+		{"insufficient funds", FakeMethodInsufficientFunds, TransactionFailed, "insufficient_funds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewFakeProvider("secret")
+			ctx := context.Background()
+
+			authorization, err := provider.Authorize(ctx, AuthorizeRequest{Reference: "order-1-payment-1", Amount: 125000, Currency: "USD", PaymentMethod: tt.paymentMethod})
+
+			if err != nil {
+				t.Fatalf("Authorize() error = %v", err)
+			}
+
+			if authorization.Kind != TransactionAuthorize || authorization.Status != tt.wantStatus || authorization.FailureReason != tt.wantReason {
+				t.Fatalf("Authorize() = %+v, want status %s and reason %q", authorization, tt.wantStatus, tt.wantReason)
+			}
+
+			capture, err := provider.Capture(ctx, authorization.ID, authorization.Amount)
+
+			if tt.wantStatus == TransactionFailed {
+				if !errors.Is(err, ErrUnknownTransaction) {
+					t.Fatalf("Capture() of a declined authorization error = %v, want ErrUnknownTransaction", err)
+				}
+				return
+			}
+
+			if err != nil || capture.Kind != TransactionCapture || capture.Status != TransactionSucceeded || capture.Amount != 125000 || capture.Currency != "USD" {
+				t.Fatalf("Capture() = %+v, %v", capture, err)
+			}
+		})
+	}
+}
+
+func TestFakeProviderIdempotency(t *testing.T) {
+	provider := NewFakeProvider("secret")
+	ctx := context.Background()
+	request := AuthorizeRequest{Reference: "order-1-payment-1", Amount: 125000, Currency: "USD", PaymentMethod: "pm_card"}
+
+	first, _ := provider.Authorize(ctx, request)
+	again, _ := provider.Authorize(ctx, request)
+
+	if first.ID != again.ID {
+		t.Errorf("Authorize() with the same reference = %s, want %s", again.ID, first.ID)
+	}
+
+	other, _ := provider.Authorize(ctx, AuthorizeRequest{Reference: "order-1-payment-2", Amount: 125000, Currency: "USD", PaymentMethod: "pm_card"})
+
+	if other.ID == first.ID {
+		t.Errorf("Authorize() with a new reference reused %s", first.ID)
+	}
+
+	capture, _ := provider.Capture(ctx, first.ID, first.Amount)
+	captureAgain, _ := provider.Capture(ctx, first.ID, first.Amount)
+
+	if capture.ID != captureAgain.ID {
+		t.Errorf("Capture() of the same authorization = %s, want %s", captureAgain.ID, capture.ID)
+	}
+
+	refund, _ := provider.Refund(ctx, RefundRequest{Reference: "order-1-refund-3", CaptureID: capture.ID, Amount: capture.Amount})
+	refundAgain, err := provider.Refund(ctx, RefundRequest{Reference: "order-1-refund-3", CaptureID: capture.ID, Amount: capture.Amount})
+
+	if err != nil || refund.ID != refundAgain.ID {
+		t.Errorf("Refund() with the same reference = %s, %v, want %s", refundAgain.ID, err, refund.ID)
+	}
+}
+
+func TestFakeProviderCaptureAndRefundLimits(t *testing.T) {
+	provider := NewFakeProvider("secret")
+	ctx := context.Background()
+
+	authorization, _ := provider.Authorize(ctx, AuthorizeRequest{Reference: "order-1-payment-1", Amount: 100000, Currency: "USD", PaymentMethod: "pm_card"})
+
+	if _, err := provider.Capture(ctx, authorization.ID, 100001); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("Capture() above the authorization error = %v, want ErrInvalidAmount", err)
+	}
+
+	if _, err := provider.Capture(ctx, "fake_authorize_999999", 100000); !errors.Is(err, ErrUnknownTransaction) {
+		t.Errorf("Capture() of an unknown authorization error = %v, want ErrUnknownTransaction", err)
+	}
+
+	capture, _ := provider.Capture(ctx, authorization.ID, 100000)
+
+	tests := []struct {
+		name      string
+		reference string
+		captureID string
+		amount    int64
+		wantErr   error
+	}{
+		{"unknown capture", "refund-1", "fake_capture_999999", 100, ErrUnknownTransaction},
+		{"authorization is not a capture", "refund-2", authorization.ID, 100, ErrUnknownTransaction},
+		{"zero amount", "refund-3", capture.ID, 0, ErrInvalidAmount},
+		{"partial refund", "refund-4", capture.ID, 60000, nil},
+		{"more than is left", "refund-5", capture.ID, 40001, ErrInvalidAmount},
+		{"rest of the capture", "refund-6", capture.ID, 40000, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refund, err := provider.Refund(ctx, RefundRequest{Reference: tt.reference, CaptureID: tt.captureID, Amount: models.Money(tt.amount)})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Refund() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && (refund.Kind != TransactionRefund || refund.Status != TransactionSucceeded || int64(refund.Amount) != tt.amount) {
+				t.Errorf("Refund() = %+v", refund)
+			}
+		})
+	}
+}
+
+func TestFakeProviderVerifyWebhook(t *testing.T) {
+	provider := NewFakeProvider("secret")
+	payload := []byte(`{"id":"evt_1","type":"payment.refunded","transaction_id":"fake_capture_000002"}`)
+	signature := provider.SignWebhook(payload)
+
+	tests := []struct {
+		name      string
+		provider  *FakeProvider
+		payload   []byte
+		signature string
+		wantErr   error
+	}{
+		{"valid", provider, payload, signature, nil},
+		{"replayed", provider, payload, signature, nil},
+		{"tampered payload", provider, []byte(`{"id":"evt_1","type":"payment.captured","transaction_id":"fake_capture_000002"}`), signature, ErrInvalidSignature},
+		{"other secret", NewFakeProvider("other"), payload, signature, ErrInvalidSignature},
+		{"not hex", provider, payload, "not-a-signature", ErrInvalidSignature},
+		{"missing", provider, payload, "", ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := tt.provider.VerifyWebhook(tt.payload, tt.signature)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyWebhook() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && (event.ID != "evt_1" || event.Type != WebhookPaymentRefunded || event.TransactionID != "fake_capture_000002") {
+				t.Errorf("VerifyWebhook() = %+v", event)
+			}
+		})
+	}
+}
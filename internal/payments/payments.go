@@ -0,0 +1,89 @@
+package payments
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bangueco/auction-api/internal/models"
+)
+
+var (
+	ErrUnknownTransaction = errors.New("payment transaction not found")
+	ErrInvalidAmount      = errors.New("amount exceeds what the transaction allows")
+	ErrInvalidSignature   = errors.New("webhook signature is invalid")
+)
+
+type TransactionKind string
+
+const (
+	TransactionAuthorize TransactionKind = "authorize"
+	TransactionCapture   TransactionKind = "capture"
+	TransactionRefund    TransactionKind = "refund"
+)
+
+type TransactionStatus string
+
+const (
+	// Recorded before the provider is called, until its answer is applied
+	TransactionPending   TransactionStatus = "pending"
+	TransactionSucceeded TransactionStatus = "succeeded"
+	TransactionFailed    TransactionStatus = "failed"
+)
+
+// The provider's record of an authorization, capture or refund.
+// A declined payment is a failed transaction, errors are kept for requests the provider could not process.
+type Transaction struct {
+	ID            string
+	Kind          TransactionKind
+	Status        TransactionStatus
+	Amount        models.Money
+	Currency      string
+	FailureReason string
+}
+
+type AuthorizeRequest struct {
+	// Our own reference for the payment attempt, also its idempotency key:
+	// authorizing the same reference again returns the first authorization instead of charging twice
+	Reference string
+	Amount    models.Money
+	Currency  string
+	// Provider specific token of the card or account to charge
+	PaymentMethod string
+}
+
+type RefundRequest struct {
+	// Our own reference for the refund attempt, also its idempotency key:
+	// refunding the same reference again returns the first refund instead of paying out twice
+	Reference string
+	CaptureID string
+	Amount    models.Money
+}
+
+type WebhookEventType string
+
+const (
+	WebhookPaymentCaptured WebhookEventType = "payment.captured"
+	WebhookPaymentFailed   WebhookEventType = "payment.failed"
+	WebhookPaymentRefunded WebhookEventType = "payment.refunded"
+)
+
+// A notification the provider sends when a transaction changes on its side
+type WebhookEvent struct {
+	ID            string           `json:"id"`
+	Type          WebhookEventType `json:"type"`
+	TransactionID string           `json:"transaction_id"`
+}
+
+// PaymentProvider is the gateway orders are paid through
+type PaymentProvider interface {
+	// Name recorded with every transaction, transaction IDs are only unique per provider
+	Name() string
+	// Reserve an amount on the payment method without moving it yet, at most once per reference
+	Authorize(ctx context.Context, request AuthorizeRequest) (Transaction, error)
+	// Collect up to the authorized amount, capturing the same authorization again returns the first capture
+	Capture(ctx context.Context, authorizationID string, amount models.Money) (Transaction, error)
+	// Return up to the captured amount to the payer, at most once per reference
+	Refund(ctx context.Context, request RefundRequest) (Transaction, error)
+	// Check the signature of a webhook request and decode its event
+	VerifyWebhook(payload []byte, signature string) (WebhookEvent, error)
+}
@@ -0,0 +1,145 @@
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// A message a client sends to change the items it follows
+type clientMessage struct {
+	Action string `json:"action"`
+	ItemID int64  `json:"item_id"`
+}
+
+// A reply to a client message
+type controlMessage struct {
+	Event   string `json:"event"`
+	ItemID  int64  `json:"item_id,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Client is one WebSocket connection to the live feed.
+// Only writePump writes data frames to the connection, everyone else queues messages on send.
+type Client struct {
+	hub    *Hub
+	conn   *websocket.Conn
+	userID int64
+	send   chan []byte
+	// Items the client follows, guarded by the hub's lock
+	items map[int64]struct{}
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// Queue a message without blocking. A client whose queue is full has fallen too far behind
+// and is disconnected, it can reconnect and reload the items it follows.
+func (c *Client) enqueue(payload []byte) {
+	select {
+	case <-c.done:
+	case c.send <- payload:
+	default:
+		c.close(websocket.ClosePolicyViolation, "client is not keeping up")
+	}
+}
+
+// Send a close frame and tear down the connection, safe to call from any goroutine and more than once
+func (c *Client) close(code int, reason string) {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.hub.unregister(c)
+		c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(writeWait))
+		c.conn.Close()
+	})
+}
+
+// Read subscribe and unsubscribe messages until the connection fails or the client stops answering pings
+func (c *Client) readPump() {
+	defer c.close(websocket.CloseNormalClosure, "")
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		var message clientMessage
+
+		err := c.conn.ReadJSON(&message)
+
+		if err != nil {
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+
+			if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+				c.reply(controlMessage{Event: "error", Message: "Invalid message"})
+				continue
+			}
+
+			return
+		}
+
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+
+		switch message.Action {
+		case "subscribe":
+			if message.ItemID <= 0 || !c.hub.subscribe(c, message.ItemID) {
+				c.reply(controlMessage{Event: "error", ItemID: message.ItemID, Message: "Cannot follow this item"})
+				continue
+			}
+
+			c.reply(controlMessage{Event: "subscribed", ItemID: message.ItemID})
+		case "unsubscribe":
+			c.hub.unsubscribe(c, message.ItemID)
+			c.reply(controlMessage{Event: "unsubscribed", ItemID: message.ItemID})
+		default:
+			c.reply(controlMessage{Event: "error", Message: "Unknown action"})
+		}
+	}
+}
+
+// Write queued messages and heartbeat pings until the connection is closed
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+
+	defer func() {
+		ticker.Stop()
+		c.close(websocket.CloseNormalClosure, "")
+	}()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case payload := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+
+			err := c.conn.WriteMessage(websocket.TextMessage, payload)
+
+			if err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+
+			err := c.conn.WriteMessage(websocket.PingMessage, nil)
+
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *Client) reply(message controlMessage) {
+	payload, err := json.Marshal(message)
+
+	if err == nil {
+		c.enqueue(payload)
+	}
+}
@@ -0,0 +1,206 @@
+package realtime
+
+import (
+	"container/list"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bangueco/auction-api/internal/events"
+)
+
+// Events queued for a stream listener before it is dropped as too slow
+const listenerBufferSize = 64
+
+// A feed message with the ID clients resume from
+type LoggedEvent struct {
+	ID      int64
+	Event   string
+	Payload []byte
+}
+
+// EventLog keeps the latest feed events of each item so reconnecting Server-Sent Events clients can catch up,
+// and passes new events on to the streams listening on the item.
+// IDs are seeded from the start time, so IDs handed out before a restart always sort before the current ones.
+type EventLog struct {
+	mu       sync.Mutex
+	size     int
+	maxItems int
+	nextID   int64
+	logs     map[int64]*list.Element
+	// Items ordered by their last event, the least recently active at the back
+	recent    *list.List
+	listeners map[int64]map[*Listener]struct{}
+	closed    bool
+}
+
+type itemLog struct {
+	itemID int64
+	events []LoggedEvent
+}
+
+// A stream waiting for an item's events
+type Listener struct {
+	itemID int64
+	events chan LoggedEvent
+}
+
+// Events for the listener, the channel is closed when the listener falls behind or the log shuts down
+func (l *Listener) Events() <-chan LoggedEvent {
+	return l.events
+}
+
+// Create a log keeping up to size events for each of the maxItems most recently active items
+func NewEventLog(dispatcher *events.Dispatcher, size int, maxItems int) *EventLog {
+	el := &EventLog{
+		size:      size,
+		maxItems:  maxItems,
+		nextID:    time.Now().UnixMicro(),
+		logs:      make(map[int64]*list.Element),
+		recent:    list.New(),
+		listeners: make(map[int64]map[*Listener]struct{}),
+	}
+
+	for _, eventType := range FeedEventTypes() {
+		dispatcher.Subscribe(eventType, el.append)
+	}
+
+	return el
+}
+
+// Start listening on an item. The retained events logged after lastEventID are returned for replay,
+// a lastEventID of 0 means the client has seen nothing yet and wants only new events.
+func (el *EventLog) Listen(itemID int64, lastEventID int64) (*Listener, []LoggedEvent, error) {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	if el.closed {
+		return nil, nil, ErrHubClosed
+	}
+
+	var missed []LoggedEvent
+
+	if lastEventID > 0 {
+		if element, ok := el.logs[itemID]; ok {
+			for _, event := range element.Value.(*itemLog).events {
+				if event.ID > lastEventID {
+					missed = append(missed, event)
+				}
+			}
+		}
+	}
+
+	listener := &Listener{itemID: itemID, events: make(chan LoggedEvent, listenerBufferSize)}
+
+	if el.listeners[itemID] == nil {
+		el.listeners[itemID] = make(map[*Listener]struct{})
+	}
+
+	el.listeners[itemID][listener] = struct{}{}
+
+	return listener, missed, nil
+}
+
+// Stop listening, safe to call after the listener was dropped
+func (el *EventLog) Unlisten(listener *Listener) {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	el.removeListener(listener)
+}
+
+// Check whether the log still takes listeners
+func (el *EventLog) Open() bool {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	return !el.closed
+}
+
+// End every stream and refuse new ones, called when the server stops
+func (el *EventLog) Shutdown() {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	el.closed = true
+
+	for _, listeners := range el.listeners {
+		for listener := range listeners {
+			el.removeListener(listener)
+		}
+	}
+}
+
+// The caller holds the lock
+func (el *EventLog) removeListener(listener *Listener) {
+	listeners, ok := el.listeners[listener.itemID]
+
+	if !ok {
+		return
+	}
+
+	if _, ok := listeners[listener]; !ok {
+		return
+	}
+
+	delete(listeners, listener)
+	close(listener.events)
+
+	if len(listeners) == 0 {
+		delete(el.listeners, listener.itemID)
+	}
+}
+
+// Log an event and hand it to the item's listeners.
+// Listeners are never waited on, one whose buffer is full is dropped and replays what it missed once it reconnects.
+func (el *EventLog) append(event events.Event) {
+	message, ok := NewFeedMessage(event)
+
+	if !ok {
+		return
+	}
+
+	payload, err := json.Marshal(message)
+
+	if err != nil {
+		log.Printf("Error encoding event log entry: %v", err)
+		return
+	}
+
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	el.nextID++
+	logged := LoggedEvent{ID: el.nextID, Event: message.Event, Payload: payload}
+
+	element, ok := el.logs[event.ItemID]
+
+	if ok {
+		el.recent.MoveToFront(element)
+	} else {
+		element = el.recent.PushFront(&itemLog{itemID: event.ItemID})
+		el.logs[event.ItemID] = element
+
+		if el.recent.Len() > el.maxItems {
+			oldest := el.recent.Back()
+			el.recent.Remove(oldest)
+			delete(el.logs, oldest.Value.(*itemLog).itemID)
+		}
+	}
+
+	entries := element.Value.(*itemLog)
+	entries.events = append(entries.events, logged)
+
+	if len(entries.events) > el.size {
+		entries.events = entries.events[len(entries.events)-el.size:]
+	}
+
+	for listener := range el.listeners[event.ItemID] {
+		select {
+		case listener.events <- logged:
+		default:
+			el.removeListener(listener)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package realtime
+
+import (
+	"time"
+
+	"github.com/bangueco/auction-api/internal/events"
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/services"
+)
+
+// Names events go by on the live feeds
+const (
+	FeedBidPlaced    = "bid-placed"
+	FeedExtended     = "extended"
+	FeedClosed       = "closed"
+	FeedPriceChanged = "price-changed"
+)
+
+var feedNames = map[events.EventType]string{
+	events.BidPlaced:    FeedBidPlaced,
+	events.ItemExtended: FeedExtended,
+	events.ItemClosed:   FeedClosed,
+	events.PriceChanged: FeedPriceChanged,
+}
+
+// Event types the live feeds carry
+func FeedEventTypes() []events.EventType {
+	return []events.EventType{events.BidPlaced, events.ItemExtended, events.ItemClosed, events.PriceChanged}
+}
+
+// An event as it is sent to feed clients
+type FeedMessage struct {
+	Event      string    `json:"event"`
+	ItemID     int64     `json:"item_id"`
+	Data       any       `json:"data,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Turn an event into a feed message, items are shown the way any bidder would see them.
+// It reports false for events the feeds do not carry.
+func NewFeedMessage(event events.Event) (FeedMessage, bool) {
+	name, ok := feedNames[event.Type]
+
+	if !ok {
+		return FeedMessage{}, false
+	}
+
+	data := event.Data
+
+	if item, ok := data.(models.Item); ok {
+		data = services.PublicItem(item, event.OccurredAt)
+	}
+
+	return FeedMessage{Event: name, ItemID: event.ItemID, Data: data, OccurredAt: event.OccurredAt}, true
+}
@@ -0,0 +1,187 @@
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bangueco/auction-api/internal/events"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// Time allowed to write a message to the client
+	writeWait = 10 * time.Second
+	// Time allowed between two messages from the client, pongs included
+	pongWait = 60 * time.Second
+	// How often the client is pinged, has to be shorter than pongWait
+	pingPeriod = pongWait * 9 / 10
+	// Largest message a client may send
+	maxMessageSize = 1024
+	// Messages queued for a client before it is dropped as too slow
+	sendBufferSize = 64
+	// Items a single connection may follow
+	MaxSubscriptions = 100
+)
+
+var ErrHubClosed = errors.New("live feed is shutting down")
+
+// Hub fans events out to the WebSocket clients following each item
+type Hub struct {
+	mu          sync.RWMutex
+	clients     map[*Client]struct{}
+	subscribers map[int64]map[*Client]struct{}
+	closed      bool
+}
+
+// Create a hub that forwards every feed event published on the dispatcher
+func NewHub(dispatcher *events.Dispatcher) *Hub {
+	h := &Hub{
+		clients:     make(map[*Client]struct{}),
+		subscribers: make(map[int64]map[*Client]struct{}),
+	}
+
+	for _, eventType := range FeedEventTypes() {
+		dispatcher.Subscribe(eventType, h.broadcast)
+	}
+
+	return h
+}
+
+// Start serving an upgraded connection for a user, following the given items from the start
+func (h *Hub) Register(conn *websocket.Conn, userID int64, itemIDs []int64) error {
+	client := &Client{hub: h, conn: conn, userID: userID, send: make(chan []byte, sendBufferSize), items: make(map[int64]struct{}), done: make(chan struct{})}
+
+	h.mu.Lock()
+
+	if h.closed {
+		h.mu.Unlock()
+		return ErrHubClosed
+	}
+
+	h.clients[client] = struct{}{}
+	h.mu.Unlock()
+
+	for _, itemID := range itemIDs {
+		h.subscribe(client, itemID)
+	}
+
+	go client.writePump()
+	go client.readPump()
+
+	return nil
+}
+
+// Close every connection with a going away frame and refuse new ones, called when the server stops
+func (h *Hub) Shutdown() {
+	h.mu.Lock()
+	h.closed = true
+	clients := make([]*Client, 0, len(h.clients))
+
+	for client := range h.clients {
+		clients = append(clients, client)
+	}
+
+	h.mu.Unlock()
+
+	for _, client := range clients {
+		client.close(websocket.CloseGoingAway, "server shutting down")
+	}
+
+	log.Printf("Live feed closed %d connections", len(clients))
+}
+
+// Check whether the hub still takes connections
+func (h *Hub) Open() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return !h.closed
+}
+
+func (h *Hub) subscribe(client *Client, itemID int64) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.clients[client]; !ok {
+		return false
+	}
+
+	if _, ok := client.items[itemID]; !ok && len(client.items) >= MaxSubscriptions {
+		return false
+	}
+
+	if h.subscribers[itemID] == nil {
+		h.subscribers[itemID] = make(map[*Client]struct{})
+	}
+
+	h.subscribers[itemID][client] = struct{}{}
+	client.items[itemID] = struct{}{}
+
+	return true
+}
+
+func (h *Hub) unsubscribe(client *Client, itemID int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.removeSubscription(client, itemID)
+}
+
+// Forget a client and every item it followed
+func (h *Hub) unregister(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for itemID := range client.items {
+		h.removeSubscription(client, itemID)
+	}
+
+	delete(h.clients, client)
+}
+
+// The caller holds the write lock
+func (h *Hub) removeSubscription(client *Client, itemID int64) {
+	delete(client.items, itemID)
+	delete(h.subscribers[itemID], client)
+
+	if len(h.subscribers[itemID]) == 0 {
+		delete(h.subscribers, itemID)
+	}
+}
+
+// Queue an event for every client following its item.
+// Clients are collected under the lock and written to after it is released, so a slow client never holds up the others.
+func (h *Hub) broadcast(event events.Event) {
+	message, ok := NewFeedMessage(event)
+
+	if !ok {
+		return
+	}
+
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.subscribers[event.ItemID]))
+
+	for client := range h.subscribers[event.ItemID] {
+		clients = append(clients, client)
+	}
+
+	h.mu.RUnlock()
+
+	if len(clients) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(message)
+
+	if err != nil {
+		log.Printf("Error encoding live feed event: %v", err)
+		return
+	}
+
+	for _, client := range clients {
+		client.enqueue(payload)
+	}
+}
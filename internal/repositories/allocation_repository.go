@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Columns selected for every allocation query, in the order scanAllocation expects them
+const allocationColumns = `id, item_id, bid_id, bidder_id, quantity, unit_price, created_at`
+
+type AllocationRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewAllocationRepository(DB *pgxpool.Pool) *AllocationRepository {
+	return &AllocationRepository{DB}
+}
+
+func scanAllocation(row pgx.Row) (models.Allocation, error) {
+	var allocation models.Allocation
+
+	err := row.Scan(&allocation.ID, &allocation.ItemID, &allocation.BidID, &allocation.BidderID, &allocation.Quantity, &allocation.UnitPrice, &allocation.CreatedAt)
+	allocation.TotalPrice = allocation.UnitPrice * models.Money(allocation.Quantity)
+
+	return allocation, err
+}
+
+// Record an allocation inside an existing transaction
+func (a *AllocationRepository) CreateAllocation(tx pgx.Tx, allocation models.Allocation) (models.Allocation, error) {
+	query := `INSERT INTO allocations (item_id, bid_id, bidder_id, quantity, unit_price) VALUES (@item_id, @bid_id, @bidder_id, @quantity, @unit_price) RETURNING ` + allocationColumns
+	namedArgs := pgx.NamedArgs{
+		"item_id":    allocation.ItemID,
+		"bid_id":     allocation.BidID,
+		"bidder_id":  allocation.BidderID,
+		"quantity":   allocation.Quantity,
+		"unit_price": allocation.UnitPrice,
+	}
+
+	return scanAllocation(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve the allocations of an item, only those of one bidder when bidderID is set
+func (a *AllocationRepository) GetAllocationsByItemID(itemID int64, bidderID *int64) ([]models.Allocation, error) {
+	query := `SELECT ` + allocationColumns + ` FROM allocations WHERE item_id = @item_id AND (@bidder_id::integer IS NULL OR bidder_id = @bidder_id) ORDER BY unit_price DESC, id`
+	namedArgs := pgx.NamedArgs{
+		"item_id":   itemID,
+		"bidder_id": bidderID,
+	}
+
+	return a.queryAllocations(query, namedArgs)
+}
+
+// Retrieve every allocation a bidder has won, newest first
+func (a *AllocationRepository) GetAllocationsByBidderID(bidderID int64) ([]models.Allocation, error) {
+	query := `SELECT ` + allocationColumns + ` FROM allocations WHERE bidder_id = @bidder_id ORDER BY created_at DESC, id DESC`
+	namedArgs := pgx.NamedArgs{
+		"bidder_id": bidderID,
+	}
+
+	return a.queryAllocations(query, namedArgs)
+}
+
+func (a *AllocationRepository) queryAllocations(query string, namedArgs pgx.NamedArgs) ([]models.Allocation, error) {
+	var allocations []models.Allocation
+
+	rows, err := a.DB.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		allocation, err := scanAllocation(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		allocations = append(allocations, allocation)
+	}
+
+	return allocations, rows.Err()
+}
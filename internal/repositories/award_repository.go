@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Columns selected for every award query, in the order scanAward expects them
+const awardColumns = `id, item_id, bid_id, previous_bid_id, awarded_by, reason, created_at`
+
+type AwardRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewAwardRepository(DB *pgxpool.Pool) *AwardRepository {
+	return &AwardRepository{DB}
+}
+
+func scanAward(row pgx.Row) (models.Award, error) {
+	var award models.Award
+
+	err := row.Scan(&award.ID, &award.ItemID, &award.BidID, &award.PreviousBidID, &award.AwardedBy, &award.Reason, &award.CreatedAt)
+
+	return award, err
+}
+
+// Record an award inside an existing transaction
+func (a *AwardRepository) CreateAward(tx pgx.Tx, award models.Award) (models.Award, error) {
+	query := `INSERT INTO awards (item_id, bid_id, previous_bid_id, awarded_by, reason) VALUES (@item_id, @bid_id, @previous_bid_id, @awarded_by, @reason) RETURNING ` + awardColumns
+	namedArgs := pgx.NamedArgs{
+		"item_id":         award.ItemID,
+		"bid_id":          award.BidID,
+		"previous_bid_id": award.PreviousBidID,
+		"awarded_by":      award.AwardedBy,
+		"reason":          award.Reason,
+	}
+
+	return scanAward(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve every award made on an item, oldest first
+func (a *AwardRepository) GetAwardsByItemID(itemID int64) ([]models.Award, error) {
+	var awards []models.Award
+
+	query := `SELECT ` + awardColumns + ` FROM awards WHERE item_id = @item_id ORDER BY created_at, id`
+	namedArgs := pgx.NamedArgs{
+		"item_id": itemID,
+	}
+
+	rows, err := a.DB.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		award, err := scanAward(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		awards = append(awards, award)
+	}
+
+	return awards, rows.Err()
+}
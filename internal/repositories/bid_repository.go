@@ -0,0 +1,273 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Columns selected for every bid query, in the order scanBid expects them
+const bidColumns = `id, item_id, bidder_id, amount, kind, created_at, quantity`
+
+// Highest bid for an item, the earliest bid wins a tie
+const highestBidQuery = `SELECT ` + bidColumns + ` FROM bids WHERE item_id = @item_id ORDER BY amount DESC, created_at ASC, id ASC LIMIT 1`
+
+// Query for the leading bid of a reverse auction, the lowest amount wins and ties go to the earliest bid
+const lowestBidQuery = `SELECT ` + bidColumns + ` FROM bids WHERE item_id = @item_id ORDER BY amount ASC, created_at ASC, id ASC LIMIT 1`
+
+type BidRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewBidRepository(DB *pgxpool.Pool) *BidRepository {
+	return &BidRepository{DB}
+}
+
+// Scan a single bid row selected with bidColumns
+func scanBid(row pgx.Row) (models.Bid, error) {
+	var bid models.Bid
+
+	err := row.Scan(&bid.ID, &bid.ItemID, &bid.BidderID, &bid.Amount, &bid.Kind, &bid.CreatedAt, &bid.Quantity)
+
+	return bid, err
+}
+
+// Create a new bid inside an existing transaction
+func (b *BidRepository) CreateBid(tx pgx.Tx, bid models.Bid) (models.Bid, error) {
+	if bid.Kind == "" {
+		bid.Kind = models.BidKindBid
+	}
+
+	if bid.Quantity == 0 {
+		bid.Quantity = 1
+	}
+
+	query := `INSERT INTO bids (item_id, bidder_id, amount, kind, quantity) VALUES (@item_id, @bidder_id, @amount, @kind, @quantity) RETURNING ` + bidColumns
+	namedArgs := pgx.NamedArgs{
+		"item_id":   bid.ItemID,
+		"bidder_id": bid.BidderID,
+		"amount":    bid.Amount,
+		"kind":      bid.Kind,
+		"quantity":  bid.Quantity,
+	}
+
+	return scanBid(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve a page of bids for an item, newest first
+func (b *BidRepository) GetBidsByItemID(itemID int64, limit, offset int) ([]models.Bid, error) {
+	var bids []models.Bid
+
+	query := `SELECT ` + bidColumns + ` FROM bids WHERE item_id = @item_id ORDER BY created_at DESC, id DESC LIMIT @limit OFFSET @offset`
+	namedArgs := pgx.NamedArgs{
+		"item_id": itemID,
+		"limit":   limit,
+		"offset":  offset,
+	}
+
+	rows, err := b.DB.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		bid, err := scanBid(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		bids = append(bids, bid)
+	}
+
+	return bids, rows.Err()
+}
+
+// Count all bids placed on an item
+func (b *BidRepository) CountBidsByItemID(itemID int64) (int64, error) {
+	var count int64
+
+	query := `SELECT count(*) FROM bids WHERE item_id = @item_id`
+	namedArgs := pgx.NamedArgs{
+		"item_id": itemID,
+	}
+
+	err := b.DB.QueryRow(context.Background(), query, namedArgs).Scan(&count)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// Retrieve the leading bid for an item, the lowest rather than the highest when lowest is set
+func (b *BidRepository) GetLeadingBid(itemID int64, lowest bool) (models.Bid, error) {
+	query := highestBidQuery
+
+	if lowest {
+		query = lowestBidQuery
+	}
+
+	namedArgs := pgx.NamedArgs{
+		"item_id": itemID,
+	}
+
+	return scanBid(b.DB.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve the lowest bid for an item inside an existing transaction
+func (b *BidRepository) GetLowestBidTx(tx pgx.Tx, itemID int64) (models.Bid, error) {
+	namedArgs := pgx.NamedArgs{
+		"item_id": itemID,
+	}
+
+	return scanBid(tx.QueryRow(context.Background(), lowestBidQuery, namedArgs))
+}
+
+// Retrieve the IDs of everyone who bid on an item
+func (b *BidRepository) GetItemBidderIDs(itemID int64) ([]int64, error) {
+	var bidderIDs []int64
+
+	query := `SELECT DISTINCT bidder_id FROM bids WHERE item_id = @item_id ORDER BY bidder_id`
+	namedArgs := pgx.NamedArgs{
+		"item_id": itemID,
+	}
+
+	rows, err := b.DB.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var bidderID int64
+
+		err := rows.Scan(&bidderID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		bidderIDs = append(bidderIDs, bidderID)
+	}
+
+	return bidderIDs, rows.Err()
+}
+
+// Retrieve a single bid on an item
+func (b *BidRepository) GetItemBid(itemID int64, bidID int64) (models.Bid, error) {
+	query := `SELECT ` + bidColumns + ` FROM bids WHERE id = @id AND item_id = @item_id`
+	namedArgs := pgx.NamedArgs{
+		"id":      bidID,
+		"item_id": itemID,
+	}
+
+	return scanBid(b.DB.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve a single bid on an item inside an existing transaction
+func (b *BidRepository) GetItemBidTx(tx pgx.Tx, itemID int64, bidID int64) (models.Bid, error) {
+	query := `SELECT ` + bidColumns + ` FROM bids WHERE id = @id AND item_id = @item_id`
+	namedArgs := pgx.NamedArgs{
+		"id":      bidID,
+		"item_id": itemID,
+	}
+
+	return scanBid(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve the highest bid for an item inside an existing transaction
+func (b *BidRepository) GetHighestBidTx(tx pgx.Tx, itemID int64) (models.Bid, error) {
+	namedArgs := pgx.NamedArgs{
+		"item_id": itemID,
+	}
+
+	return scanBid(tx.QueryRow(context.Background(), highestBidQuery, namedArgs))
+}
+
+// Check whether any bid has been placed on an item inside an existing transaction
+func (b *BidRepository) HasBids(tx pgx.Tx, itemID int64) (bool, error) {
+	var exists bool
+
+	query := `SELECT EXISTS (SELECT 1 FROM bids WHERE item_id = @item_id)`
+	namedArgs := pgx.NamedArgs{
+		"item_id": itemID,
+	}
+
+	err := tx.QueryRow(context.Background(), query, namedArgs).Scan(&exists)
+
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// Retrieve the bid of a given kind a bidder holds on an item inside an existing transaction
+func (b *BidRepository) GetBidderBidTx(tx pgx.Tx, itemID int64, bidderID int64, kind models.BidKind) (models.Bid, error) {
+	query := `SELECT ` + bidColumns + ` FROM bids WHERE item_id = @item_id AND bidder_id = @bidder_id AND kind = @kind ORDER BY id DESC LIMIT 1`
+	namedArgs := pgx.NamedArgs{
+		"item_id":   itemID,
+		"bidder_id": bidderID,
+		"kind":      kind,
+	}
+
+	return scanBid(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Replace the amount of a bid inside an existing transaction.
+// The bid takes the current time so a revised bid ranks behind bids already standing at the same amount.
+func (b *BidRepository) ReviseBid(tx pgx.Tx, bidID int64, amount models.Money) (models.Bid, error) {
+	query := `UPDATE bids SET amount = @amount, created_at = now() WHERE id = @id RETURNING ` + bidColumns
+	namedArgs := pgx.NamedArgs{
+		"id":     bidID,
+		"amount": amount,
+	}
+
+	return scanBid(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve the highest bids for an item inside an existing transaction, ranked the same way as the leading bid.
+// A limit of 0 returns every bid.
+func (b *BidRepository) GetTopBidsTx(tx pgx.Tx, itemID int64, limit int) ([]models.Bid, error) {
+	var bids []models.Bid
+	var limitArg any
+
+	if limit > 0 {
+		limitArg = limit
+	}
+
+	query := `SELECT ` + bidColumns + ` FROM bids WHERE item_id = @item_id ORDER BY amount DESC, created_at ASC, id ASC LIMIT @limit`
+	namedArgs := pgx.NamedArgs{
+		"item_id": itemID,
+		"limit":   limitArg,
+	}
+
+	rows, err := tx.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		bid, err := scanBid(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		bids = append(bids, bid)
+	}
+
+	return bids, rows.Err()
+}
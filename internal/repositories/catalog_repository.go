@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Columns selected for every catalog query, in the order scanCatalog expects them
+const catalogColumns = `id, name, seller_id, starts_at, first_lot_ends_at, stagger_interval, created_at`
+
+type CatalogRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewCatalogRepository(DB *pgxpool.Pool) *CatalogRepository {
+	return &CatalogRepository{DB}
+}
+
+func scanCatalog(row pgx.Row) (models.Catalog, error) {
+	var catalog models.Catalog
+
+	err := row.Scan(&catalog.ID, &catalog.Name, &catalog.SellerID, &catalog.StartsAt, &catalog.FirstLotEndsAt, &catalog.StaggerInterval, &catalog.CreatedAt)
+
+	return catalog, err
+}
+
+// Create a catalog inside an existing transaction
+func (c *CatalogRepository) CreateCatalog(tx pgx.Tx, catalog models.Catalog) (models.Catalog, error) {
+	query := `INSERT INTO catalogs (name, seller_id, starts_at, first_lot_ends_at, stagger_interval) VALUES (@name, @seller_id, @starts_at, @first_lot_ends_at, @stagger_interval) RETURNING ` + catalogColumns
+	namedArgs := pgx.NamedArgs{
+		"name":              catalog.Name,
+		"seller_id":         catalog.SellerID,
+		"starts_at":         catalog.StartsAt,
+		"first_lot_ends_at": catalog.FirstLotEndsAt,
+		"stagger_interval":  catalog.StaggerInterval,
+	}
+
+	return scanCatalog(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve every catalog, the soonest to start first
+func (c *CatalogRepository) GetCatalogs() ([]models.Catalog, error) {
+	var catalogs []models.Catalog
+
+	query := `SELECT ` + catalogColumns + ` FROM catalogs ORDER BY starts_at, id`
+
+	rows, err := c.DB.Query(context.Background(), query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		catalog, err := scanCatalog(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		catalogs = append(catalogs, catalog)
+	}
+
+	return catalogs, rows.Err()
+}
+
+// Retrieve a single catalog by its ID
+func (c *CatalogRepository) GetCatalogByID(id int64) (models.Catalog, error) {
+	query := `SELECT ` + catalogColumns + ` FROM catalogs WHERE id = @id`
+	namedArgs := pgx.NamedArgs{
+		"id": id,
+	}
+
+	return scanCatalog(c.DB.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve a single catalog by its ID and lock its row until the transaction ends
+func (c *CatalogRepository) GetCatalogByIDForUpdate(tx pgx.Tx, id int64) (models.Catalog, error) {
+	query := `SELECT ` + catalogColumns + ` FROM catalogs WHERE id = @id FOR UPDATE`
+	namedArgs := pgx.NamedArgs{
+		"id": id,
+	}
+
+	return scanCatalog(tx.QueryRow(context.Background(), query, namedArgs))
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ExchangeRateRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewExchangeRateRepository(DB *pgxpool.Pool) *ExchangeRateRepository {
+	return &ExchangeRateRepository{DB}
+}
+
+// Retrieve every exchange rate, the base currency first
+func (e *ExchangeRateRepository) GetExchangeRates() ([]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+
+	query := `SELECT currency, rate::text, is_base, updated_at FROM exchange_rates ORDER BY is_base DESC, currency`
+
+	rows, err := e.DB.Query(context.Background(), query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var rate models.ExchangeRate
+
+		err := rows.Scan(&rate.Currency, &rate.Rate, &rate.IsBase, &rate.UpdatedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
+
+// Replace every exchange rate with a new set, the base currency is stored with a rate of 1
+func (e *ExchangeRateRepository) ReplaceExchangeRates(tx pgx.Tx, rates models.ExchangeRates) error {
+	_, err := tx.Exec(context.Background(), `DELETE FROM exchange_rates`)
+
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO exchange_rates (currency, rate, is_base) VALUES (@currency, @rate::numeric, @is_base)`
+
+	_, err = tx.Exec(context.Background(), query, pgx.NamedArgs{"currency": rates.Base, "rate": "1", "is_base": true})
+
+	if err != nil {
+		return err
+	}
+
+	for currency, rate := range rates.Rates {
+		if currency == rates.Base {
+			continue
+		}
+
+		_, err = tx.Exec(context.Background(), query, pgx.NamedArgs{"currency": currency, "rate": rate.String(), "is_base": false})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IncrementRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewIncrementRepository(DB *pgxpool.Pool) *IncrementRepository {
+	return &IncrementRepository{DB}
+}
+
+// Retrieve the increment table of every category
+func (i *IncrementRepository) GetCategoryIncrements() ([]models.CategoryIncrements, error) {
+	var tables []models.CategoryIncrements
+
+	query := `SELECT category, tiers FROM category_increments ORDER BY category`
+
+	rows, err := i.DB.Query(context.Background(), query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var table models.CategoryIncrements
+
+		err := rows.Scan(&table.Category, &table.Tiers)
+
+		if err != nil {
+			return nil, err
+		}
+
+		tables = append(tables, table)
+	}
+
+	return tables, rows.Err()
+}
+
+// Retrieve the increment table of a single category
+func (i *IncrementRepository) GetCategoryIncrementsByCategory(category string) (models.IncrementTable, error) {
+	var tiers models.IncrementTable
+
+	query := `SELECT tiers FROM category_increments WHERE category = @category`
+	namedArgs := pgx.NamedArgs{
+		"category": category,
+	}
+
+	err := i.DB.QueryRow(context.Background(), query, namedArgs).Scan(&tiers)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tiers, nil
+}
+
+// Create or replace the increment table of a category
+func (i *IncrementRepository) UpsertCategoryIncrements(table models.CategoryIncrements) (models.CategoryIncrements, error) {
+	var saved models.CategoryIncrements
+
+	query := `INSERT INTO category_increments (category, tiers) VALUES (@category, @tiers)
+		ON CONFLICT (category) DO UPDATE SET tiers = EXCLUDED.tiers, updated_at = now()
+		RETURNING category, tiers`
+	namedArgs := pgx.NamedArgs{
+		"category": table.Category,
+		"tiers":    table.Tiers,
+	}
+
+	err := i.DB.QueryRow(context.Background(), query, namedArgs).Scan(&saved.Category, &saved.Tiers)
+
+	if err != nil {
+		return saved, err
+	}
+
+	return saved, nil
+}
+
+// Delete the increment table of a category, its items fall back to the default table
+func (i *IncrementRepository) DeleteCategoryIncrements(category string) (int64, error) {
+	query := `DELETE FROM category_increments WHERE category = @category`
+	namedArgs := pgx.NamedArgs{
+		"category": category,
+	}
+
+	tag, err := i.DB.Exec(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -2,12 +2,17 @@ package repositories
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/bangueco/auction-api/internal/models"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// Columns selected for every item query, in the order scanItem expects them
+const itemColumns = `id, item_name, bid_amount, auctioned_by, starts_at, ends_at, status, winner_id, winning_bid_id, closed_at, original_ends_at, extension_count, reserve_price, bid_count, buy_now_price, category, increment_table, currency, auction_type, floor_price, price_drop, price_drop_interval, hammer_price, quantity, pricing_rule, lot_id, lot_position`
+
 type ItemRepository struct {
 	DB *pgxpool.Pool
 }
@@ -16,11 +21,29 @@ func NewItemRepository(DB *pgxpool.Pool) *ItemRepository {
 	return &ItemRepository{DB}
 }
 
+// Scan a single item row selected with itemColumns
+func scanItem(row pgx.Row) (models.Item, error) {
+	var item models.Item
+
+	err := row.Scan(&item.ID, &item.ItemName, &item.BidAmount, &item.AuctionedBy, &item.StartsAt, &item.EndsAt, &item.Status, &item.WinnerID, &item.WinningBidID, &item.ClosedAt, &item.OriginalEndsAt, &item.ExtensionCount, &item.ReservePrice, &item.BidCount, &item.BuyNowPrice, &item.Category, &item.IncrementTable, &item.Currency, &item.AuctionType, &item.FloorPrice, &item.PriceDrop, &item.PriceDropInterval, &item.HammerPrice, &item.Quantity, &item.PricingRule, &item.LotID, &item.LotPosition)
+
+	return item, err
+}
+
+// Store an empty increment table as NULL so the item falls back to its category or the default table
+func incrementTableArg(table models.IncrementTable) any {
+	if len(table) == 0 {
+		return nil
+	}
+
+	return table
+}
+
 // Retrieve all items from the database
 func (i *ItemRepository) GetItems() ([]models.Item, error) {
 	var items []models.Item
 
-	query := `SELECT * FROM items`
+	query := `SELECT ` + itemColumns + ` FROM items`
 
 	rows, err := i.DB.Query(context.Background(), query)
 
@@ -28,10 +51,10 @@ func (i *ItemRepository) GetItems() ([]models.Item, error) {
 		return nil, err
 	}
 
-	for rows.Next() {
-		var item models.Item
+	defer rows.Close()
 
-		err := rows.Scan(&item.ID, &item.ItemName, &item.BidAmount, &item.AuctionedBy)
+	for rows.Next() {
+		item, err := scanItem(rows)
 
 		if err != nil {
 			return nil, err
@@ -40,80 +63,520 @@ func (i *ItemRepository) GetItems() ([]models.Item, error) {
 		items = append(items, item)
 	}
 
-	return items, nil
+	return items, rows.Err()
 }
 
 // Retrieve a single item from the database by its ID
 func (i *ItemRepository) GetItemByID(id int64) (models.Item, error) {
-	var item models.Item
+	query := `SELECT ` + itemColumns + ` FROM items WHERE id = @id`
+	namedArgs := pgx.NamedArgs{
+		"id": id,
+	}
+
+	return scanItem(i.DB.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Create a new item in the database
+func (i *ItemRepository) CreateItem(item models.Item) (models.Item, error) {
+	query, namedArgs := createItemQuery(item)
+
+	return scanItem(i.DB.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Create a new item inside an existing transaction
+func (i *ItemRepository) CreateItemTx(tx pgx.Tx, item models.Item) (models.Item, error) {
+	query, namedArgs := createItemQuery(item)
+
+	return scanItem(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Build the insert statement shared by CreateItem and CreateItemTx
+func createItemQuery(item models.Item) (string, pgx.NamedArgs) {
+	query := `INSERT INTO items (item_name, bid_amount, auctioned_by, starts_at, ends_at, original_ends_at, status, reserve_price, buy_now_price, category, increment_table, currency, auction_type, floor_price, price_drop, price_drop_interval, quantity, pricing_rule) VALUES (@item_name, @bid_amount, @auctioned_by, @starts_at, @ends_at, @ends_at, @status, @reserve_price, @buy_now_price, @category, @increment_table, (SELECT currency FROM users WHERE id = @auctioned_by), @auction_type, @floor_price, @price_drop, @price_drop_interval, @quantity, @pricing_rule) RETURNING ` + itemColumns
+	namedArgs := pgx.NamedArgs{
+		"item_name":           item.ItemName,
+		"bid_amount":          item.BidAmount,
+		"auctioned_by":        item.AuctionedBy,
+		"starts_at":           item.StartsAt,
+		"ends_at":             item.EndsAt,
+		"status":              item.Status,
+		"reserve_price":       item.ReservePrice,
+		"buy_now_price":       item.BuyNowPrice,
+		"category":            item.Category,
+		"increment_table":     incrementTableArg(item.IncrementTable),
+		"auction_type":        item.AuctionType,
+		"floor_price":         item.FloorPrice,
+		"price_drop":          item.PriceDrop,
+		"price_drop_interval": item.PriceDropInterval,
+		"quantity":            item.Quantity,
+		"pricing_rule":        item.PricingRule,
+	}
+
+	return query, namedArgs
+}
+
+// Update an existing item inside an existing transaction
+func (i *ItemRepository) UpdateItem(tx pgx.Tx, itemID int64, item models.Item) (models.Item, error) {
+	query := `UPDATE items SET item_name = @item_name, bid_amount = @bid_amount, auctioned_by = @auctioned_by, starts_at = @starts_at, ends_at = @ends_at, original_ends_at = @ends_at, extension_count = 0, reserve_price = @reserve_price, buy_now_price = @buy_now_price, category = @category, increment_table = @increment_table, auction_type = @auction_type, floor_price = @floor_price, price_drop = @price_drop, price_drop_interval = @price_drop_interval, quantity = @quantity, pricing_rule = @pricing_rule WHERE id = @id RETURNING ` + itemColumns
+	namedArgs := pgx.NamedArgs{
+		"id":                  itemID,
+		"item_name":           item.ItemName,
+		"bid_amount":          item.BidAmount,
+		"auctioned_by":        item.AuctionedBy,
+		"starts_at":           item.StartsAt,
+		"ends_at":             item.EndsAt,
+		"reserve_price":       item.ReservePrice,
+		"buy_now_price":       item.BuyNowPrice,
+		"category":            item.Category,
+		"increment_table":     incrementTableArg(item.IncrementTable),
+		"auction_type":        item.AuctionType,
+		"floor_price":         item.FloorPrice,
+		"price_drop":          item.PriceDrop,
+		"price_drop_interval": item.PriceDropInterval,
+		"quantity":            item.Quantity,
+		"pricing_rule":        item.PricingRule,
+	}
+
+	return scanItem(tx.QueryRow(context.Background(), query, namedArgs))
+}
 
-	query := `SELECT * FROM items WHERE id = @id`
+// Delete an item by its ID inside an existing transaction
+func (i *ItemRepository) DeleteItem(tx pgx.Tx, id int64) error {
+	query := `DELETE FROM items WHERE id = @id`
 	namedArgs := pgx.NamedArgs{
 		"id": id,
 	}
 
-	err := i.DB.QueryRow(context.Background(), query, namedArgs).Scan(&item.ID, &item.ItemName, &item.BidAmount, &item.AuctionedBy)
+	_, err := tx.Exec(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Retrieve a single item by its ID and lock its row until the transaction ends
+func (i *ItemRepository) GetItemByIDForUpdate(tx pgx.Tx, id int64) (models.Item, error) {
+	query := `SELECT ` + itemColumns + ` FROM items WHERE id = @id FOR UPDATE`
+	namedArgs := pgx.NamedArgs{
+		"id": id,
+	}
+
+	return scanItem(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Record a newly accepted bid on an item inside an existing transaction.
+// The bid is counted and the current price only ever moves up, so bids recorded out of order
+// while resolving proxy bids cannot lower it.
+func (i *ItemRepository) RecordBid(tx pgx.Tx, itemID int64, bidAmount models.Money) error {
+	query := `UPDATE items SET bid_amount = GREATEST(bid_amount, @bid_amount), bid_count = bid_count + 1 WHERE id = @id`
+	namedArgs := pgx.NamedArgs{
+		"id":         itemID,
+		"bid_amount": bidAmount,
+	}
+
+	_, err := tx.Exec(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Record a newly accepted bid on a reverse auction inside an existing transaction, the current price only ever moves down
+func (i *ItemRepository) RecordLowerBid(tx pgx.Tx, itemID int64, bidAmount models.Money) error {
+	query := `UPDATE items SET bid_amount = LEAST(bid_amount, @bid_amount), bid_count = bid_count + 1 WHERE id = @id`
+	namedArgs := pgx.NamedArgs{
+		"id":         itemID,
+		"bid_amount": bidAmount,
+	}
+
+	_, err := tx.Exec(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Update the status of an item inside an existing transaction
+func (i *ItemRepository) UpdateItemStatus(tx pgx.Tx, itemID int64, status models.ItemStatus) (models.Item, error) {
+	query := `UPDATE items SET status = @status WHERE id = @id RETURNING ` + itemColumns
+	namedArgs := pgx.NamedArgs{
+		"id":     itemID,
+		"status": status,
+	}
+
+	return scanItem(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Move every scheduled item whose start time has passed to live
+func (i *ItemRepository) ActivateScheduledItems(now time.Time) (int64, error) {
+	query := `UPDATE items SET status = 'live' WHERE status = 'scheduled' AND starts_at <= @now AND ends_at > @now`
+	namedArgs := pgx.NamedArgs{
+		"now": now,
+	}
+
+	tag, err := i.DB.Exec(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// Retrieve every dutch auction that is taking bids right now
+func (i *ItemRepository) GetLiveDutchItems(now time.Time) ([]models.Item, error) {
+	var items []models.Item
+
+	query := `SELECT ` + itemColumns + ` FROM items WHERE auction_type = 'dutch' AND status IN ('scheduled', 'live') AND starts_at <= @now AND ends_at > @now ORDER BY id`
+	namedArgs := pgx.NamedArgs{
+		"now": now,
+	}
+
+	rows, err := i.DB.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanItem(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// Lock a batch of items whose auction has run past its end time.
+// Rows already locked by another transaction are skipped so several instances can close auctions side by side.
+func (i *ItemRepository) GetExpiredItemsForUpdate(tx pgx.Tx, now time.Time, limit int) ([]models.Item, error) {
+	var items []models.Item
+
+	query := `SELECT ` + itemColumns + ` FROM items WHERE status IN ('scheduled', 'live') AND ends_at <= @now ORDER BY ends_at LIMIT @limit FOR UPDATE SKIP LOCKED`
+	namedArgs := pgx.NamedArgs{
+		"now":   now,
+		"limit": limit,
+	}
+
+	rows, err := tx.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanItem(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// Record the outcome of an auction inside an existing transaction.
+// When the item is the auction item of a lot, the lot's items take on the same outcome.
+func (i *ItemRepository) CloseItem(tx pgx.Tx, itemID int64, status models.ItemStatus, winnerID, winningBidID *int64, hammerPrice *models.Money, closedAt time.Time) (models.Item, error) {
+	query := `UPDATE items SET status = @status, winner_id = @winner_id, winning_bid_id = @winning_bid_id, hammer_price = @hammer_price, closed_at = @closed_at WHERE id = @id RETURNING ` + itemColumns
+	namedArgs := pgx.NamedArgs{
+		"id":             itemID,
+		"status":         status,
+		"winner_id":      winnerID,
+		"winning_bid_id": winningBidID,
+		"hammer_price":   hammerPrice,
+		"closed_at":      closedAt,
+	}
+
+	item, err := scanItem(tx.QueryRow(context.Background(), query, namedArgs))
 
 	if err != nil {
 		return item, err
 	}
 
-	return item, nil
+	return item, i.CloseLotItems(tx, itemID, status, winnerID, closedAt)
 }
 
-// Create a new item in the database
-func (i *ItemRepository) CreateItem(item models.Item) (models.Item, error) {
-	var newItem models.Item
+// Push back the end time of an item and count the extension inside an existing transaction
+func (i *ItemRepository) ExtendItem(tx pgx.Tx, itemID int64, endsAt time.Time) (models.Item, error) {
+	query := `UPDATE items SET ends_at = @ends_at, extension_count = extension_count + 1 WHERE id = @id RETURNING ` + itemColumns
+	namedArgs := pgx.NamedArgs{
+		"id":      itemID,
+		"ends_at": endsAt,
+	}
+
+	return scanItem(tx.QueryRow(context.Background(), query, namedArgs))
+}
 
-	query := `INSERT INTO items (item_name, bid_amount, auctioned_by) VALUES (@item_name, @bid_amount, @auctioned_by) RETURNING id, item_name, bid_amount, auctioned_by`
+// Hand a closed auction to a different winning bid inside an existing transaction
+func (i *ItemRepository) AwardItem(tx pgx.Tx, itemID int64, bid models.Bid) (models.Item, error) {
+	query := `UPDATE items SET status = @status, winner_id = @winner_id, winning_bid_id = @winning_bid_id, hammer_price = @hammer_price WHERE id = @id RETURNING ` + itemColumns
 	namedArgs := pgx.NamedArgs{
-		"item_name":    item.ItemName,
-		"bid_amount":   item.BidAmount,
-		"auctioned_by": item.AuctionedBy,
+		"id":             itemID,
+		"status":         models.ItemStatusSold,
+		"winner_id":      bid.BidderID,
+		"winning_bid_id": bid.ID,
+		"hammer_price":   bid.Amount,
 	}
 
-	err := i.DB.QueryRow(context.Background(), query, namedArgs).Scan(&newItem.ID, &newItem.ItemName, &newItem.BidAmount, &newItem.AuctionedBy)
+	return scanItem(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve the items grouped in a lot, in lot order
+func (i *ItemRepository) GetLotItems(lotID int64) ([]models.Item, error) {
+	var items []models.Item
+
+	query := `SELECT ` + itemColumns + ` FROM items WHERE lot_id = @lot_id ORDER BY lot_position`
+	namedArgs := pgx.NamedArgs{
+		"lot_id": lotID,
+	}
+
+	rows, err := i.DB.Query(context.Background(), query, namedArgs)
 
 	if err != nil {
-		return newItem, err
+		return nil, err
 	}
 
-	return newItem, nil
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanItem(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
 }
 
-// Update an existing item in the database
-func (i *ItemRepository) UpdateItem(itemID int64, item models.Item) (models.Item, error) {
-	var updatedItem models.Item
+// Retrieve the items with the given IDs, in no particular order
+func (i *ItemRepository) GetItemsByIDs(ids []int64) ([]models.Item, error) {
+	var items []models.Item
 
-	query := `UPDATE items SET item_name = @item_name, bid_amount = @bid_amount, auctioned_by = @auctioned_by WHERE id = @id RETURNING id, item_name, bid_amount, auctioned_by`
+	query := `SELECT ` + itemColumns + ` FROM items WHERE id = ANY(@ids)`
 	namedArgs := pgx.NamedArgs{
-		"id":           itemID,
-		"item_name":    item.ItemName,
-		"bid_amount":   item.BidAmount,
-		"auctioned_by": item.AuctionedBy,
+		"ids": ids,
 	}
 
-	err := i.DB.QueryRow(context.Background(), query, namedArgs).Scan(&updatedItem.ID, &updatedItem.ItemName, &updatedItem.BidAmount, &updatedItem.AuctionedBy)
+	rows, err := i.DB.Query(context.Background(), query, namedArgs)
 
 	if err != nil {
-		return updatedItem, err
+		return nil, err
 	}
 
-	return updatedItem, nil
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanItem(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
 }
 
-// Delete an item from the database by its ID
-func (i *ItemRepository) DeleteItem(id int64) error {
-	query := `DELETE FROM items WHERE id = @id`
+// Retrieve the items grouped in any of the given lots, in lot order
+func (i *ItemRepository) GetItemsByLotIDs(lotIDs []int64) ([]models.Item, error) {
+	var items []models.Item
+
+	query := `SELECT ` + itemColumns + ` FROM items WHERE lot_id = ANY(@lot_ids) ORDER BY lot_id, lot_position`
 	namedArgs := pgx.NamedArgs{
-		"id": id,
+		"lot_ids": lotIDs,
 	}
 
-	_, err := i.DB.Exec(context.Background(), query, namedArgs)
+	rows, err := i.DB.Query(context.Background(), query, namedArgs)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanItem(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// Take every item out of a lot inside an existing transaction
+func (i *ItemRepository) ReleaseLotItems(tx pgx.Tx, lotID int64) error {
+	query := `UPDATE items SET lot_id = NULL, lot_position = NULL WHERE lot_id = @lot_id`
+	namedArgs := pgx.NamedArgs{
+		"lot_id": lotID,
+	}
+
+	_, err := tx.Exec(context.Background(), query, namedArgs)
+
+	return err
+}
+
+// Take every item out of the lot auctioned by an item inside an existing transaction.
+// It does nothing when the item is not the auction item of a lot.
+func (i *ItemRepository) ReleaseLotItemsByItemID(tx pgx.Tx, lotItemID int64) error {
+	query := `UPDATE items SET lot_id = NULL, lot_position = NULL WHERE lot_id = (SELECT id FROM lots WHERE item_id = @item_id)`
+	namedArgs := pgx.NamedArgs{
+		"item_id": lotItemID,
+	}
+
+	_, err := tx.Exec(context.Background(), query, namedArgs)
+
+	return err
+}
+
+// Check whether an item is the auction item of a lot that closes in a catalog inside an existing transaction
+func (i *ItemRepository) IsCatalogLotItem(tx pgx.Tx, itemID int64) (bool, error) {
+	var exists bool
+
+	query := `SELECT EXISTS (SELECT 1 FROM lots WHERE item_id = @item_id AND catalog_id IS NOT NULL)`
+	namedArgs := pgx.NamedArgs{
+		"item_id": itemID,
+	}
+
+	err := tx.QueryRow(context.Background(), query, namedArgs).Scan(&exists)
+
+	return exists, err
+}
+
+// Put an item into a lot at the given position inside an existing transaction
+func (i *ItemRepository) AssignItemToLot(tx pgx.Tx, itemID int64, lotID int64, position int) error {
+	query := `UPDATE items SET lot_id = @lot_id, lot_position = @lot_position WHERE id = @id`
+	namedArgs := pgx.NamedArgs{
+		"id":           itemID,
+		"lot_id":       lotID,
+		"lot_position": position,
+	}
+
+	_, err := tx.Exec(context.Background(), query, namedArgs)
+
+	return err
+}
+
+// Give the items of a lot the outcome of the lot's auction inside an existing transaction.
+// It does nothing when the item is not the auction item of a lot.
+func (i *ItemRepository) CloseLotItems(tx pgx.Tx, lotItemID int64, status models.ItemStatus, winnerID *int64, closedAt time.Time) error {
+	query := `UPDATE items SET status = @status, winner_id = @winner_id, closed_at = @closed_at WHERE lot_id = (SELECT id FROM lots WHERE item_id = @item_id)`
+	namedArgs := pgx.NamedArgs{
+		"item_id":   lotItemID,
+		"status":    status,
+		"winner_id": winnerID,
+		"closed_at": closedAt,
+	}
+
+	_, err := tx.Exec(context.Background(), query, namedArgs)
+
+	return err
+}
+
+// Give an item a new auction window and schedule it inside an existing transaction
+func (i *ItemRepository) ScheduleItem(tx pgx.Tx, itemID int64, startsAt time.Time, endsAt time.Time) (models.Item, error) {
+	query := `UPDATE items SET starts_at = @starts_at, ends_at = @ends_at, original_ends_at = @ends_at, extension_count = 0, status = 'scheduled' WHERE id = @id RETURNING ` + itemColumns
+	namedArgs := pgx.NamedArgs{
+		"id":        itemID,
+		"starts_at": startsAt,
+		"ends_at":   endsAt,
+	}
+
+	return scanItem(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Push back the lots that close after a lot in the same catalog inside an existing transaction.
+// Each later lot keeps closing at least its catalog stagger after the one before it, lots already
+// ending late enough are left alone, and so are lots that have ended but were not closed yet.
+// The later lots are locked in closing order so two extensions in the same catalog always lock rows in the same order.
+func (i *ItemRepository) PushBackCatalogLots(tx pgx.Tx, itemID int64, endsAt time.Time, now time.Time) ([]models.Item, error) {
+	var items []models.Item
+
+	lockQuery := `SELECT later.id FROM lots AS this_lot
+		JOIN lots AS later_lot ON later_lot.catalog_id = this_lot.catalog_id AND later_lot.catalog_position > this_lot.catalog_position
+		JOIN items AS later ON later.id = later_lot.item_id
+		WHERE this_lot.item_id = @item_id AND later.status IN ('scheduled', 'live') AND later.ends_at > @now
+		ORDER BY later_lot.catalog_position
+		FOR UPDATE OF later`
+	namedArgs := pgx.NamedArgs{
+		"item_id": itemID,
+		"ends_at": endsAt,
+		"now":     now,
+	}
+
+	rows, err := tx.Query(context.Background(), lockQuery, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows.Close()
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	query := `UPDATE items AS later
+		SET ends_at = @ends_at + (later_lot.catalog_position - this_lot.catalog_position) * catalogs.stagger_interval * interval '1 second'
+		FROM lots AS this_lot
+		JOIN catalogs ON catalogs.id = this_lot.catalog_id
+		JOIN lots AS later_lot ON later_lot.catalog_id = this_lot.catalog_id AND later_lot.catalog_position > this_lot.catalog_position
+		WHERE this_lot.item_id = @item_id AND later.id = later_lot.item_id AND later.status IN ('scheduled', 'live') AND later.ends_at > @now
+		AND later.ends_at < @ends_at + (later_lot.catalog_position - this_lot.catalog_position) * catalogs.stagger_interval * interval '1 second'
+		RETURNING ` + prefixColumns("later", itemColumns)
+
+	rows, err = tx.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanItem(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// Qualify every column of a column list with a table alias
+func prefixColumns(alias string, columns string) string {
+	fields := strings.Split(columns, ", ")
+
+	for idx, field := range fields {
+		fields[idx] = alias + "." + field
+	}
+
+	return strings.Join(fields, ", ")
 }
@@ -0,0 +1,342 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Columns selected for every ledger account query, in the order scanLedgerAccount expects them
+const ledgerAccountColumns = `id, user_id, kind, currency, created_at`
+
+// Columns selected for every hold query, in the order scanHold expects them
+const holdColumns = `id, user_id, item_id, currency, amount, status, created_at, updated_at`
+
+type LedgerRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewLedgerRepository(DB *pgxpool.Pool) *LedgerRepository {
+	return &LedgerRepository{DB}
+}
+
+func scanLedgerAccount(row pgx.Row) (models.LedgerAccount, error) {
+	var account models.LedgerAccount
+
+	err := row.Scan(&account.ID, &account.UserID, &account.Kind, &account.Currency, &account.CreatedAt)
+
+	return account, err
+}
+
+func scanHold(row pgx.Row) (models.Hold, error) {
+	var hold models.Hold
+
+	err := row.Scan(&hold.ID, &hold.UserID, &hold.ItemID, &hold.Currency, &hold.Amount, &hold.Status, &hold.CreatedAt, &hold.UpdatedAt)
+
+	return hold, err
+}
+
+// Retrieve an account inside an existing transaction, opening it when it does not exist yet.
+// The account row is not locked, see LockAccount.
+func (l *LedgerRepository) GetOrCreateAccount(tx pgx.Tx, userID *int64, kind models.AccountKind, currency string) (models.LedgerAccount, error) {
+	namedArgs := pgx.NamedArgs{
+		"user_id":  userID,
+		"kind":     kind,
+		"currency": currency,
+	}
+
+	_, err := tx.Exec(context.Background(), `INSERT INTO ledger_accounts (user_id, kind, currency) VALUES (@user_id, @kind, @currency) ON CONFLICT (user_id, kind, currency) DO NOTHING`, namedArgs)
+
+	if err != nil {
+		return models.LedgerAccount{}, err
+	}
+
+	query := `SELECT ` + ledgerAccountColumns + ` FROM ledger_accounts WHERE user_id IS NOT DISTINCT FROM @user_id AND kind = @kind AND currency = @currency`
+
+	return scanLedgerAccount(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Lock an account for the rest of the transaction so its balance cannot be spent twice.
+// NO KEY UPDATE leaves other transactions free to post lines to the account while it is locked.
+func (l *LedgerRepository) LockAccount(tx pgx.Tx, accountID int64) error {
+	_, err := tx.Exec(context.Background(), `SELECT id FROM ledger_accounts WHERE id = @id FOR NO KEY UPDATE`, pgx.NamedArgs{"id": accountID})
+
+	return err
+}
+
+// Sum the lines of an account inside an existing transaction
+func (l *LedgerRepository) GetAccountBalance(tx pgx.Tx, accountID int64) (models.Money, error) {
+	var balance models.Money
+
+	query := `SELECT coalesce(sum(credit - debit), 0)::bigint FROM journal_lines WHERE account_id = @account_id`
+	namedArgs := pgx.NamedArgs{
+		"account_id": accountID,
+	}
+
+	err := tx.QueryRow(context.Background(), query, namedArgs).Scan(&balance)
+
+	return balance, err
+}
+
+// Retrieve every account of a user with its balance
+func (l *LedgerRepository) GetUserAccounts(userID int64) ([]models.LedgerAccount, error) {
+	var accounts []models.LedgerAccount
+
+	query := `
+		SELECT a.id, a.user_id, a.kind, a.currency, a.created_at, coalesce(sum(j.credit - j.debit), 0)::bigint
+		FROM ledger_accounts a
+		LEFT JOIN journal_lines j ON j.account_id = a.id
+		WHERE a.user_id = @user_id
+		GROUP BY a.id
+		ORDER BY a.currency, a.kind`
+	namedArgs := pgx.NamedArgs{
+		"user_id": userID,
+	}
+
+	rows, err := l.DB.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var account models.LedgerAccount
+
+		err := rows.Scan(&account.ID, &account.UserID, &account.Kind, &account.Currency, &account.CreatedAt, &account.Balance)
+
+		if err != nil {
+			return nil, err
+		}
+
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
+}
+
+// Sum what has been captured from a buyer's holds on an item inside an existing transaction
+func (l *LedgerRepository) GetCapturedAmount(tx pgx.Tx, itemID int64, buyerID int64) (models.Money, error) {
+	var captured models.Money
+
+	query := `
+		SELECT coalesce(sum(j.debit), 0)::bigint
+		FROM journal_entries e
+		JOIN journal_lines j ON j.entry_id = e.id
+		JOIN ledger_accounts a ON a.id = j.account_id
+		WHERE e.kind = 'capture' AND e.item_id = @item_id AND a.user_id = @user_id AND a.kind = 'held'`
+	namedArgs := pgx.NamedArgs{
+		"item_id": itemID,
+		"user_id": buyerID,
+	}
+
+	err := tx.QueryRow(context.Background(), query, namedArgs).Scan(&captured)
+
+	return captured, err
+}
+
+// Record a journal entry together with its lines inside an existing transaction
+func (l *LedgerRepository) CreateJournalEntry(tx pgx.Tx, entry models.JournalEntry) (models.JournalEntry, error) {
+	query := `INSERT INTO journal_entries (kind, item_id, description) VALUES (@kind, @item_id, @description) RETURNING id, created_at`
+	namedArgs := pgx.NamedArgs{
+		"kind":        entry.Kind,
+		"item_id":     entry.ItemID,
+		"description": entry.Description,
+	}
+
+	err := tx.QueryRow(context.Background(), query, namedArgs).Scan(&entry.ID, &entry.CreatedAt)
+
+	if err != nil {
+		return entry, err
+	}
+
+	for i, line := range entry.Lines {
+		query := `INSERT INTO journal_lines (entry_id, account_id, debit, credit) VALUES (@entry_id, @account_id, @debit, @credit) RETURNING id`
+		namedArgs := pgx.NamedArgs{
+			"entry_id":   entry.ID,
+			"account_id": line.AccountID,
+			"debit":      line.Debit,
+			"credit":     line.Credit,
+		}
+
+		err := tx.QueryRow(context.Background(), query, namedArgs).Scan(&entry.Lines[i].ID)
+
+		if err != nil {
+			return entry, err
+		}
+
+		entry.Lines[i].EntryID = entry.ID
+	}
+
+	return entry, nil
+}
+
+// Retrieve the active hold of a user on an item inside an existing transaction
+func (l *LedgerRepository) GetActiveHold(tx pgx.Tx, itemID int64, userID int64) (models.Hold, error) {
+	query := `SELECT ` + holdColumns + ` FROM holds WHERE item_id = @item_id AND user_id = @user_id AND status = 'active'`
+	namedArgs := pgx.NamedArgs{
+		"item_id": itemID,
+		"user_id": userID,
+	}
+
+	return scanHold(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve every active hold on an item inside an existing transaction
+func (l *LedgerRepository) GetActiveItemHolds(tx pgx.Tx, itemID int64) ([]models.Hold, error) {
+	var holds []models.Hold
+
+	query := `SELECT ` + holdColumns + ` FROM holds WHERE item_id = @item_id AND status = 'active' ORDER BY id`
+	namedArgs := pgx.NamedArgs{
+		"item_id": itemID,
+	}
+
+	rows, err := tx.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		hold, err := scanHold(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		holds = append(holds, hold)
+	}
+
+	return holds, rows.Err()
+}
+
+// Retrieve the holds of a user, newest first, only active ones when activeOnly is set
+func (l *LedgerRepository) GetUserHolds(userID int64, activeOnly bool) ([]models.Hold, error) {
+	var holds []models.Hold
+
+	query := `SELECT ` + holdColumns + ` FROM holds WHERE user_id = @user_id AND (NOT @active_only OR status = 'active') ORDER BY created_at DESC, id DESC`
+	namedArgs := pgx.NamedArgs{
+		"user_id":     userID,
+		"active_only": activeOnly,
+	}
+
+	rows, err := l.DB.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		hold, err := scanHold(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		holds = append(holds, hold)
+	}
+
+	return holds, rows.Err()
+}
+
+// Record a new active hold inside an existing transaction
+func (l *LedgerRepository) CreateHold(tx pgx.Tx, hold models.Hold) (models.Hold, error) {
+	query := `INSERT INTO holds (user_id, item_id, currency, amount) VALUES (@user_id, @item_id, @currency, @amount) RETURNING ` + holdColumns
+	namedArgs := pgx.NamedArgs{
+		"user_id":  hold.UserID,
+		"item_id":  hold.ItemID,
+		"currency": hold.Currency,
+		"amount":   hold.Amount,
+	}
+
+	return scanHold(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Change the amount of a hold inside an existing transaction
+func (l *LedgerRepository) UpdateHoldAmount(tx pgx.Tx, holdID int64, amount models.Money) (models.Hold, error) {
+	query := `UPDATE holds SET amount = @amount, updated_at = now() WHERE id = @id RETURNING ` + holdColumns
+	namedArgs := pgx.NamedArgs{
+		"id":     holdID,
+		"amount": amount,
+	}
+
+	return scanHold(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Mark a hold released or captured inside an existing transaction
+func (l *LedgerRepository) UpdateHoldStatus(tx pgx.Tx, holdID int64, status models.HoldStatus) (models.Hold, error) {
+	query := `UPDATE holds SET status = @status, updated_at = now() WHERE id = @id RETURNING ` + holdColumns
+	namedArgs := pgx.NamedArgs{
+		"id":     holdID,
+		"status": status,
+	}
+
+	return scanHold(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Check the ledger's invariants: every entry balances, so the whole ledger does,
+// and every held account matches the active holds of its user in that currency
+func (l *LedgerRepository) CheckLedger() (models.LedgerCheck, error) {
+	check := models.LedgerCheck{UnbalancedEntries: []int64{}, MismatchedHeldAccounts: []int64{}}
+
+	err := l.DB.QueryRow(context.Background(), `SELECT coalesce(sum(debit), 0)::bigint, coalesce(sum(credit), 0)::bigint FROM journal_lines`).Scan(&check.TotalDebits, &check.TotalCredits)
+
+	if err != nil {
+		return check, err
+	}
+
+	check.UnbalancedEntries, err = l.queryIDs(`SELECT entry_id FROM journal_lines GROUP BY entry_id HAVING sum(debit) <> sum(credit) ORDER BY entry_id`)
+
+	if err != nil {
+		return check, err
+	}
+
+	check.MismatchedHeldAccounts, err = l.queryIDs(`
+		SELECT a.id
+		FROM ledger_accounts a
+		WHERE a.kind = 'held'
+			AND (SELECT coalesce(sum(credit - debit), 0) FROM journal_lines WHERE account_id = a.id)
+				<> (SELECT coalesce(sum(amount), 0) FROM holds WHERE user_id = a.user_id AND currency = a.currency AND status = 'active')
+		ORDER BY a.id`)
+
+	if err != nil {
+		return check, err
+	}
+
+	check.Balanced = check.TotalDebits == check.TotalCredits && len(check.UnbalancedEntries) == 0 && len(check.MismatchedHeldAccounts) == 0
+
+	return check, nil
+}
+
+func (l *LedgerRepository) queryIDs(query string) ([]int64, error) {
+	ids := []int64{}
+
+	rows, err := l.DB.Query(context.Background(), query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+
+		err := rows.Scan(&id)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
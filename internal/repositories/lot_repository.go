@@ -0,0 +1,200 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Columns selected for every lot query, in the order scanLot expects them
+const lotColumns = `id, name, seller_id, item_id, created_at, catalog_id, catalog_position`
+
+type LotRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewLotRepository(DB *pgxpool.Pool) *LotRepository {
+	return &LotRepository{DB}
+}
+
+func scanLot(row pgx.Row) (models.Lot, error) {
+	var lot models.Lot
+
+	err := row.Scan(&lot.ID, &lot.Name, &lot.SellerID, &lot.ItemID, &lot.CreatedAt, &lot.CatalogID, &lot.CatalogPosition)
+
+	return lot, err
+}
+
+// Create a lot around its auction item inside an existing transaction
+func (l *LotRepository) CreateLot(tx pgx.Tx, lot models.Lot) (models.Lot, error) {
+	query := `INSERT INTO lots (name, seller_id, item_id) VALUES (@name, @seller_id, @item_id) RETURNING ` + lotColumns
+	namedArgs := pgx.NamedArgs{
+		"name":      lot.Name,
+		"seller_id": lot.SellerID,
+		"item_id":   lot.ItemID,
+	}
+
+	return scanLot(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve a page of lots, newest first
+func (l *LotRepository) GetLots(limit, offset int) ([]models.Lot, error) {
+	var lots []models.Lot
+
+	query := `SELECT ` + lotColumns + ` FROM lots ORDER BY created_at DESC, id DESC LIMIT @limit OFFSET @offset`
+	namedArgs := pgx.NamedArgs{
+		"limit":  limit,
+		"offset": offset,
+	}
+
+	rows, err := l.DB.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		lot, err := scanLot(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		lots = append(lots, lot)
+	}
+
+	return lots, rows.Err()
+}
+
+// Count every lot
+func (l *LotRepository) CountLots() (int64, error) {
+	var total int64
+
+	err := l.DB.QueryRow(context.Background(), `SELECT COUNT(*) FROM lots`).Scan(&total)
+
+	return total, err
+}
+
+// Retrieve a single lot by its ID
+func (l *LotRepository) GetLotByID(id int64) (models.Lot, error) {
+	query := `SELECT ` + lotColumns + ` FROM lots WHERE id = @id`
+	namedArgs := pgx.NamedArgs{
+		"id": id,
+	}
+
+	return scanLot(l.DB.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve a single lot by its ID inside an existing transaction
+func (l *LotRepository) GetLotByIDTx(tx pgx.Tx, id int64) (models.Lot, error) {
+	query := `SELECT ` + lotColumns + ` FROM lots WHERE id = @id`
+	namedArgs := pgx.NamedArgs{
+		"id": id,
+	}
+
+	return scanLot(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Check whether an item is the auction item of a lot inside an existing transaction
+func (l *LotRepository) IsLotAuctionItem(tx pgx.Tx, itemID int64) (bool, error) {
+	var exists bool
+
+	query := `SELECT EXISTS (SELECT 1 FROM lots WHERE item_id = @item_id)`
+	namedArgs := pgx.NamedArgs{
+		"item_id": itemID,
+	}
+
+	err := tx.QueryRow(context.Background(), query, namedArgs).Scan(&exists)
+
+	return exists, err
+}
+
+// Retrieve a single lot by its ID and lock its row until the transaction ends
+func (l *LotRepository) GetLotByIDForUpdate(tx pgx.Tx, id int64) (models.Lot, error) {
+	query := `SELECT ` + lotColumns + ` FROM lots WHERE id = @id FOR UPDATE`
+	namedArgs := pgx.NamedArgs{
+		"id": id,
+	}
+
+	return scanLot(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve the lots of a catalog, in closing order
+func (l *LotRepository) GetCatalogLots(catalogID int64) ([]models.Lot, error) {
+	var lots []models.Lot
+
+	query := `SELECT ` + lotColumns + ` FROM lots WHERE catalog_id = @catalog_id ORDER BY catalog_position`
+	namedArgs := pgx.NamedArgs{
+		"catalog_id": catalogID,
+	}
+
+	rows, err := l.DB.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		lot, err := scanLot(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		lots = append(lots, lot)
+	}
+
+	return lots, rows.Err()
+}
+
+// Take every lot out of a catalog inside an existing transaction, returning the lots' auction item IDs
+func (l *LotRepository) ReleaseCatalogLots(tx pgx.Tx, catalogID int64) ([]int64, error) {
+	var itemIDs []int64
+
+	query := `UPDATE lots SET catalog_id = NULL, catalog_position = NULL WHERE catalog_id = @catalog_id RETURNING item_id`
+	namedArgs := pgx.NamedArgs{
+		"catalog_id": catalogID,
+	}
+
+	rows, err := tx.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var itemID int64
+
+		err := rows.Scan(&itemID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		itemIDs = append(itemIDs, itemID)
+	}
+
+	return itemIDs, rows.Err()
+}
+
+// Put a lot into a catalog at the given position inside an existing transaction
+func (l *LotRepository) AssignLotToCatalog(tx pgx.Tx, lotID int64, catalogID int64, position int) error {
+	query := `UPDATE lots SET catalog_id = @catalog_id, catalog_position = @catalog_position WHERE id = @id`
+	namedArgs := pgx.NamedArgs{
+		"id":               lotID,
+		"catalog_id":       catalogID,
+		"catalog_position": position,
+	}
+
+	_, err := tx.Exec(context.Background(), query, namedArgs)
+
+	return err
+}
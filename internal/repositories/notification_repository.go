@@ -0,0 +1,204 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Columns selected for every notification query, in the order scanNotification expects them
+const notificationColumns = `id, user_id, kind, item_id, message, dedupe_key, read_at, created_at`
+
+// Keeps a notification from being stored for a user who turned its kind off
+const notificationEnabledCondition = `NOT EXISTS (SELECT 1 FROM notification_preferences p WHERE p.user_id = @user_id AND p.kind = @kind AND NOT p.enabled)`
+
+type NotificationRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewNotificationRepository(DB *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{DB}
+}
+
+func scanNotification(row pgx.Row) (models.Notification, error) {
+	var notification models.Notification
+
+	err := row.Scan(&notification.ID, &notification.UserID, &notification.Kind, &notification.ItemID, &notification.Message, &notification.DedupeKey, &notification.ReadAt, &notification.CreatedAt)
+
+	return notification, err
+}
+
+// Store a notification unless its user turned the kind off or one with the same dedupe key exists, reporting whether it was stored
+func (n *NotificationRepository) CreateNotification(notification models.Notification) (bool, error) {
+	query := `
+		INSERT INTO notifications (user_id, kind, item_id, message, dedupe_key)
+		SELECT @user_id, @kind, @item_id, @message, @dedupe_key
+		WHERE ` + notificationEnabledCondition + `
+		ON CONFLICT (dedupe_key) DO NOTHING`
+	namedArgs := pgx.NamedArgs{
+		"user_id":    notification.UserID,
+		"kind":       notification.Kind,
+		"item_id":    notification.ItemID,
+		"message":    notification.Message,
+		"dedupe_key": notification.DedupeKey,
+	}
+
+	tag, err := n.DB.Exec(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// Tell everyone who bid on a live item ending before until that it is about to close, once per item.
+// Returns how many notifications were stored.
+func (n *NotificationRepository) CreateEndingSoonNotifications(now time.Time, until time.Time) (int64, error) {
+	query := `
+		INSERT INTO notifications (user_id, kind, item_id, message, dedupe_key)
+		SELECT DISTINCT b.bidder_id, 'ending_soon', i.id, 'Bidding on ' || i.item_name || ' is ending soon', 'ending_soon:' || i.id || ':' || b.bidder_id
+		FROM items i
+		JOIN bids b ON b.item_id = i.id
+		WHERE i.status IN ('scheduled', 'live') AND i.starts_at <= @now AND i.ends_at > @now AND i.ends_at <= @until
+		AND NOT EXISTS (SELECT 1 FROM notification_preferences p WHERE p.user_id = b.bidder_id AND p.kind = 'ending_soon' AND NOT p.enabled)
+		ON CONFLICT (dedupe_key) DO NOTHING`
+	namedArgs := pgx.NamedArgs{
+		"now":   now,
+		"until": until,
+	}
+
+	tag, err := n.DB.Exec(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// Retrieve a page of a user's notifications, newest first, only the unread ones when unreadOnly is set
+func (n *NotificationRepository) GetNotificationsByUserID(userID int64, unreadOnly bool, limit, offset int) ([]models.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE user_id = @user_id AND (NOT @unread_only OR read_at IS NULL) ORDER BY id DESC LIMIT @limit OFFSET @offset`
+	namedArgs := pgx.NamedArgs{
+		"user_id":     userID,
+		"unread_only": unreadOnly,
+		"limit":       limit,
+		"offset":      offset,
+	}
+
+	rows, err := n.DB.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, rows.Err()
+}
+
+func (n *NotificationRepository) CountUnreadNotifications(userID int64) (int64, error) {
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = @user_id AND read_at IS NULL`
+	namedArgs := pgx.NamedArgs{
+		"user_id": userID,
+	}
+
+	var count int64
+
+	err := n.DB.QueryRow(context.Background(), query, namedArgs).Scan(&count)
+
+	return count, err
+}
+
+// Mark one of a user's notifications as read, a notification read before keeps its first read time
+func (n *NotificationRepository) MarkNotificationRead(userID int64, id int64, now time.Time) (models.Notification, error) {
+	query := `UPDATE notifications SET read_at = COALESCE(read_at, @now) WHERE id = @id AND user_id = @user_id RETURNING ` + notificationColumns
+	namedArgs := pgx.NamedArgs{
+		"id":      id,
+		"user_id": userID,
+		"now":     now,
+	}
+
+	return scanNotification(n.DB.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Mark every unread notification of a user as read and return how many there were
+func (n *NotificationRepository) MarkAllNotificationsRead(userID int64, now time.Time) (int64, error) {
+	query := `UPDATE notifications SET read_at = @now WHERE user_id = @user_id AND read_at IS NULL`
+	namedArgs := pgx.NamedArgs{
+		"user_id": userID,
+		"now":     now,
+	}
+
+	tag, err := n.DB.Exec(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// Retrieve the preferences a user has set, kinds without one are enabled
+func (n *NotificationRepository) GetNotificationPreferences(userID int64) ([]models.NotificationPreference, error) {
+	query := `SELECT kind, enabled FROM notification_preferences WHERE user_id = @user_id`
+	namedArgs := pgx.NamedArgs{
+		"user_id": userID,
+	}
+
+	rows, err := n.DB.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var preferences []models.NotificationPreference
+
+	for rows.Next() {
+		var preference models.NotificationPreference
+
+		err := rows.Scan(&preference.Kind, &preference.Enabled)
+
+		if err != nil {
+			return nil, err
+		}
+
+		preferences = append(preferences, preference)
+	}
+
+	return preferences, rows.Err()
+}
+
+// Set whether a user receives a kind of notification inside an existing transaction
+func (n *NotificationRepository) SetNotificationPreference(tx pgx.Tx, userID int64, preference models.NotificationPreference) error {
+	query := `
+		INSERT INTO notification_preferences (user_id, kind, enabled) VALUES (@user_id, @kind, @enabled)
+		ON CONFLICT (user_id, kind) DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = now()`
+	namedArgs := pgx.NamedArgs{
+		"user_id": userID,
+		"kind":    preference.Kind,
+		"enabled": preference.Enabled,
+	}
+
+	_, err := tx.Exec(context.Background(), query, namedArgs)
+
+	return err
+}
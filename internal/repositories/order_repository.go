@@ -0,0 +1,382 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Columns selected for every order query, in the order scanOrder expects them
+const orderColumns = `id, item_id, bid_id, buyer_id, seller_id, currency, quantity, total, wallet_amount, payment_status, paid_at, refunded_at, created_at, updated_at`
+
+// Columns selected for every order line query, in the order scanOrderLine expects them
+const orderLineColumns = `id, order_id, kind, description, amount`
+
+// Columns selected for every payment transition query, in the order scanPaymentTransition expects them
+const paymentTransitionColumns = `id, order_id, from_status, to_status, changed_by, note, created_at`
+
+// Columns selected for every payment transaction query, in the order scanPaymentTransaction expects them
+// A pending payment attempt has no provider transaction ID yet and reads as an empty one.
+const paymentTransactionColumns = `id, order_id, provider, kind, coalesce(provider_transaction_id, ''), amount, currency, status, failure_reason, created_at`
+
+type OrderRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewOrderRepository(DB *pgxpool.Pool) *OrderRepository {
+	return &OrderRepository{DB}
+}
+
+func scanOrder(row pgx.Row) (models.Order, error) {
+	var order models.Order
+
+	err := row.Scan(&order.ID, &order.ItemID, &order.BidID, &order.BuyerID, &order.SellerID, &order.Currency, &order.Quantity, &order.Total, &order.WalletAmount, &order.PaymentStatus, &order.PaidAt, &order.RefundedAt, &order.CreatedAt, &order.UpdatedAt)
+
+	return order, err
+}
+
+func scanOrderLine(row pgx.Row) (models.OrderLine, error) {
+	var line models.OrderLine
+
+	err := row.Scan(&line.ID, &line.OrderID, &line.Kind, &line.Description, &line.Amount)
+
+	return line, err
+}
+
+func scanPaymentTransition(row pgx.Row) (models.PaymentTransition, error) {
+	var transition models.PaymentTransition
+
+	err := row.Scan(&transition.ID, &transition.OrderID, &transition.FromStatus, &transition.ToStatus, &transition.ChangedBy, &transition.Note, &transition.CreatedAt)
+
+	return transition, err
+}
+
+func scanPaymentTransaction(row pgx.Row) (models.PaymentTransaction, error) {
+	var transaction models.PaymentTransaction
+
+	err := row.Scan(&transaction.ID, &transaction.OrderID, &transaction.Provider, &transaction.Kind, &transaction.ProviderTransactionID, &transaction.Amount, &transaction.Currency, &transaction.Status, &transaction.FailureReason, &transaction.CreatedAt)
+
+	return transaction, err
+}
+
+// Record an order together with its lines inside an existing transaction
+func (o *OrderRepository) CreateOrder(tx pgx.Tx, order models.Order) (models.Order, error) {
+	query := `INSERT INTO orders (item_id, bid_id, buyer_id, seller_id, currency, quantity, total, wallet_amount) VALUES (@item_id, @bid_id, @buyer_id, @seller_id, @currency, @quantity, @total, @wallet_amount) RETURNING ` + orderColumns
+	namedArgs := pgx.NamedArgs{
+		"item_id":       order.ItemID,
+		"bid_id":        order.BidID,
+		"buyer_id":      order.BuyerID,
+		"seller_id":     order.SellerID,
+		"currency":      order.Currency,
+		"quantity":      order.Quantity,
+		"total":         order.Total,
+		"wallet_amount": order.WalletAmount,
+	}
+
+	created, err := scanOrder(tx.QueryRow(context.Background(), query, namedArgs))
+
+	if err != nil {
+		return created, err
+	}
+
+	for _, line := range order.Lines {
+		query := `INSERT INTO order_lines (order_id, kind, description, amount) VALUES (@order_id, @kind, @description, @amount) RETURNING ` + orderLineColumns
+		namedArgs := pgx.NamedArgs{
+			"order_id":    created.ID,
+			"kind":        line.Kind,
+			"description": line.Description,
+			"amount":      line.Amount,
+		}
+
+		line, err := scanOrderLine(tx.QueryRow(context.Background(), query, namedArgs))
+
+		if err != nil {
+			return created, err
+		}
+
+		created.Lines = append(created.Lines, line)
+	}
+
+	return created, nil
+}
+
+func (o *OrderRepository) GetOrderByID(orderID int64) (models.Order, error) {
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE id = @id`
+	namedArgs := pgx.NamedArgs{
+		"id": orderID,
+	}
+
+	return scanOrder(o.DB.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve an order and lock it for the rest of the transaction
+func (o *OrderRepository) GetOrderByIDForUpdate(tx pgx.Tx, orderID int64) (models.Order, error) {
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE id = @id FOR UPDATE`
+	namedArgs := pgx.NamedArgs{
+		"id": orderID,
+	}
+
+	return scanOrder(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve every order a user bought, newest first
+func (o *OrderRepository) GetOrdersByBuyerID(buyerID int64) ([]models.Order, error) {
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE buyer_id = @user_id ORDER BY created_at DESC, id DESC`
+	namedArgs := pgx.NamedArgs{
+		"user_id": buyerID,
+	}
+
+	return o.queryOrders(query, namedArgs)
+}
+
+// Retrieve every order a user sold, newest first
+func (o *OrderRepository) GetOrdersBySellerID(sellerID int64) ([]models.Order, error) {
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE seller_id = @user_id ORDER BY created_at DESC, id DESC`
+	namedArgs := pgx.NamedArgs{
+		"user_id": sellerID,
+	}
+
+	return o.queryOrders(query, namedArgs)
+}
+
+func (o *OrderRepository) queryOrders(query string, namedArgs pgx.NamedArgs) ([]models.Order, error) {
+	var orders []models.Order
+
+	rows, err := o.DB.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		order, err := scanOrder(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
+
+// Retrieve the lines of several orders at once, grouped by order ID
+func (o *OrderRepository) GetOrderLines(orderIDs []int64) (map[int64][]models.OrderLine, error) {
+	lines := make(map[int64][]models.OrderLine, len(orderIDs))
+
+	query := `SELECT ` + orderLineColumns + ` FROM order_lines WHERE order_id = ANY(@order_ids) ORDER BY order_id, id`
+	namedArgs := pgx.NamedArgs{
+		"order_ids": orderIDs,
+	}
+
+	rows, err := o.DB.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		line, err := scanOrderLine(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		lines[line.OrderID] = append(lines[line.OrderID], line)
+	}
+
+	return lines, rows.Err()
+}
+
+// Move an order to a new payment status inside an existing transaction, stamping when it was paid or refunded
+func (o *OrderRepository) UpdatePaymentStatus(tx pgx.Tx, orderID int64, status models.PaymentStatus, now time.Time) (models.Order, error) {
+	query := `
+		UPDATE orders SET
+			payment_status = @status,
+			paid_at = CASE WHEN @status = 'paid' THEN @now ELSE paid_at END,
+			refunded_at = CASE WHEN @status = 'refunded' THEN @now ELSE refunded_at END,
+			updated_at = @now
+		WHERE id = @id
+		RETURNING ` + orderColumns
+	namedArgs := pgx.NamedArgs{
+		"id":     orderID,
+		"status": status,
+		"now":    now,
+	}
+
+	return scanOrder(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Record a payment status change inside an existing transaction
+func (o *OrderRepository) CreatePaymentTransition(tx pgx.Tx, transition models.PaymentTransition) (models.PaymentTransition, error) {
+	query := `INSERT INTO payment_transitions (order_id, from_status, to_status, changed_by, note) VALUES (@order_id, @from_status, @to_status, @changed_by, @note) RETURNING ` + paymentTransitionColumns
+	namedArgs := pgx.NamedArgs{
+		"order_id":    transition.OrderID,
+		"from_status": transition.FromStatus,
+		"to_status":   transition.ToStatus,
+		"changed_by":  transition.ChangedBy,
+		"note":        transition.Note,
+	}
+
+	return scanPaymentTransition(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve the payment history of an order, oldest first
+func (o *OrderRepository) GetPaymentTransitions(orderID int64) ([]models.PaymentTransition, error) {
+	var transitions []models.PaymentTransition
+
+	query := `SELECT ` + paymentTransitionColumns + ` FROM payment_transitions WHERE order_id = @order_id ORDER BY created_at, id`
+	namedArgs := pgx.NamedArgs{
+		"order_id": orderID,
+	}
+
+	rows, err := o.DB.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		transition, err := scanPaymentTransition(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		transitions = append(transitions, transition)
+	}
+
+	return transitions, rows.Err()
+}
+
+// Remove the orders of an item that have not been paid inside an existing transaction,
+// returning how many paid or refunded orders are left
+func (o *OrderRepository) DeleteUnpaidItemOrders(tx pgx.Tx, itemID int64) (int, error) {
+	var remaining int
+
+	namedArgs := pgx.NamedArgs{
+		"item_id": itemID,
+	}
+
+	_, err := tx.Exec(context.Background(), `DELETE FROM orders WHERE item_id = @item_id AND payment_status IN ('pending', 'failed')`, namedArgs)
+
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.QueryRow(context.Background(), `SELECT count(*) FROM orders WHERE item_id = @item_id`, namedArgs).Scan(&remaining)
+
+	return remaining, err
+}
+
+// Record a call made to the payment provider inside an existing transaction
+func (o *OrderRepository) CreatePaymentTransaction(tx pgx.Tx, transaction models.PaymentTransaction) (models.PaymentTransaction, error) {
+	query := `INSERT INTO payment_transactions (order_id, provider, kind, provider_transaction_id, amount, currency, status, failure_reason) VALUES (@order_id, @provider, @kind, NULLIF(@provider_transaction_id, ''), @amount, @currency, @status, @failure_reason) RETURNING ` + paymentTransactionColumns
+	namedArgs := pgx.NamedArgs{
+		"order_id":                transaction.OrderID,
+		"provider":                transaction.Provider,
+		"kind":                    transaction.Kind,
+		"provider_transaction_id": transaction.ProviderTransactionID,
+		"amount":                  transaction.Amount,
+		"currency":                transaction.Currency,
+		"status":                  transaction.Status,
+		"failure_reason":          transaction.FailureReason,
+	}
+
+	return scanPaymentTransaction(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve the pending attempt of the given kind, a payment or a refund, of an order inside an existing transaction
+func (o *OrderRepository) GetPendingPaymentTransaction(tx pgx.Tx, orderID int64, kind string) (models.PaymentTransaction, error) {
+	query := `SELECT ` + paymentTransactionColumns + ` FROM payment_transactions WHERE order_id = @order_id AND kind = @kind AND status = 'pending'`
+	namedArgs := pgx.NamedArgs{
+		"order_id": orderID,
+		"kind":     kind,
+	}
+
+	return scanPaymentTransaction(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve a payment transaction by its ID and lock its row until the transaction ends
+func (o *OrderRepository) GetPaymentTransactionByIDForUpdate(tx pgx.Tx, id int64) (models.PaymentTransaction, error) {
+	query := `SELECT ` + paymentTransactionColumns + ` FROM payment_transactions WHERE id = @id FOR UPDATE`
+	namedArgs := pgx.NamedArgs{
+		"id": id,
+	}
+
+	return scanPaymentTransaction(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Complete a pending payment attempt with the provider's answer inside an existing transaction
+func (o *OrderRepository) CompletePaymentTransaction(tx pgx.Tx, id int64, providerTransactionID string, status string, failureReason *string) (models.PaymentTransaction, error) {
+	query := `UPDATE payment_transactions SET provider_transaction_id = NULLIF(@provider_transaction_id, ''), status = @status, failure_reason = @failure_reason WHERE id = @id RETURNING ` + paymentTransactionColumns
+	namedArgs := pgx.NamedArgs{
+		"id":                      id,
+		"provider_transaction_id": providerTransactionID,
+		"status":                  status,
+		"failure_reason":          failureReason,
+	}
+
+	return scanPaymentTransaction(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve the provider calls made for an order, oldest first
+func (o *OrderRepository) GetPaymentTransactions(orderID int64) ([]models.PaymentTransaction, error) {
+	var transactions []models.PaymentTransaction
+
+	query := `SELECT ` + paymentTransactionColumns + ` FROM payment_transactions WHERE order_id = @order_id ORDER BY id`
+	namedArgs := pgx.NamedArgs{
+		"order_id": orderID,
+	}
+
+	rows, err := o.DB.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		transaction, err := scanPaymentTransaction(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, rows.Err()
+}
+
+// Retrieve a payment transaction by the ID its provider gave it
+func (o *OrderRepository) GetPaymentTransactionByProviderID(provider string, providerTransactionID string) (models.PaymentTransaction, error) {
+	query := `SELECT ` + paymentTransactionColumns + ` FROM payment_transactions WHERE provider = @provider AND provider_transaction_id = @provider_transaction_id`
+	namedArgs := pgx.NamedArgs{
+		"provider":                provider,
+		"provider_transaction_id": providerTransactionID,
+	}
+
+	return scanPaymentTransaction(o.DB.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Retrieve the latest successful capture of an order inside an existing transaction
+func (o *OrderRepository) GetCaptureTransaction(tx pgx.Tx, orderID int64) (models.PaymentTransaction, error) {
+	query := `SELECT ` + paymentTransactionColumns + ` FROM payment_transactions WHERE order_id = @order_id AND kind = 'capture' AND status = 'succeeded' ORDER BY id DESC LIMIT 1`
+	namedArgs := pgx.NamedArgs{
+		"order_id": orderID,
+	}
+
+	return scanPaymentTransaction(tx.QueryRow(context.Background(), query, namedArgs))
+}
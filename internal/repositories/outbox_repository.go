@@ -0,0 +1,214 @@
+package repositories
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Columns selected for every outbox query, in the order scanOutboxEvent expects them
+const outboxColumns = `id, aggregate_type, aggregate_id, item_id, event_type, payload, publish_attempts, publish_last_error, publish_next_attempt_at, published_at, webhook_attempts, webhook_last_error, webhook_next_attempt_at, webhook_delivered_at, created_at`
+
+type OutboxRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewOutboxRepository(DB *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{DB}
+}
+
+func scanOutboxEvent(row pgx.Row) (models.OutboxEvent, error) {
+	var event models.OutboxEvent
+
+	err := row.Scan(&event.ID, &event.AggregateType, &event.AggregateID, &event.ItemID, &event.EventType, &event.Payload, &event.PublishAttempts, &event.PublishLastError, &event.PublishNextAttemptAt, &event.PublishedAt, &event.WebhookAttempts, &event.WebhookLastError, &event.WebhookNextAttemptAt, &event.WebhookDeliveredAt, &event.CreatedAt)
+
+	return event, err
+}
+
+// Add an event to the outbox inside the transaction that made the change it describes
+func (o *OutboxRepository) CreateOutboxEvent(tx pgx.Tx, event models.OutboxEvent) (models.OutboxEvent, error) {
+	query := `INSERT INTO outbox (aggregate_type, aggregate_id, item_id, event_type, payload) VALUES (@aggregate_type, @aggregate_id, @item_id, @event_type, @payload) RETURNING ` + outboxColumns
+	namedArgs := pgx.NamedArgs{
+		"aggregate_type": event.AggregateType,
+		"aggregate_id":   event.AggregateID,
+		"item_id":        event.ItemID,
+		"event_type":     event.EventType,
+		"payload":        event.Payload,
+	}
+
+	return scanOutboxEvent(tx.QueryRow(context.Background(), query, namedArgs))
+}
+
+// Lease up to limit events that are due for publication to the event bus until leaseUntil and return them, oldest first.
+// Only the oldest unpublished event of each aggregate qualifies, and the lease keeps other relays from
+// publishing it, or the events queued behind it, until it was published or the lease ran out.
+func (o *OutboxRepository) ClaimUnpublishedOutboxEvents(now time.Time, leaseUntil time.Time, limit int) ([]models.OutboxEvent, error) {
+	query := `UPDATE outbox SET publish_next_attempt_at = @lease_until
+		WHERE id IN (
+			SELECT id FROM outbox o
+			WHERE published_at IS NULL AND publish_next_attempt_at <= @now
+			AND NOT EXISTS (
+				SELECT 1 FROM outbox earlier
+				WHERE earlier.aggregate_type = o.aggregate_type AND earlier.aggregate_id = o.aggregate_id
+				AND earlier.published_at IS NULL AND earlier.id < o.id
+			)
+			ORDER BY id
+			LIMIT @limit
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + outboxColumns
+	namedArgs := pgx.NamedArgs{
+		"now":         now,
+		"lease_until": leaseUntil,
+		"limit":       limit,
+	}
+
+	return o.queryOutboxEvents(query, namedArgs)
+}
+
+// Record that an event was handed to the event bus
+func (o *OutboxRepository) MarkOutboxEventPublished(id int64, now time.Time) error {
+	query := `UPDATE outbox SET published_at = @now, publish_last_error = NULL WHERE id = @id`
+	namedArgs := pgx.NamedArgs{
+		"id":  id,
+		"now": now,
+	}
+
+	_, err := o.DB.Exec(context.Background(), query, namedArgs)
+
+	return err
+}
+
+// Record a failed publication and when to try again
+func (o *OutboxRepository) MarkOutboxPublishFailed(id int64, reason string, nextAttemptAt time.Time) error {
+	query := `UPDATE outbox SET publish_attempts = publish_attempts + 1, publish_last_error = @reason, publish_next_attempt_at = @next_attempt_at WHERE id = @id`
+	namedArgs := pgx.NamedArgs{
+		"id":              id,
+		"reason":          reason,
+		"next_attempt_at": nextAttemptAt,
+	}
+
+	_, err := o.DB.Exec(context.Background(), query, namedArgs)
+
+	return err
+}
+
+// Lease up to limit events that are due for webhook delivery until leaseUntil and return them, oldest first.
+// Only the oldest undelivered event of each aggregate qualifies, and the lease keeps other relays from
+// posting it, or the events queued behind it, until it was delivered or the lease ran out.
+func (o *OutboxRepository) ClaimOutboxWebhooks(now time.Time, leaseUntil time.Time, limit int) ([]models.OutboxEvent, error) {
+	query := `UPDATE outbox SET webhook_next_attempt_at = @lease_until
+		WHERE id IN (
+			SELECT id FROM outbox o
+			WHERE webhook_delivered_at IS NULL AND webhook_next_attempt_at <= @now
+			AND NOT EXISTS (
+				SELECT 1 FROM outbox earlier
+				WHERE earlier.aggregate_type = o.aggregate_type AND earlier.aggregate_id = o.aggregate_id
+				AND earlier.webhook_delivered_at IS NULL AND earlier.id < o.id
+			)
+			ORDER BY id
+			LIMIT @limit
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + outboxColumns
+	namedArgs := pgx.NamedArgs{
+		"now":         now,
+		"lease_until": leaseUntil,
+		"limit":       limit,
+	}
+
+	return o.queryOutboxEvents(query, namedArgs)
+}
+
+func (o *OutboxRepository) queryOutboxEvents(query string, namedArgs pgx.NamedArgs) ([]models.OutboxEvent, error) {
+	rows, err := o.DB.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var outboxEvents []models.OutboxEvent
+
+	for rows.Next() {
+		event, err := scanOutboxEvent(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		outboxEvents = append(outboxEvents, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep the order of the subquery
+	slices.SortFunc(outboxEvents, func(a, b models.OutboxEvent) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return outboxEvents, nil
+}
+
+// Record that every webhook endpoint took an event
+func (o *OutboxRepository) MarkOutboxWebhookDelivered(id int64, now time.Time) error {
+	query := `UPDATE outbox SET webhook_delivered_at = @now, webhook_last_error = NULL WHERE id = @id`
+	namedArgs := pgx.NamedArgs{
+		"id":  id,
+		"now": now,
+	}
+
+	_, err := o.DB.Exec(context.Background(), query, namedArgs)
+
+	return err
+}
+
+// Record a failed webhook delivery and when to try again
+func (o *OutboxRepository) MarkOutboxWebhookFailed(id int64, reason string, nextAttemptAt time.Time) error {
+	query := `UPDATE outbox SET webhook_attempts = webhook_attempts + 1, webhook_last_error = @reason, webhook_next_attempt_at = @next_attempt_at WHERE id = @id`
+	namedArgs := pgx.NamedArgs{
+		"id":              id,
+		"reason":          reason,
+		"next_attempt_at": nextAttemptAt,
+	}
+
+	_, err := o.DB.Exec(context.Background(), query, namedArgs)
+
+	return err
+}
+
+// Mark every event still waiting for webhook delivery as delivered, used when no webhooks are configured
+func (o *OutboxRepository) SkipOutboxWebhooks(now time.Time) error {
+	query := `UPDATE outbox SET webhook_delivered_at = @now WHERE webhook_delivered_at IS NULL`
+	namedArgs := pgx.NamedArgs{
+		"now": now,
+	}
+
+	_, err := o.DB.Exec(context.Background(), query, namedArgs)
+
+	return err
+}
+
+// Delete events published and delivered before the given time and return how many were deleted
+func (o *OutboxRepository) DeleteDeliveredOutboxEvents(before time.Time) (int64, error) {
+	query := `DELETE FROM outbox WHERE webhook_delivered_at < @before AND published_at < @before`
+	namedArgs := pgx.NamedArgs{
+		"before": before,
+	}
+
+	tag, err := o.DB.Exec(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ProxyBidRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewProxyBidRepository(DB *pgxpool.Pool) *ProxyBidRepository {
+	return &ProxyBidRepository{DB}
+}
+
+// Retrieve a bidder's proxy bid on an item inside an existing transaction
+func (p *ProxyBidRepository) GetProxyBid(tx pgx.Tx, itemID int64, bidderID int64) (models.ProxyBid, error) {
+	var proxy models.ProxyBid
+
+	query := `SELECT id, item_id, bidder_id, max_amount, created_at, updated_at FROM proxy_bids WHERE item_id = @item_id AND bidder_id = @bidder_id`
+	namedArgs := pgx.NamedArgs{
+		"item_id":   itemID,
+		"bidder_id": bidderID,
+	}
+
+	err := tx.QueryRow(context.Background(), query, namedArgs).Scan(&proxy.ID, &proxy.ItemID, &proxy.BidderID, &proxy.MaxAmount, &proxy.CreatedAt, &proxy.UpdatedAt)
+
+	if err != nil {
+		return proxy, err
+	}
+
+	return proxy, nil
+}
+
+// Create or replace a bidder's proxy bid on an item inside an existing transaction
+func (p *ProxyBidRepository) UpsertProxyBid(tx pgx.Tx, proxy models.ProxyBid) (models.ProxyBid, error) {
+	var saved models.ProxyBid
+
+	query := `INSERT INTO proxy_bids (item_id, bidder_id, max_amount) VALUES (@item_id, @bidder_id, @max_amount)
+		ON CONFLICT (item_id, bidder_id) DO UPDATE SET max_amount = EXCLUDED.max_amount, updated_at = now()
+		RETURNING id, item_id, bidder_id, max_amount, created_at, updated_at`
+	namedArgs := pgx.NamedArgs{
+		"item_id":    proxy.ItemID,
+		"bidder_id":  proxy.BidderID,
+		"max_amount": proxy.MaxAmount,
+	}
+
+	err := tx.QueryRow(context.Background(), query, namedArgs).Scan(&saved.ID, &saved.ItemID, &saved.BidderID, &saved.MaxAmount, &saved.CreatedAt, &saved.UpdatedAt)
+
+	if err != nil {
+		return saved, err
+	}
+
+	return saved, nil
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type StreamTicketRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewStreamTicketRepository(DB *pgxpool.Pool) *StreamTicketRepository {
+	return &StreamTicketRepository{DB}
+}
+
+// Store the hash of a new ticket for a user
+func (s *StreamTicketRepository) CreateStreamTicket(ticketHash string, userID int64, expiresAt time.Time) error {
+	query := `INSERT INTO stream_tickets (ticket_hash, user_id, expires_at) VALUES (@ticket_hash, @user_id, @expires_at)`
+	namedArgs := pgx.NamedArgs{
+		"ticket_hash": ticketHash,
+		"user_id":     userID,
+		"expires_at":  expiresAt,
+	}
+
+	_, err := s.DB.Exec(context.Background(), query, namedArgs)
+
+	return err
+}
+
+// Delete a ticket that has not expired yet and return the user it was issued to, so it can only be used once
+func (s *StreamTicketRepository) RedeemStreamTicket(ticketHash string, now time.Time) (int64, error) {
+	var userID int64
+
+	query := `DELETE FROM stream_tickets WHERE ticket_hash = @ticket_hash AND expires_at > @now RETURNING user_id`
+	namedArgs := pgx.NamedArgs{
+		"ticket_hash": ticketHash,
+		"now":         now,
+	}
+
+	err := s.DB.QueryRow(context.Background(), query, namedArgs).Scan(&userID)
+
+	return userID, err
+}
+
+// Delete the tickets that expired before they were used, returning how many were deleted
+func (s *StreamTicketRepository) DeleteExpiredStreamTickets(now time.Time) (int64, error) {
+	query := `DELETE FROM stream_tickets WHERE expires_at <= @now`
+	namedArgs := pgx.NamedArgs{
+		"now": now,
+	}
+
+	tag, err := s.DB.Exec(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Run fn inside a single database transaction.
+// The transaction is committed when fn returns nil and rolled back otherwise.
+func WithTx(DB *pgxpool.Pool, fn func(tx pgx.Tx) error) error {
+	tx, err := DB.Begin(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(context.Background())
+
+	err = fn(tx)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(context.Background())
+}
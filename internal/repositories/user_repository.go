@@ -20,7 +20,7 @@ func NewUserRepository(DB *pgxpool.Pool) *UserRepository {
 func (u *UserRepository) GetUsers() ([]models.User, error) {
 	var users []models.User
 
-	query := `SELECT * FROM users`
+	query := `SELECT id, username, password, currency FROM users`
 
 	rows, err := u.DB.Query(context.Background(), query)
 
@@ -31,7 +31,7 @@ func (u *UserRepository) GetUsers() ([]models.User, error) {
 	for rows.Next() {
 		var user models.User
 
-		err := rows.Scan(&user.ID, &user.Username, &user.Password)
+		err := rows.Scan(&user.ID, &user.Username, &user.Password, &user.Currency)
 
 		if err != nil {
 			return nil, err
@@ -47,12 +47,12 @@ func (u *UserRepository) GetUsers() ([]models.User, error) {
 func (u *UserRepository) GetUserByID(id int64) (models.User, error) {
 	var user models.User
 
-	query := `SELECT * FROM users WHERE id = @id`
+	query := `SELECT id, username, password, currency FROM users WHERE id = @id`
 	namedArgs := pgx.NamedArgs{
 		"id": id,
 	}
 
-	err := u.DB.QueryRow(context.Background(), query, namedArgs).Scan(&user.ID, &user.Username, &user.Password)
+	err := u.DB.QueryRow(context.Background(), query, namedArgs).Scan(&user.ID, &user.Username, &user.Password, &user.Currency)
 
 	if err != nil {
 		return user, err
@@ -65,12 +65,12 @@ func (u *UserRepository) GetUserByID(id int64) (models.User, error) {
 func (u *UserRepository) GetUserByUsername(username string) (models.User, error) {
 	var user models.User
 
-	query := `SELECT * FROM users WHERE username = @username`
+	query := `SELECT id, username, password, currency FROM users WHERE username = @username`
 	namedArgs := pgx.NamedArgs{
 		"username": username,
 	}
 
-	err := u.DB.QueryRow(context.Background(), query, namedArgs).Scan(&user.ID, &user.Username, &user.Password)
+	err := u.DB.QueryRow(context.Background(), query, namedArgs).Scan(&user.ID, &user.Username, &user.Password, &user.Currency)
 
 	if err != nil {
 		return user, err
@@ -83,13 +83,14 @@ func (u *UserRepository) GetUserByUsername(username string) (models.User, error)
 func (u *UserRepository) CreateUser(user models.User) (models.User, error) {
 	var newUser models.User
 
-	query := `INSERT INTO users (username, password) VALUES (@username, @password) RETURNING id, username, password`
+	query := `INSERT INTO users (username, password, currency) VALUES (@username, @password, @currency) RETURNING id, username, password, currency`
 	namedArgs := pgx.NamedArgs{
 		"username": user.Username,
 		"password": user.Password,
+		"currency": user.Currency,
 	}
 
-	err := u.DB.QueryRow(context.Background(), query, namedArgs).Scan(&newUser.ID, &newUser.Username, &newUser.Password)
+	err := u.DB.QueryRow(context.Background(), query, namedArgs).Scan(&newUser.ID, &newUser.Username, &newUser.Password, &newUser.Currency)
 
 	if err != nil {
 		return newUser, err
@@ -97,3 +98,36 @@ func (u *UserRepository) CreateUser(user models.User) (models.User, error) {
 
 	return newUser, nil
 }
+
+// Retrieve the usernames of several users at once, keyed by user ID
+func (u *UserRepository) GetUsernamesByIDs(ids []int64) (map[int64]string, error) {
+	usernames := make(map[int64]string, len(ids))
+
+	query := `SELECT id, username FROM users WHERE id = ANY(@ids)`
+	namedArgs := pgx.NamedArgs{
+		"ids": ids,
+	}
+
+	rows, err := u.DB.Query(context.Background(), query, namedArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var username string
+
+		err := rows.Scan(&id, &username)
+
+		if err != nil {
+			return nil, err
+		}
+
+		usernames[id] = username
+	}
+
+	return usernames, rows.Err()
+}
@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs background jobs on a fixed interval until its context is cancelled
+type Scheduler struct {
+	jobs []Job
+	wg   sync.WaitGroup
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Register a job, jobs have to be added before Start is called
+func (s *Scheduler) Add(name string, interval time.Duration, run func(ctx context.Context) error) {
+	s.jobs = append(s.jobs, Job{Name: name, Interval: interval, Run: run})
+}
+
+// Start every registered job in its own goroutine
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+
+		go func(job Job) {
+			defer s.wg.Done()
+			s.runJob(ctx, job)
+		}(job)
+	}
+}
+
+// Block until every job has returned after the context was cancelled
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) runJob(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	log.Printf("Scheduler job %s started, running every %s", job.Name, job.Interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Scheduler job %s stopped", job.Name)
+			return
+		case <-ticker.C:
+			err := job.Run(ctx)
+
+			if err != nil {
+				log.Printf("Scheduler job %s failed: %v", job.Name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"errors"
+	"log"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/repositories"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrNotAllocationViewer = errors.New("only the seller and the bidder may view a bidder's allocations")
+
+// AllocationService reports which bidders won units of multi-unit auctions and at what price
+type AllocationService struct {
+	ItemRepository       *repositories.ItemRepository
+	AllocationRepository *repositories.AllocationRepository
+}
+
+func NewAllocationService(ItemRepository *repositories.ItemRepository, AllocationRepository *repositories.AllocationRepository) *AllocationService {
+	return &AllocationService{ItemRepository, AllocationRepository}
+}
+
+// Retrieve the allocations of an item, only those of one bidder when bidderID is set.
+// A single bidder's allocations may only be looked up by that bidder or the item's seller.
+func (a *AllocationService) GetItemAllocations(itemID int64, bidderID *int64, viewerID int64) ([]models.Allocation, error) {
+	item, err := a.ItemRepository.GetItemByID(itemID)
+
+	if err != nil {
+		log.Printf("Error retrieving allocations: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrItemNotFound
+		}
+		return nil, err
+	}
+
+	if bidderID != nil && *bidderID != viewerID && item.AuctionedBy != viewerID {
+		return nil, ErrNotAllocationViewer
+	}
+
+	allocations, err := a.AllocationRepository.GetAllocationsByItemID(itemID, bidderID)
+
+	if err != nil {
+		log.Printf("Error retrieving allocations: %v", err)
+		return nil, err
+	}
+
+	if allocations == nil {
+		allocations = []models.Allocation{}
+	}
+
+	return allocations, nil
+}
+
+// Retrieve every allocation a bidder has won across all items
+func (a *AllocationService) GetBidderAllocations(bidderID int64) ([]models.Allocation, error) {
+	allocations, err := a.AllocationRepository.GetAllocationsByBidderID(bidderID)
+
+	if err != nil {
+		log.Printf("Error retrieving allocations: %v", err)
+		return nil, err
+	}
+
+	if allocations == nil {
+		allocations = []models.Allocation{}
+	}
+
+	return allocations, nil
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bangueco/auction-api/internal/events"
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/repositories"
+	"github.com/jackc/pgx/v5"
+)
+
+// AuctionService drives the time based part of the auction lifecycle outside the request path
+type AuctionService struct {
+	ItemRepository       *repositories.ItemRepository
+	BidRepository        *repositories.BidRepository
+	AllocationRepository *repositories.AllocationRepository
+	OrderService         *OrderService
+	WalletService        *WalletService
+	Outbox               *OutboxService
+	// Price changes are published straight to this instance, every instance computes them for itself
+	Dispatcher *events.Dispatcher
+	BatchSize  int
+
+	// Last asking price published for each live dutch auction
+	pricesMu sync.Mutex
+	prices   map[int64]models.Money
+}
+
+func NewAuctionService(ItemRepository *repositories.ItemRepository, BidRepository *repositories.BidRepository, AllocationRepository *repositories.AllocationRepository, OrderService *OrderService, WalletService *WalletService, Outbox *OutboxService, Dispatcher *events.Dispatcher, BatchSize int) *AuctionService {
+	return &AuctionService{
+		ItemRepository:       ItemRepository,
+		BidRepository:        BidRepository,
+		AllocationRepository: AllocationRepository,
+		OrderService:         OrderService,
+		WalletService:        WalletService,
+		Outbox:               Outbox,
+		Dispatcher:           Dispatcher,
+		BatchSize:            BatchSize,
+		prices:               make(map[int64]models.Money),
+	}
+}
+
+// Move scheduled auctions whose start time has passed to live
+func (a *AuctionService) ActivateScheduledAuctions(ctx context.Context) error {
+	activated, err := a.ItemRepository.ActivateScheduledItems(time.Now())
+
+	if err != nil {
+		log.Printf("Error activating scheduled auctions: %v", err)
+		return err
+	}
+
+	if activated > 0 {
+		log.Printf("Activated %d scheduled auctions", activated)
+	}
+
+	return nil
+}
+
+// Publish a PriceChanged event for every live dutch auction whose asking price dropped since the last run.
+// Prices follow a fixed schedule and are never written anywhere, so the drops are found by polling.
+func (a *AuctionService) PublishPriceChanges(ctx context.Context) error {
+	now := time.Now()
+
+	items, err := a.ItemRepository.GetLiveDutchItems(now)
+
+	if err != nil {
+		log.Printf("Error publishing price changes: %v", err)
+		return err
+	}
+
+	a.pricesMu.Lock()
+	defer a.pricesMu.Unlock()
+
+	live := make(map[int64]models.Money, len(items))
+
+	for _, item := range items {
+		price, nextDropAt := item.DutchPriceAt(now)
+		live[item.ID] = price
+
+		if last, ok := a.prices[item.ID]; ok && last == price {
+			continue
+		}
+
+		a.Dispatcher.Publish(events.Event{Type: events.PriceChanged, ItemID: item.ID, Data: models.PriceChange{ItemID: item.ID, CurrentPrice: price, NextPriceDropAt: nextDropAt, Currency: item.Currency}})
+	}
+
+	a.prices = live
+
+	return nil
+}
+
+// Close every auction that has run past its end time, one batch at a time.
+// Each batch is locked with SKIP LOCKED so several API instances can run this job at once
+// without closing the same auction twice. An ItemClosed event is recorded for every
+// auction in the transaction that closed it.
+func (a *AuctionService) CloseExpiredAuctions(ctx context.Context) error {
+	for ctx.Err() == nil {
+		closed, err := a.closeExpiredBatch(time.Now())
+
+		if err != nil {
+			log.Printf("Error closing expired auctions: %v", err)
+			return err
+		}
+
+		if len(closed) > 0 {
+			a.Outbox.Wake()
+		}
+
+		if len(closed) < a.BatchSize {
+			return nil
+		}
+	}
+
+	return ctx.Err()
+}
+
+func (a *AuctionService) closeExpiredBatch(now time.Time) ([]models.Item, error) {
+	var closed []models.Item
+
+	err := repositories.WithTx(a.ItemRepository.DB, func(tx pgx.Tx) error {
+		items, err := a.ItemRepository.GetExpiredItemsForUpdate(tx, now, a.BatchSize)
+
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			outcome, err := a.settle(tx, item)
+
+			if err != nil {
+				return err
+			}
+
+			closedItem, err := a.ItemRepository.CloseItem(tx, item.ID, outcome.Status, outcome.WinnerID, outcome.WinningBidID, outcome.HammerPrice, now)
+
+			if err != nil {
+				return err
+			}
+
+			var allocations []models.Allocation
+
+			for _, allocation := range outcome.Allocations {
+				created, err := a.AllocationRepository.CreateAllocation(tx, allocation)
+
+				if err != nil {
+					return err
+				}
+
+				allocations = append(allocations, created)
+			}
+
+			err = a.WalletService.Settle(tx, closedItem, allocations)
+
+			if err != nil {
+				return err
+			}
+
+			_, err = a.OrderService.CreateOrders(tx, closedItem, allocations)
+
+			if err != nil {
+				return err
+			}
+
+			err = a.Outbox.RecordItemEvent(tx, events.ItemClosed, closedItem.ID, closedItem)
+
+			if err != nil {
+				return err
+			}
+
+			closed = append(closed, closedItem)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return closed, nil
+}
+
+// The result of an auction reaching its end time
+type auctionOutcome struct {
+	Status       models.ItemStatus
+	WinnerID     *int64
+	WinningBidID *int64
+	HammerPrice  *models.Money
+	// Units handed to each winning bid of a multi-unit auction
+	Allocations []models.Allocation
+}
+
+// Decide who, if anyone, won an expired auction and what they pay
+func (a *AuctionService) settle(tx pgx.Tx, item models.Item) (auctionOutcome, error) {
+	outcome := auctionOutcome{Status: models.ItemStatusEnded}
+
+	// A dutch auction that reaches its end was never accepted
+	if item.AuctionType == models.AuctionTypeDutch {
+		outcome.Status = models.ItemStatusUnsold
+		return outcome, nil
+	}
+
+	// The lowest bid wins a reverse auction, its owner may award the contract to another bid later
+	if item.AuctionType == models.AuctionTypeReverse {
+		lowest, err := a.BidRepository.GetLowestBidTx(tx, item.ID)
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return outcome, nil
+		}
+
+		if err != nil {
+			return outcome, err
+		}
+
+		outcome.Status = models.ItemStatusSold
+		outcome.WinnerID, outcome.WinningBidID, outcome.HammerPrice = &lowest.BidderID, &lowest.ID, &lowest.Amount
+
+		return outcome, nil
+	}
+
+	if item.Quantity > 1 {
+		return a.settleMultiUnit(tx, item)
+	}
+
+	ranked, err := a.BidRepository.GetTopBidsTx(tx, item.ID, 2)
+
+	if err != nil {
+		return outcome, err
+	}
+
+	// An auction that ends below its reserve is not awarded to anyone
+	if len(ranked) == 0 || (item.ReservePrice != nil && ranked[0].Amount < *item.ReservePrice) {
+		if item.ReservePrice != nil {
+			outcome.Status = models.ItemStatusUnsold
+		}
+
+		return outcome, nil
+	}
+
+	price := ranked[0].Amount
+
+	if item.AuctionType.IsSealed() {
+		price = sealedHammerPrice(item, ranked)
+	}
+
+	outcome.Status = models.ItemStatusSold
+	outcome.WinnerID, outcome.WinningBidID, outcome.HammerPrice = &ranked[0].BidderID, &ranked[0].ID, &price
+
+	return outcome, nil
+}
+
+// Allocate the units of an expired multi-unit auction to its highest bids.
+// Under uniform pricing the lowest winning bid becomes the hammer price every winner pays.
+func (a *AuctionService) settleMultiUnit(tx pgx.Tx, item models.Item) (auctionOutcome, error) {
+	outcome := auctionOutcome{Status: models.ItemStatusEnded}
+
+	ranked, err := a.BidRepository.GetTopBidsTx(tx, item.ID, 0)
+
+	if err != nil {
+		return outcome, err
+	}
+
+	outcome.Allocations = models.AllocateUnits(item.Quantity, ranked, item.PricingRule, item.ReservePrice)
+
+	if len(outcome.Allocations) == 0 {
+		if item.ReservePrice != nil {
+			outcome.Status = models.ItemStatusUnsold
+		}
+
+		return outcome, nil
+	}
+
+	outcome.Status = models.ItemStatusSold
+
+	if item.PricingRule == models.PricingRuleUniform {
+		outcome.HammerPrice = &outcome.Allocations[0].UnitPrice
+	}
+
+	return outcome, nil
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/repositories"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrNotReverseAuction  = errors.New("only reverse auctions can be awarded")
+	ErrAuctionNotClosed   = errors.New("auction has not closed yet")
+	ErrBidNotFound        = errors.New("bid not found")
+	ErrAwardReasonMissing = errors.New("awarding a bid other than the lowest needs a reason")
+)
+
+// AwardService lets the owner of a closed reverse auction choose which bid wins the contract
+type AwardService struct {
+	ItemRepository  *repositories.ItemRepository
+	BidRepository   *repositories.BidRepository
+	AwardRepository *repositories.AwardRepository
+	OrderService    *OrderService
+	Outbox          *OutboxService
+}
+
+func NewAwardService(ItemRepository *repositories.ItemRepository, BidRepository *repositories.BidRepository, AwardRepository *repositories.AwardRepository, OrderService *OrderService, Outbox *OutboxService) *AwardService {
+	return &AwardService{ItemRepository, BidRepository, AwardRepository, OrderService, Outbox}
+}
+
+// Award a closed reverse auction to one of its bids.
+// The closer awards the lowest bid automatically, any other bid needs a reason,
+// and every award is written to the audit trail together with the bid it replaced.
+// The unpaid order of the previous award is replaced by one for the new bid.
+func (a *AwardService) AwardBid(itemID int64, userID int64, request models.AwardRequest) (models.Item, error) {
+	var awardedItem models.Item
+
+	err := repositories.WithTx(a.ItemRepository.DB, func(tx pgx.Tx) error {
+		item, err := a.ItemRepository.GetItemByIDForUpdate(tx, itemID)
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrItemNotFound
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if item.AuctionedBy != userID {
+			return ErrNotItemOwner
+		}
+
+		if item.AuctionType != models.AuctionTypeReverse {
+			return ErrNotReverseAuction
+		}
+
+		if item.ClosedAt == nil {
+			return ErrAuctionNotClosed
+		}
+
+		bid, err := a.BidRepository.GetItemBidTx(tx, itemID, request.BidID)
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrBidNotFound
+		}
+
+		if err != nil {
+			return err
+		}
+
+		lowest, err := a.BidRepository.GetLowestBidTx(tx, itemID)
+
+		if err != nil {
+			return err
+		}
+
+		if bid.ID != lowest.ID && request.Reason == nil {
+			return ErrAwardReasonMissing
+		}
+
+		_, err = a.AwardRepository.CreateAward(tx, models.Award{ItemID: itemID, BidID: bid.ID, PreviousBidID: item.WinningBidID, AwardedBy: userID, Reason: request.Reason})
+
+		if err != nil {
+			return err
+		}
+
+		awardedItem, err = a.ItemRepository.AwardItem(tx, itemID, bid)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = a.OrderService.ReplaceOrders(tx, awardedItem)
+
+		return err
+	})
+
+	if err != nil {
+		log.Printf("Error awarding bid: %v", err)
+		return awardedItem, err
+	}
+
+	a.Outbox.Wake()
+
+	return presentItem(awardedItem, userID, time.Now()), nil
+}
+
+// Retrieve the award audit trail of an item, only its owner may read it
+func (a *AwardService) GetAwards(itemID int64, userID int64) ([]models.Award, error) {
+	item, err := a.ItemRepository.GetItemByID(itemID)
+
+	if err != nil {
+		log.Printf("Error retrieving awards: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrItemNotFound
+		}
+		return nil, err
+	}
+
+	if item.AuctionedBy != userID {
+		return nil, ErrNotItemOwner
+	}
+
+	awards, err := a.AwardRepository.GetAwardsByItemID(itemID)
+
+	if err != nil {
+		log.Printf("Error retrieving awards: %v", err)
+		return nil, err
+	}
+
+	if awards == nil {
+		awards = []models.Award{}
+	}
+
+	return awards, nil
+}
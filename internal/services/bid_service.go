@@ -0,0 +1,557 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bangueco/auction-api/internal/events"
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/repositories"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrBidTooLow         = errors.New("bid must be higher than the current bid")
+	ErrBidTooHigh        = errors.New("bid must be lower than the current bid")
+	ErrOwnItemBid        = errors.New("cannot bid on your own item")
+	ErrNoBids            = errors.New("no bids found")
+	ErrAlreadyLeading    = errors.New("bidder already holds the leading bid")
+	ErrInvalidMaxAmount  = errors.New("maximum bid must not be below the bid amount")
+	ErrBuyNowUnavailable = errors.New("buy-now is not available for this item")
+	ErrCurrencyMismatch  = errors.New("bid currency does not match the item currency")
+	ErrWrongAuctionType  = errors.New("operation is not supported by this auction type")
+	ErrProxyUnavailable  = errors.New("proxy bids are only available in english auctions")
+)
+
+// Returned when a bid is below the next valid bid, it matches ErrBidTooLow with errors.Is
+type BidTooLowError struct {
+	MinimumBid models.Money
+}
+
+func (e *BidTooLowError) Error() string {
+	return fmt.Sprintf("%v, the minimum bid is %s", ErrBidTooLow, e.MinimumBid)
+}
+
+func (e *BidTooLowError) Is(target error) bool {
+	return target == ErrBidTooLow
+}
+
+// Returned when a bid in a reverse auction is above the next valid bid, it matches ErrBidTooHigh with errors.Is
+type BidTooHighError struct {
+	MaximumBid models.Money
+}
+
+func (e *BidTooHighError) Error() string {
+	return fmt.Sprintf("%v, the maximum bid is %s", ErrBidTooHigh, e.MaximumBid)
+}
+
+func (e *BidTooHighError) Is(target error) bool {
+	return target == ErrBidTooHigh
+}
+
+// Bidding rules shared by every auction
+type BidRules struct {
+	// A bid placed within SoftCloseWindow of the end pushes the end back by SoftCloseExtension
+	SoftCloseWindow    time.Duration
+	SoftCloseExtension time.Duration
+	MaxExtensions      int
+	// Share of the buy-now price, in basis points, the high bid may reach before buy-now is withdrawn
+	BuyNowThresholdBps int64
+	// Whether a bidder may change their sealed bid before the close
+	SealedRevisions models.SealedRevisionRule
+}
+
+type BidService struct {
+	BidRepository      *repositories.BidRepository
+	ItemRepository     *repositories.ItemRepository
+	UserRepository     *repositories.UserRepository
+	ProxyBidRepository *repositories.ProxyBidRepository
+	IncrementService   *IncrementService
+	OrderService       *OrderService
+	WalletService      *WalletService
+	Outbox             *OutboxService
+	Rules              BidRules
+}
+
+func NewBidService(BidRepository *repositories.BidRepository, ItemRepository *repositories.ItemRepository, UserRepository *repositories.UserRepository, ProxyBidRepository *repositories.ProxyBidRepository, IncrementService *IncrementService, OrderService *OrderService, WalletService *WalletService, Outbox *OutboxService, Rules BidRules) *BidService {
+	return &BidService{BidRepository, ItemRepository, UserRepository, ProxyBidRepository, IncrementService, OrderService, WalletService, Outbox, Rules}
+}
+
+// Place a bid or a proxy bid on an item.
+// The item row is locked for the duration of the transaction so concurrent bids are
+// compared against the latest high bid, and the item's current price is updated together with the new bids.
+// A bid that lands inside the soft close window extends the auction in the same transaction,
+// and so are the holds on the bidders' wallets and the events announcing the bids.
+func (b *BidService) PlaceBid(itemID int64, bidderID int64, request models.PlaceBidRequest) (models.BidPlacement, error) {
+	var placement models.BidPlacement
+
+	err := repositories.WithTx(b.BidRepository.DB, func(tx pgx.Tx) error {
+		now := time.Now()
+
+		item, err := b.lockBiddableItem(tx, itemID, bidderID, now)
+
+		if err != nil {
+			return err
+		}
+
+		// Amounts are only ever compared in the item's own currency
+		if request.Currency != "" && request.Currency != item.Currency {
+			return ErrCurrencyMismatch
+		}
+
+		if !request.Amount.FitsCurrency(item.Currency) || (request.MaxAmount != nil && !request.MaxAmount.FitsCurrency(item.Currency)) {
+			return models.ErrInvalidMoneyForCurrency
+		}
+
+		var placed []models.Bid
+
+		leaders, _, err := b.currentLeaders(tx, item)
+
+		if err != nil {
+			return err
+		}
+
+		if request.Quantity == 0 {
+			request.Quantity = 1
+		}
+
+		if request.Quantity > item.Quantity {
+			return ErrInvalidQuantity
+		}
+
+		switch {
+		case item.Quantity > 1:
+			placement, placed, err = b.placeMultiUnitBid(tx, item, bidderID, request)
+		case item.AuctionType == models.AuctionTypeEnglish:
+			placement, placed, err = b.resolveBid(tx, item, bidderID, request)
+		case item.AuctionType == models.AuctionTypeReverse:
+			placement, placed, err = b.placeReverseBid(tx, item, bidderID, request)
+		case item.AuctionType.IsSealed():
+			placement, err = b.placeSealedBid(tx, item, bidderID, request)
+		default:
+			err = ErrWrongAuctionType
+		}
+
+		if err != nil {
+			return err
+		}
+
+		placement.Currency = item.Currency
+
+		if !item.AuctionType.IsSealed() {
+			for _, bid := range placed {
+				err = b.Outbox.RecordItemEvent(tx, events.BidPlaced, bid.ItemID, bid)
+
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		err = b.recordOutbid(tx, item, leaders)
+
+		if err != nil {
+			return err
+		}
+
+		err = b.holdFunds(tx, item, bidderID, request, placement)
+
+		if err != nil {
+			return err
+		}
+
+		if len(placed) > 0 && b.shouldExtend(item, now) {
+			extended, err := b.ItemRepository.ExtendItem(tx, item.ID, item.EndsAt.Add(b.Rules.SoftCloseExtension))
+
+			if err != nil {
+				return err
+			}
+
+			// Lots closing after this one in a catalog keep their stagger behind it
+			pushed, err := b.ItemRepository.PushBackCatalogLots(tx, item.ID, *extended.EndsAt, now)
+
+			if err != nil {
+				return err
+			}
+
+			for _, extendedItem := range append([]models.Item{extended}, pushed...) {
+				err = b.Outbox.RecordItemEvent(tx, events.ItemExtended, extendedItem.ID, extendedItem)
+
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Error placing bid: %v", err)
+		return placement, err
+	}
+
+	b.Outbox.Wake()
+
+	return placement, nil
+}
+
+// Return the bidders winning an item right now and the bid the others have to beat, the lowest winning bid of a multi-unit auction.
+// Sealed bids stay secret until the close, so nobody leads a sealed auction.
+func (b *BidService) currentLeaders(tx pgx.Tx, item models.Item) (map[int64]bool, models.Bid, error) {
+	leaders := make(map[int64]bool)
+
+	switch {
+	case item.AuctionType.IsSealed() || item.AuctionType == models.AuctionTypeDutch:
+		return leaders, models.Bid{}, nil
+	case item.Quantity > 1:
+		ranked, err := b.BidRepository.GetTopBidsTx(tx, item.ID, 0)
+
+		if err != nil {
+			return nil, models.Bid{}, err
+		}
+
+		allocations := models.AllocateUnits(item.Quantity, ranked, item.PricingRule, item.ReservePrice)
+
+		if len(allocations) == 0 {
+			return leaders, models.Bid{}, nil
+		}
+
+		for _, allocation := range allocations {
+			leaders[allocation.BidderID] = true
+		}
+
+		last := allocations[len(allocations)-1]
+
+		return leaders, models.Bid{ID: last.BidID, ItemID: item.ID, BidderID: last.BidderID, Amount: last.UnitPrice}, nil
+	}
+
+	var lead models.Bid
+	var err error
+
+	if item.AuctionType == models.AuctionTypeReverse {
+		lead, err = b.BidRepository.GetLowestBidTx(tx, item.ID)
+	} else {
+		lead, err = b.BidRepository.GetHighestBidTx(tx, item.ID)
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return leaders, lead, nil
+	}
+
+	if err != nil {
+		return nil, lead, err
+	}
+
+	leaders[lead.BidderID] = true
+
+	return leaders, lead, nil
+}
+
+// Record an Outbid event for every bidder who was winning the item before the bid and no longer is
+func (b *BidService) recordOutbid(tx pgx.Tx, item models.Item, previous map[int64]bool) error {
+	if len(previous) == 0 {
+		return nil
+	}
+
+	leaders, lead, err := b.currentLeaders(tx, item)
+
+	if err != nil {
+		return err
+	}
+
+	for bidderID := range previous {
+		if leaders[bidderID] {
+			continue
+		}
+
+		outbid := models.Outbid{ItemID: item.ID, ItemName: item.ItemName, BidderID: bidderID, LeadingBidID: lead.ID, LeadingAmount: lead.Amount, Currency: item.Currency}
+
+		err := b.Outbox.RecordItemEvent(tx, events.Outbid, item.ID, outbid)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Lock an item for the rest of the transaction and make sure the user may bid on it right now
+func (b *BidService) lockBiddableItem(tx pgx.Tx, itemID int64, bidderID int64, now time.Time) (models.Item, error) {
+	item, err := b.ItemRepository.GetItemByIDForUpdate(tx, itemID)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return item, ErrItemNotFound
+	}
+
+	if err != nil {
+		return item, err
+	}
+
+	if item.AuctionedBy == bidderID {
+		return item, ErrOwnItemBid
+	}
+
+	if effectiveStatus(item, now) != models.ItemStatusLive {
+		return item, ErrAuctionNotLive
+	}
+
+	return item, nil
+}
+
+// Buy an item outright at its buy-now price, ending the auction immediately.
+// The item row is locked the same way PlaceBid locks it, so a buy-now and a bid can never both win.
+func (b *BidService) BuyNow(itemID int64, buyerID int64) (models.Item, error) {
+	var soldItem models.Item
+
+	err := repositories.WithTx(b.BidRepository.DB, func(tx pgx.Tx) error {
+		now := time.Now()
+
+		item, err := b.lockBiddableItem(tx, itemID, buyerID, now)
+
+		if err != nil {
+			return err
+		}
+
+		if item.BuyNowPrice == nil || (item.BidCount > 0 && item.BidAmount >= item.BuyNowPrice.MulBasisPoints(b.Rules.BuyNowThresholdBps, item.Currency)) {
+			return ErrBuyNowUnavailable
+		}
+
+		sale, err := b.BidRepository.CreateBid(tx, models.Bid{ItemID: item.ID, BidderID: buyerID, Amount: *item.BuyNowPrice, Kind: models.BidKindBuyNow})
+
+		if err != nil {
+			return err
+		}
+
+		err = b.ItemRepository.RecordBid(tx, item.ID, sale.Amount)
+
+		if err != nil {
+			return err
+		}
+
+		soldItem, err = b.settleSale(tx, item, sale, now)
+
+		return err
+	})
+
+	if err != nil {
+		log.Printf("Error buying item: %v", err)
+		return soldItem, err
+	}
+
+	b.Outbox.Wake()
+
+	return presentItem(soldItem, buyerID, time.Now()), nil
+}
+
+// Keep funds on hold for every bid that can still win and release the holds of bidders who were outbid.
+// A bidder only ever has funds taken for their own bid, other holds are never raised by it.
+func (b *BidService) holdFunds(tx pgx.Tx, item models.Item, bidderID int64, request models.PlaceBidRequest, placement models.BidPlacement) error {
+	switch {
+	case item.AuctionType == models.AuctionTypeReverse:
+		// Bidders in a reverse auction are the ones being paid
+		return nil
+	case item.Quantity > 1:
+		ranked, err := b.BidRepository.GetTopBidsTx(tx, item.ID, 0)
+
+		if err != nil {
+			return err
+		}
+
+		// Pay-as-bid is what a winner could owe at most under either pricing rule
+		owed := make(map[int64]models.Money)
+
+		for _, allocation := range models.AllocateUnits(item.Quantity, ranked, models.PricingRulePayAsBid, item.ReservePrice) {
+			owed[allocation.BidderID] += allocation.TotalPrice
+		}
+
+		if amount, ok := owed[bidderID]; ok {
+			err := b.WalletService.Reserve(tx, bidderID, item, amount)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		// The new bid can take units from other bidders, whose holds shrink with their allocations
+		return b.WalletService.TrimHolds(tx, item.ID, owed)
+	case item.AuctionType.IsSealed():
+		// Every sealed bid can still win until the close
+		return b.WalletService.Reserve(tx, bidderID, item, placement.Bid.Amount)
+	case !placement.Leading:
+		return nil
+	}
+
+	commitment := request.Amount
+
+	if request.MaxAmount != nil {
+		commitment = *request.MaxAmount
+	}
+
+	err := b.WalletService.Reserve(tx, bidderID, item, commitment)
+
+	if err != nil {
+		return err
+	}
+
+	return b.WalletService.ReleaseHolds(tx, item.ID, bidderID)
+}
+
+// Close an item sold outright to a buy-now or accept bid, taking the price from the buyer's wallet
+// and recording the order in the same transaction
+func (b *BidService) settleSale(tx pgx.Tx, item models.Item, sale models.Bid, now time.Time) (models.Item, error) {
+	err := b.WalletService.Reserve(tx, sale.BidderID, item, sale.Amount)
+
+	if err != nil {
+		return item, err
+	}
+
+	soldItem, err := b.ItemRepository.CloseItem(tx, item.ID, models.ItemStatusSold, &sale.BidderID, &sale.ID, &sale.Amount, now)
+
+	if err != nil {
+		return soldItem, err
+	}
+
+	err = b.WalletService.Settle(tx, soldItem, nil)
+
+	if err != nil {
+		return soldItem, err
+	}
+
+	_, err = b.OrderService.CreateOrders(tx, soldItem, nil)
+
+	if err != nil {
+		return soldItem, err
+	}
+
+	return soldItem, b.Outbox.RecordItemEvent(tx, events.ItemClosed, soldItem.ID, soldItem)
+}
+
+// Check whether a bid placed now falls inside the soft close window of a live item
+// that still has extensions left
+func (b *BidService) shouldExtend(item models.Item, now time.Time) bool {
+	if item.EndsAt == nil || b.Rules.SoftCloseExtension <= 0 || item.ExtensionCount >= b.Rules.MaxExtensions {
+		return false
+	}
+
+	return item.EndsAt.Sub(now) <= b.Rules.SoftCloseWindow
+}
+
+// Retrieve a page of an item's bid history, newest first, with bidder usernames resolved
+func (b *BidService) GetBidHistory(itemID int64, page, limit int) (models.BidPage, error) {
+	bidPage := models.BidPage{Bids: []models.Bid{}, Page: page, Limit: limit}
+
+	item, err := b.ItemRepository.GetItemByID(itemID)
+
+	if err != nil {
+		log.Printf("Error retrieving bid history: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return bidPage, ErrItemNotFound
+		}
+		return bidPage, err
+	}
+
+	if isSealed(item) {
+		return bidPage, ErrBidsSealed
+	}
+
+	total, err := b.BidRepository.CountBidsByItemID(itemID)
+
+	if err != nil {
+		log.Printf("Error retrieving bid history: %v", err)
+		return bidPage, err
+	}
+
+	bids, err := b.BidRepository.GetBidsByItemID(itemID, limit, (page-1)*limit)
+
+	if err != nil {
+		log.Printf("Error retrieving bid history: %v", err)
+		return bidPage, err
+	}
+
+	err = b.resolveBidderUsernames(bids)
+
+	if err != nil {
+		log.Printf("Error retrieving bid history: %v", err)
+		return bidPage, err
+	}
+
+	bidPage.Total = total
+
+	if bids != nil {
+		bidPage.Bids = bids
+	}
+
+	return bidPage, nil
+}
+
+// Retrieve the current high bid of an item together with its bidder
+func (b *BidService) GetLeadingBid(itemID int64) (models.Bid, error) {
+	item, err := b.ItemRepository.GetItemByID(itemID)
+
+	if err != nil {
+		log.Printf("Error retrieving leading bid: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Bid{}, ErrItemNotFound
+		}
+		return models.Bid{}, err
+	}
+
+	if isSealed(item) {
+		return models.Bid{}, ErrBidsSealed
+	}
+
+	leader, err := b.BidRepository.GetLeadingBid(itemID, item.AuctionType == models.AuctionTypeReverse)
+
+	if err != nil {
+		log.Printf("Error retrieving leading bid: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return leader, ErrNoBids
+		}
+		return leader, err
+	}
+
+	bids := []models.Bid{leader}
+
+	err = b.resolveBidderUsernames(bids)
+
+	if err != nil {
+		log.Printf("Error retrieving leading bid: %v", err)
+		return leader, err
+	}
+
+	return bids[0], nil
+}
+
+// Check whether an item's bids are still hidden, sealed bids are revealed once the auction has closed
+func isSealed(item models.Item) bool {
+	return item.AuctionType.IsSealed() && item.ClosedAt == nil
+}
+
+// Fill in the bidder username of every bid with a single user lookup
+func (b *BidService) resolveBidderUsernames(bids []models.Bid) error {
+	if len(bids) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(bids))
+
+	for _, bid := range bids {
+		ids = append(ids, bid.BidderID)
+	}
+
+	usernames, err := b.UserRepository.GetUsernamesByIDs(ids)
+
+	if err != nil {
+		return err
+	}
+
+	for i := range bids {
+		bids[i].BidderUsername = usernames[bids[i].BidderID]
+	}
+
+	return nil
+}
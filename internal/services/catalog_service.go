@@ -0,0 +1,224 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/repositories"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrCatalogNotFound    = errors.New("catalog not found")
+	ErrCatalogNotEditable = errors.New("catalog has already started")
+	ErrLotNotSchedulable  = errors.New("only lots that are not live and not in another catalog can be added")
+	ErrInvalidLotPricing  = errors.New("lot pricing is not valid")
+	ErrCatalogLotTiming   = errors.New("the times of a catalog lot are set by its catalog")
+)
+
+// CatalogService runs lots as one sale with staggered closing.
+// Adding a lot to a catalog schedules its auction item, the end time follows from the lot's place in the catalog.
+type CatalogService struct {
+	CatalogRepository *repositories.CatalogRepository
+	LotRepository     *repositories.LotRepository
+	ItemRepository    *repositories.ItemRepository
+}
+
+func NewCatalogService(CatalogRepository *repositories.CatalogRepository, LotRepository *repositories.LotRepository, ItemRepository *repositories.ItemRepository) *CatalogService {
+	return &CatalogService{CatalogRepository, LotRepository, ItemRepository}
+}
+
+// Create a catalog and schedule its lots in the order given
+func (c *CatalogService) CreateCatalog(sellerID int64, request models.CreateCatalogRequest) (models.Catalog, error) {
+	var catalog models.Catalog
+
+	if !request.FirstLotEndsAt.After(time.Now()) {
+		return catalog, ErrInvalidAuctionWindow
+	}
+
+	err := repositories.WithTx(c.CatalogRepository.DB, func(tx pgx.Tx) error {
+		var err error
+
+		catalog, err = c.CatalogRepository.CreateCatalog(tx, models.Catalog{
+			Name:            request.Name,
+			SellerID:        sellerID,
+			StartsAt:        *request.StartsAt,
+			FirstLotEndsAt:  *request.FirstLotEndsAt,
+			StaggerInterval: request.StaggerInterval,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		return c.scheduleLots(tx, catalog, request.LotIDs)
+	})
+
+	if err != nil {
+		log.Printf("Error creating catalog: %v", err)
+		return catalog, err
+	}
+
+	return c.GetCatalogByID(catalog.ID, sellerID)
+}
+
+// Replace the lots of a catalog and their closing order, only until the catalog starts.
+// Lots taken out of the catalog go back to draft.
+func (c *CatalogService) SetCatalogLots(catalogID int64, userID int64, lotIDs []int64) (models.Catalog, error) {
+	err := repositories.WithTx(c.CatalogRepository.DB, func(tx pgx.Tx) error {
+		catalog, err := c.CatalogRepository.GetCatalogByIDForUpdate(tx, catalogID)
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrCatalogNotFound
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if catalog.SellerID != userID {
+			return ErrNotItemOwner
+		}
+
+		if !time.Now().Before(catalog.StartsAt) {
+			return ErrCatalogNotEditable
+		}
+
+		released, err := c.LotRepository.ReleaseCatalogLots(tx, catalog.ID)
+
+		if err != nil {
+			return err
+		}
+
+		for _, itemID := range released {
+			_, err := c.ItemRepository.UpdateItemStatus(tx, itemID, models.ItemStatusDraft)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return c.scheduleLots(tx, catalog, lotIDs)
+	})
+
+	if err != nil {
+		log.Printf("Error updating catalog lots: %v", err)
+		return models.Catalog{}, err
+	}
+
+	return c.GetCatalogByID(catalogID, userID)
+}
+
+// Lock each lot and its auction item, put the lot into the catalog and schedule it to close at its slot
+func (c *CatalogService) scheduleLots(tx pgx.Tx, catalog models.Catalog, lotIDs []int64) error {
+	now := time.Now()
+
+	for position, lotID := range lotIDs {
+		lot, err := c.LotRepository.GetLotByIDForUpdate(tx, lotID)
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrLotNotFound
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if lot.SellerID != catalog.SellerID {
+			return ErrNotItemOwner
+		}
+
+		if lot.CatalogID != nil {
+			return ErrLotNotSchedulable
+		}
+
+		auction, err := c.ItemRepository.GetItemByIDForUpdate(tx, lot.ItemID)
+
+		if err != nil {
+			return err
+		}
+
+		status := effectiveStatus(auction, now)
+
+		if status != models.ItemStatusDraft && status != models.ItemStatusScheduled {
+			return ErrLotNotSchedulable
+		}
+
+		err = validatePricing(auction)
+
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidLotPricing, err)
+		}
+
+		err = c.LotRepository.AssignLotToCatalog(tx, lot.ID, catalog.ID, position+1)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = c.ItemRepository.ScheduleItem(tx, auction.ID, catalog.StartsAt, catalog.LotEndsAt(position))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Retrieve every catalog without its lots
+func (c *CatalogService) GetCatalogs() ([]models.Catalog, error) {
+	catalogs, err := c.CatalogRepository.GetCatalogs()
+
+	if err != nil {
+		log.Printf("Error retrieving catalogs: %v", err)
+		return nil, err
+	}
+
+	if catalogs == nil {
+		catalogs = []models.Catalog{}
+	}
+
+	return catalogs, nil
+}
+
+// Retrieve a catalog with its lots in closing order, each with its auction item
+func (c *CatalogService) GetCatalogByID(catalogID int64, viewerID int64) (models.Catalog, error) {
+	catalog, err := c.CatalogRepository.GetCatalogByID(catalogID)
+
+	if err != nil {
+		log.Printf("Error retrieving catalog: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return catalog, ErrCatalogNotFound
+		}
+		return catalog, err
+	}
+
+	lots, err := c.LotRepository.GetCatalogLots(catalog.ID)
+
+	if err != nil {
+		log.Printf("Error retrieving catalog: %v", err)
+		return catalog, err
+	}
+
+	now := time.Now()
+	catalog.Lots = []models.Lot{}
+
+	for _, lot := range lots {
+		auction, err := c.ItemRepository.GetItemByID(lot.ItemID)
+
+		if err != nil {
+			log.Printf("Error retrieving catalog: %v", err)
+			return catalog, err
+		}
+
+		auction = presentItem(auction, viewerID, now)
+		lot.Auction = &auction
+		catalog.Lots = append(catalog.Lots, lot)
+	}
+
+	return catalog, nil
+}
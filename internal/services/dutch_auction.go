@@ -0,0 +1,59 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/repositories"
+	"github.com/jackc/pgx/v5"
+)
+
+// Accept the current price of a dutch auction, ending it immediately.
+// The item row is locked before the price is computed, so when several bidders accept at once
+// the first to take the lock wins and everyone after them finds the auction already sold.
+func (b *BidService) AcceptPrice(itemID int64, buyerID int64) (models.Item, error) {
+	var soldItem models.Item
+
+	err := repositories.WithTx(b.BidRepository.DB, func(tx pgx.Tx) error {
+		now := time.Now()
+
+		item, err := b.lockBiddableItem(tx, itemID, buyerID, now)
+
+		if err != nil {
+			return err
+		}
+
+		if item.AuctionType != models.AuctionTypeDutch {
+			return ErrWrongAuctionType
+		}
+
+		price, _ := item.DutchPriceAt(now)
+
+		sale, err := b.BidRepository.CreateBid(tx, models.Bid{ItemID: item.ID, BidderID: buyerID, Amount: price, Kind: models.BidKindAccept})
+
+		if err != nil {
+			return err
+		}
+
+		// The start price is kept in bid_amount, so only the bid count is recorded
+		err = b.ItemRepository.RecordBid(tx, item.ID, 0)
+
+		if err != nil {
+			return err
+		}
+
+		soldItem, err = b.settleSale(tx, item, sale, now)
+
+		return err
+	})
+
+	if err != nil {
+		log.Printf("Error accepting price: %v", err)
+		return soldItem, err
+	}
+
+	b.Outbox.Wake()
+
+	return presentItem(soldItem, buyerID, time.Now()), nil
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"github.com/bangueco/auction-api/internal/events"
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/repositories"
+)
+
+// EventResolver loads the data of events another instance notified this one of
+type EventResolver struct {
+	ItemRepository *repositories.ItemRepository
+	BidRepository  *repositories.BidRepository
+}
+
+func NewEventResolver(ItemRepository *repositories.ItemRepository, BidRepository *repositories.BidRepository) *EventResolver {
+	return &EventResolver{ItemRepository, BidRepository}
+}
+
+// Bids are referenced by their ID, item events only need the item
+func (er *EventResolver) Reference(event events.Event) int64 {
+	if bid, ok := event.Data.(models.Bid); ok {
+		return bid.ID
+	}
+
+	return 0
+}
+
+// Item events carry the item as it is now, which may be ahead of the event when more happened since
+func (er *EventResolver) Resolve(eventType events.EventType, itemID int64, refID int64) (any, error) {
+	switch eventType {
+	case events.BidPlaced:
+		return er.BidRepository.GetItemBid(itemID, refID)
+	case events.ItemClosed, events.ItemExtended:
+		return er.ItemRepository.GetItemByID(itemID)
+	default:
+		return nil, events.ErrUnknownEvent
+	}
+}
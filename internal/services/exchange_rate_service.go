@@ -0,0 +1,156 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/repositories"
+	"github.com/jackc/pgx/v5"
+)
+
+// ExchangeRateService converts item amounts into other currencies for display.
+// Rates are kept in the database so every API instance converts with the same rates,
+// they never take part in placing or comparing bids.
+type ExchangeRateService struct {
+	ExchangeRateRepository *repositories.ExchangeRateRepository
+}
+
+func NewExchangeRateService(ExchangeRateRepository *repositories.ExchangeRateRepository) *ExchangeRateService {
+	return &ExchangeRateService{ExchangeRateRepository}
+}
+
+// Retrieve every exchange rate
+func (e *ExchangeRateService) GetExchangeRates() ([]models.ExchangeRate, error) {
+	rates, err := e.ExchangeRateRepository.GetExchangeRates()
+
+	if err != nil {
+		log.Printf("Error retrieving exchange rates: %v", err)
+		return nil, err
+	}
+
+	if rates == nil {
+		rates = []models.ExchangeRate{}
+	}
+
+	return rates, nil
+}
+
+// Replace every exchange rate with a new set
+func (e *ExchangeRateService) SetExchangeRates(rates models.ExchangeRates) ([]models.ExchangeRate, error) {
+	err := rates.Validate()
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = repositories.WithTx(e.ExchangeRateRepository.DB, func(tx pgx.Tx) error {
+		return e.ExchangeRateRepository.ReplaceExchangeRates(tx, rates)
+	})
+
+	if err != nil {
+		log.Printf("Error saving exchange rates: %v", err)
+		return nil, err
+	}
+
+	return e.GetExchangeRates()
+}
+
+// Replace every exchange rate with the rates in a JSON file
+func (e *ExchangeRateService) LoadFile(path string) error {
+	var rates models.ExchangeRates
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(data, &rates)
+
+	if err != nil {
+		return fmt.Errorf("%w: %v", models.ErrInvalidExchangeRates, err)
+	}
+
+	_, err = e.SetExchangeRates(rates)
+
+	return err
+}
+
+// Fill in the converted amounts of every item for a display currency.
+// Items priced in a currency without a rate are left unconverted.
+func (e *ExchangeRateService) ConvertItems(items []models.Item, currency string) error {
+	rates, err := e.rates()
+
+	if err != nil {
+		return err
+	}
+
+	target, ok := rates[currency]
+
+	if !ok {
+		return fmt.Errorf("%w %s", models.ErrUnknownCurrency, currency)
+	}
+
+	for i := range items {
+		source, ok := rates[items[i].Currency]
+
+		if !ok {
+			continue
+		}
+
+		rate := new(big.Rat).Quo(target, source)
+
+		converted := &models.ConvertedAmounts{
+			Currency:  currency,
+			Rate:      models.FormatRate(rate),
+			BidAmount: items[i].BidAmount.Convert(rate, currency),
+		}
+
+		if items[i].ReservePrice != nil {
+			reserve := items[i].ReservePrice.Convert(rate, currency)
+			converted.ReservePrice = &reserve
+		}
+
+		if items[i].BuyNowPrice != nil {
+			buyNow := items[i].BuyNowPrice.Convert(rate, currency)
+			converted.BuyNowPrice = &buyNow
+		}
+
+		if items[i].CurrentPrice != nil {
+			current := items[i].CurrentPrice.Convert(rate, currency)
+			converted.CurrentPrice = &current
+		}
+
+		items[i].Converted = converted
+	}
+
+	return nil
+}
+
+// Load every rate keyed by currency
+func (e *ExchangeRateService) rates() (map[string]*big.Rat, error) {
+	stored, err := e.ExchangeRateRepository.GetExchangeRates()
+
+	if err != nil {
+		log.Printf("Error retrieving exchange rates: %v", err)
+		return nil, err
+	}
+
+	rates := make(map[string]*big.Rat, len(stored))
+
+	for _, rate := range stored {
+		value, ok := new(big.Rat).SetString(rate.Rate)
+
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", models.ErrInvalidExchangeRates, rate.Currency)
+		}
+
+		rates[rate.Currency] = value
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"log"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/repositories"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrIncrementTableNotFound = errors.New("increment table not found")
+
+// IncrementService decides how far apart consecutive bids have to be.
+// An auction's own table wins over its category's table, which wins over the default table from the config.
+type IncrementService struct {
+	IncrementRepository *repositories.IncrementRepository
+	Default             models.IncrementTable
+}
+
+func NewIncrementService(IncrementRepository *repositories.IncrementRepository, Default models.IncrementTable) *IncrementService {
+	return &IncrementService{IncrementRepository, Default}
+}
+
+// Retrieve the default table together with every category table
+func (i *IncrementService) GetIncrementTables() (models.IncrementTables, error) {
+	tables := models.IncrementTables{Default: i.Default, Categories: map[string]models.IncrementTable{}}
+
+	categoryTables, err := i.IncrementRepository.GetCategoryIncrements()
+
+	if err != nil {
+		log.Printf("Error retrieving increment tables: %v", err)
+		return tables, err
+	}
+
+	for _, table := range categoryTables {
+		tables.Categories[table.Category] = table.Tiers
+	}
+
+	return tables, nil
+}
+
+// Create or replace the increment table of a category
+func (i *IncrementService) SetCategoryIncrements(table models.CategoryIncrements) (models.CategoryIncrements, error) {
+	err := table.Tiers.Validate()
+
+	if err != nil {
+		return table, err
+	}
+
+	saved, err := i.IncrementRepository.UpsertCategoryIncrements(table)
+
+	if err != nil {
+		log.Printf("Error saving increment table: %v", err)
+		return saved, err
+	}
+
+	return saved, nil
+}
+
+// Delete the increment table of a category
+func (i *IncrementService) DeleteCategoryIncrements(category string) error {
+	deleted, err := i.IncrementRepository.DeleteCategoryIncrements(category)
+
+	if err != nil {
+		log.Printf("Error deleting increment table: %v", err)
+		return err
+	}
+
+	if deleted == 0 {
+		return ErrIncrementTableNotFound
+	}
+
+	return nil
+}
+
+// Return the increment table that applies to an item, with steps no finer than the item's currency allows
+func (i *IncrementService) TableFor(item models.Item) (models.IncrementTable, error) {
+	if len(item.IncrementTable) > 0 {
+		return item.IncrementTable.InCurrency(item.Currency), nil
+	}
+
+	if item.Category != nil {
+		table, err := i.IncrementRepository.GetCategoryIncrementsByCategory(*item.Category)
+
+		if err == nil {
+			return table.InCurrency(item.Currency), nil
+		}
+
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+	}
+
+	return i.Default.InCurrency(item.Currency), nil
+}
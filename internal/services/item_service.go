@@ -3,6 +3,8 @@ package services
 import (
 	"errors"
 	"log"
+	"slices"
+	"time"
 
 	"github.com/bangueco/auction-api/internal/models"
 	"github.com/bangueco/auction-api/internal/repositories"
@@ -10,20 +12,166 @@ import (
 )
 
 var (
-	ErrItemNotFound  = errors.New("item not found")
-	ErrItemsNotFound = errors.New("items not found")
+	ErrItemNotFound         = errors.New("item not found")
+	ErrItemsNotFound        = errors.New("items not found")
+	ErrNotItemOwner         = errors.New("item is not owned by the user")
+	ErrItemNotEditable      = errors.New("item can no longer be edited")
+	ErrItemNotDeletable     = errors.New("item can no longer be deleted")
+	ErrInvalidTransition    = errors.New("invalid item status transition")
+	ErrInvalidAuctionWindow = errors.New("auction must have a start time and an end time in the future")
+	ErrAuctionNotLive       = errors.New("auction is not live")
+	ErrInvalidBuyNowPrice   = errors.New("buy-now price must be above the starting bid and the reserve price")
+	ErrInvalidDutchSchedule = errors.New("dutch auctions need a floor price below the start price, a price drop and a drop interval, and no reserve or buy-now price")
+	ErrEnglishOnlyPricing   = errors.New("buy-now prices and increment tables are only available in english auctions")
+	ErrInvalidReversePrice  = errors.New("reverse auctions have no reserve or buy-now price")
+	ErrInvalidMultiUnit     = errors.New("multi-unit auctions must be english auctions without a buy-now price")
 )
 
+// Legal status transitions of an item, keyed by the status it is currently in
+var itemTransitions = map[models.ItemStatus][]models.ItemStatus{
+	models.ItemStatusDraft:     {models.ItemStatusScheduled, models.ItemStatusCancelled},
+	models.ItemStatusScheduled: {models.ItemStatusDraft, models.ItemStatusLive, models.ItemStatusEnded, models.ItemStatusUnsold, models.ItemStatusCancelled},
+	models.ItemStatusLive:      {models.ItemStatusEnded, models.ItemStatusUnsold, models.ItemStatusSold, models.ItemStatusCancelled},
+	models.ItemStatusEnded:     {models.ItemStatusSold},
+}
+
+// Transitions a seller may request themselves, the rest are driven by time and the bidding process
+var sellerTransitions = []models.ItemStatus{models.ItemStatusDraft, models.ItemStatusScheduled, models.ItemStatusCancelled}
+
 type ItemService struct {
 	ItemRepository *repositories.ItemRepository
+	BidRepository  *repositories.BidRepository
+	UserRepository *repositories.UserRepository
+	LotRepository  *repositories.LotRepository
+}
+
+func NewItemService(ItemRepository *repositories.ItemRepository, BidRepository *repositories.BidRepository, UserRepository *repositories.UserRepository, LotRepository *repositories.LotRepository) *ItemService {
+	return &ItemService{ItemRepository, BidRepository, UserRepository, LotRepository}
+}
+
+// Check whether an item may move from one status to another
+func canTransition(from, to models.ItemStatus) bool {
+	return slices.Contains(itemTransitions[from], to)
+}
+
+// Return the status an item is in at the given time.
+// Scheduled items go live once their start time has passed and live items stop taking bids
+// once their end time has passed, even before the stored status has caught up.
+func effectiveStatus(item models.Item, now time.Time) models.ItemStatus {
+	switch {
+	case item.Status == models.ItemStatusScheduled && item.StartsAt != nil && !now.Before(*item.StartsAt):
+		if item.EndsAt != nil && !now.Before(*item.EndsAt) {
+			return models.ItemStatusEnded
+		}
+		return models.ItemStatusLive
+	case item.Status == models.ItemStatusLive && item.EndsAt != nil && !now.Before(*item.EndsAt):
+		return models.ItemStatusEnded
+	}
+
+	return item.Status
+}
+
+// Prepare an item for the given viewer.
+// The status reflects the current time, and the reserve price is hidden from everyone but the seller,
+// who are only told whether the reserve has been met.
+func presentItem(item models.Item, viewerID int64, now time.Time) models.Item {
+	item.Status = effectiveStatus(item, now)
+	item.HasReserve = item.ReservePrice != nil
+	item.ReserveMet = !item.HasReserve || (item.BidCount > 0 && item.BidAmount >= *item.ReservePrice) || (item.HammerPrice != nil && *item.HammerPrice >= *item.ReservePrice)
+
+	if item.AuctionedBy != viewerID {
+		item.ReservePrice = nil
+	}
+
+	if item.AuctionType == models.AuctionTypeDutch && item.Status == models.ItemStatusLive {
+		price, nextDropAt := item.DutchPriceAt(now)
+		item.CurrentPrice, item.NextPriceDropAt = &price, nextDropAt
+	}
+
+	return item
+}
+
+// Prepare an item for anyone who is not its seller, such as the subscribers of a public feed
+func PublicItem(item models.Item, now time.Time) models.Item {
+	return presentItem(item, 0, now)
+}
+
+// Check that every amount can be paid in the item's currency, that the buy-now price, when set,
+// is worth more than bidding would start at, that a custom increment table is usable
+// and that a dutch auction has a complete price schedule
+func validatePricing(item models.Item) error {
+	for _, amount := range []*models.Money{&item.BidAmount, item.ReservePrice, item.BuyNowPrice, item.FloorPrice, item.PriceDrop} {
+		if amount != nil && !amount.FitsCurrency(item.Currency) {
+			return models.ErrInvalidMoneyForCurrency
+		}
+	}
+
+	if item.AuctionType == models.AuctionTypeDutch {
+		if item.FloorPrice == nil || item.PriceDrop == nil || item.PriceDropInterval == nil || *item.FloorPrice >= item.BidAmount {
+			return ErrInvalidDutchSchedule
+		}
+
+		if item.ReservePrice != nil || item.BuyNowPrice != nil || len(item.IncrementTable) > 0 {
+			return ErrInvalidDutchSchedule
+		}
+
+		return nil
+	}
+
+	if item.Quantity > 1 && (item.AuctionType != models.AuctionTypeEnglish || item.BuyNowPrice != nil) {
+		return ErrInvalidMultiUnit
+	}
+
+	if item.AuctionType == models.AuctionTypeReverse && (item.ReservePrice != nil || item.BuyNowPrice != nil) {
+		return ErrInvalidReversePrice
+	}
+
+	if item.AuctionType.IsSealed() && (item.BuyNowPrice != nil || len(item.IncrementTable) > 0) {
+		return ErrEnglishOnlyPricing
+	}
+
+	if len(item.IncrementTable) > 0 {
+		err := item.IncrementTable.Validate()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if item.BuyNowPrice == nil {
+		return nil
+	}
+
+	if *item.BuyNowPrice <= item.BidAmount || (item.ReservePrice != nil && *item.BuyNowPrice < *item.ReservePrice) {
+		return ErrInvalidBuyNowPrice
+	}
+
+	return nil
 }
 
-func NewItemService(ItemRepository *repositories.ItemRepository) *ItemService {
-	return &ItemService{ItemRepository}
+// Default an item to a single unit english auction and drop the dutch price schedule from any other type
+func withAuctionType(item models.Item) models.Item {
+	if item.AuctionType == "" {
+		item.AuctionType = models.AuctionTypeEnglish
+	}
+
+	if item.Quantity == 0 {
+		item.Quantity = 1
+	}
+
+	if item.PricingRule == "" {
+		item.PricingRule = models.PricingRuleUniform
+	}
+
+	if item.AuctionType != models.AuctionTypeDutch {
+		item.FloorPrice, item.PriceDrop, item.PriceDropInterval = nil, nil, nil
+	}
+
+	return item
 }
 
 // Retrieve all items from the database
-func (i *ItemService) GetItems() ([]models.Item, error) {
+func (i *ItemService) GetItems(viewerID int64) ([]models.Item, error) {
 	items, err := i.ItemRepository.GetItems()
 
 	if err != nil {
@@ -36,11 +184,17 @@ func (i *ItemService) GetItems() ([]models.Item, error) {
 		return nil, ErrItemsNotFound
 	}
 
+	now := time.Now()
+
+	for idx := range items {
+		items[idx] = presentItem(items[idx], viewerID, now)
+	}
+
 	return items, err
 }
 
 // Retrieve a single item from the database by its ID
-func (i *ItemService) GetItemByID(id int64) (models.Item, error) {
+func (i *ItemService) GetItemByID(id int64, viewerID int64) (models.Item, error) {
 	existingItem, err := i.ItemRepository.GetItemByID(id)
 
 	if err != nil {
@@ -48,13 +202,32 @@ func (i *ItemService) GetItemByID(id int64) (models.Item, error) {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return existingItem, ErrItemNotFound
 		}
+		return existingItem, err
 	}
 
-	return existingItem, err
+	return presentItem(existingItem, viewerID, time.Now()), nil
 }
 
-// Create a new item in the database
+// Create a new item in the database, every item starts out as a draft
 func (i *ItemService) CreateItem(item models.Item) (models.Item, error) {
+	item.Status = models.ItemStatusDraft
+	item = withAuctionType(item)
+
+	seller, err := i.UserRepository.GetUserByID(item.AuctionedBy)
+
+	if err != nil {
+		log.Printf("Error getting seller: %v", err)
+		return item, err
+	}
+
+	item.Currency = seller.Currency
+
+	err = validatePricing(item)
+
+	if err != nil {
+		return item, err
+	}
+
 	newItem, err := i.ItemRepository.CreateItem(item)
 
 	if err != nil {
@@ -62,38 +235,202 @@ func (i *ItemService) CreateItem(item models.Item) (models.Item, error) {
 		return newItem, err
 	}
 
-	return newItem, nil
+	return presentItem(newItem, newItem.AuctionedBy, time.Now()), nil
 }
 
-// Update an existing item in the database
-func (i *ItemService) UpdateItem(itemId int64, data models.Item) (models.Item, error) {
-	existingItem, err := i.GetItemByID(itemId)
+// Update an existing item in the database.
+// Only the seller may edit an item, and only until it has ended or a bid has been placed on it.
+func (i *ItemService) UpdateItem(itemId int64, userId int64, data models.Item) (models.Item, error) {
+	var newItem models.Item
 
-	if err != nil {
-		log.Printf("Error updating item: %v", err)
-		return existingItem, err
-	}
+	err := repositories.WithTx(i.ItemRepository.DB, func(tx pgx.Tx) error {
+		existingItem, err := i.lockOwnedItem(tx, itemId, userId)
+
+		if err != nil {
+			return err
+		}
+
+		if existingItem.LotID != nil {
+			return ErrItemInLot
+		}
+
+		// A lot's auction was checked against its items when the lot was created, editing it here would skip that
+		isLot, err := i.LotRepository.IsLotAuctionItem(tx, itemId)
+
+		if err != nil {
+			return err
+		}
+
+		if isLot {
+			return ErrLotAuctionItem
+		}
+
+		status := effectiveStatus(existingItem, time.Now())
+
+		switch status {
+		case models.ItemStatusDraft, models.ItemStatusScheduled:
+		case models.ItemStatusLive:
+			hasBids, err := i.BidRepository.HasBids(tx, itemId)
+
+			if err != nil {
+				return err
+			}
+
+			if hasBids {
+				return ErrItemNotEditable
+			}
+		default:
+			return ErrItemNotEditable
+		}
+
+		data = withAuctionType(data)
+		data.Currency = existingItem.Currency
+
+		err = validatePricing(data)
+
+		if err != nil {
+			return err
+		}
+
+		if status != models.ItemStatusDraft && (data.StartsAt == nil || data.EndsAt == nil || !data.EndsAt.After(time.Now())) {
+			return ErrInvalidAuctionWindow
+		}
+
+		data.AuctionedBy = existingItem.AuctionedBy
+
+		newItem, err = i.ItemRepository.UpdateItem(tx, itemId, data)
 
-	newItem, err := i.ItemRepository.UpdateItem(itemId, data)
+		return err
+	})
 
 	if err != nil {
 		log.Printf("Error updating item: %v", err)
 		return newItem, err
 	}
 
-	return newItem, nil
+	return presentItem(newItem, userId, time.Now()), nil
 }
 
-// Delete an existing item from the database
-func (i *ItemService) DeleteItem(itemID int64) error {
-	_, err := i.GetItemByID(itemID)
+// Move an item to a new status on behalf of its seller
+func (i *ItemService) UpdateItemStatus(itemId int64, userId int64, status models.ItemStatus) (models.Item, error) {
+	var updatedItem models.Item
+
+	if !slices.Contains(sellerTransitions, status) {
+		return updatedItem, ErrInvalidTransition
+	}
+
+	err := repositories.WithTx(i.ItemRepository.DB, func(tx pgx.Tx) error {
+		existingItem, err := i.lockOwnedItem(tx, itemId, userId)
+
+		if err != nil {
+			return err
+		}
+
+		if existingItem.LotID != nil {
+			return ErrItemInLot
+		}
+
+		// Catalog lots are scheduled and put back to draft by their catalog
+		inCatalog, err := i.ItemRepository.IsCatalogLotItem(tx, itemId)
+
+		if err != nil {
+			return err
+		}
+
+		if inCatalog {
+			return ErrCatalogLotTiming
+		}
+
+		now := time.Now()
+		current := effectiveStatus(existingItem, now)
+
+		if !canTransition(current, status) {
+			return ErrInvalidTransition
+		}
+
+		if status == models.ItemStatusScheduled {
+			if existingItem.StartsAt == nil || existingItem.EndsAt == nil || !existingItem.EndsAt.After(now) {
+				return ErrInvalidAuctionWindow
+			}
+		}
+
+		if status == models.ItemStatusCancelled && current == models.ItemStatusLive {
+			hasBids, err := i.BidRepository.HasBids(tx, itemId)
+
+			if err != nil {
+				return err
+			}
+
+			if hasBids {
+				return ErrInvalidTransition
+			}
+		}
+
+		updatedItem, err = i.ItemRepository.UpdateItemStatus(tx, itemId, status)
+
+		if err != nil {
+			return err
+		}
+
+		// A cancelled lot hands its items back to the seller so they can be sold on their own
+		if status == models.ItemStatusCancelled {
+			return i.ItemRepository.ReleaseLotItemsByItemID(tx, itemId)
+		}
+
+		return nil
+	})
 
 	if err != nil {
-		log.Printf("Error deleting item: %v", err)
-		return err
+		log.Printf("Error updating item status: %v", err)
+		return updatedItem, err
 	}
 
-	err = i.ItemRepository.DeleteItem(itemID)
+	return presentItem(updatedItem, userId, time.Now()), nil
+}
+
+// Delete an existing item from the database.
+// Items that have taken bids or finished their auction are kept as a record of the sale.
+func (i *ItemService) DeleteItem(itemID int64, userId int64) error {
+	err := repositories.WithTx(i.ItemRepository.DB, func(tx pgx.Tx) error {
+		existingItem, err := i.lockOwnedItem(tx, itemID, userId)
+
+		if err != nil {
+			return err
+		}
+
+		if existingItem.LotID != nil {
+			return ErrItemInLot
+		}
+
+		isLot, err := i.LotRepository.IsLotAuctionItem(tx, itemID)
+
+		if err != nil {
+			return err
+		}
+
+		// Cancelling the lot hands its items back, deleting its auction would leave them behind
+		if isLot {
+			return ErrLotAuctionItem
+		}
+
+		switch effectiveStatus(existingItem, time.Now()) {
+		case models.ItemStatusDraft, models.ItemStatusScheduled, models.ItemStatusCancelled:
+		case models.ItemStatusLive:
+			hasBids, err := i.BidRepository.HasBids(tx, itemID)
+
+			if err != nil {
+				return err
+			}
+
+			if hasBids {
+				return ErrItemNotDeletable
+			}
+		default:
+			return ErrItemNotDeletable
+		}
+
+		return i.ItemRepository.DeleteItem(tx, itemID)
+	})
 
 	if err != nil {
 		log.Printf("Error deleting item: %v", err)
@@ -102,3 +439,22 @@ func (i *ItemService) DeleteItem(itemID int64) error {
 
 	return nil
 }
+
+// Lock an item for the rest of the transaction and make sure it belongs to the user
+func (i *ItemService) lockOwnedItem(tx pgx.Tx, itemID int64, userId int64) (models.Item, error) {
+	item, err := i.ItemRepository.GetItemByIDForUpdate(tx, itemID)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return item, ErrItemNotFound
+	}
+
+	if err != nil {
+		return item, err
+	}
+
+	if item.AuctionedBy != userId {
+		return item, ErrNotItemOwner
+	}
+
+	return item, nil
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/repositories"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrLotNotFound      = errors.New("lot not found")
+	ErrLotNotEditable   = errors.New("lot can no longer be changed")
+	ErrItemNotGroupable = errors.New("only draft items that are not in another lot can be grouped")
+	ErrItemInLot        = errors.New("item is sold as part of a lot")
+	ErrLotAuctionItem   = errors.New("the auction item of a lot is only changed through its lot")
+)
+
+// LotService groups items into lots that are auctioned as a whole.
+// Each lot gets an auction item of its own, so bidding, timing and closing reuse the item auction machinery,
+// and the grouped items take on the lot's outcome when it closes.
+type LotService struct {
+	LotRepository  *repositories.LotRepository
+	ItemRepository *repositories.ItemRepository
+	BidRepository  *repositories.BidRepository
+	UserRepository *repositories.UserRepository
+}
+
+func NewLotService(LotRepository *repositories.LotRepository, ItemRepository *repositories.ItemRepository, BidRepository *repositories.BidRepository, UserRepository *repositories.UserRepository) *LotService {
+	return &LotService{LotRepository, ItemRepository, BidRepository, UserRepository}
+}
+
+// Create a lot together with its auction item and group the items in the order given.
+// The auction item is checked the same way as any other item, and a time window, when given, has to be complete and end in the future.
+func (l *LotService) CreateLot(sellerID int64, request models.CreateLotRequest) (models.Lot, error) {
+	var lot models.Lot
+
+	if (request.StartsAt == nil) != (request.EndsAt == nil) || (request.EndsAt != nil && !request.EndsAt.After(time.Now())) {
+		return lot, ErrInvalidAuctionWindow
+	}
+
+	seller, err := l.UserRepository.GetUserByID(sellerID)
+
+	if err != nil {
+		log.Printf("Error creating lot: %v", err)
+		return lot, err
+	}
+
+	item := withAuctionType(models.Item{
+		ItemName:     request.Name,
+		BidAmount:    request.BidAmount,
+		AuctionedBy:  sellerID,
+		Status:       models.ItemStatusDraft,
+		Currency:     seller.Currency,
+		StartsAt:     request.StartsAt,
+		EndsAt:       request.EndsAt,
+		ReservePrice: request.ReservePrice,
+		Category:     request.Category,
+	})
+
+	err = validatePricing(item)
+
+	if err != nil {
+		return lot, err
+	}
+
+	err = repositories.WithTx(l.LotRepository.DB, func(tx pgx.Tx) error {
+		auction, err := l.ItemRepository.CreateItemTx(tx, item)
+
+		if err != nil {
+			return err
+		}
+
+		lot, err = l.LotRepository.CreateLot(tx, models.Lot{Name: request.Name, SellerID: sellerID, ItemID: auction.ID})
+
+		if err != nil {
+			return err
+		}
+
+		return l.groupItems(tx, lot, request.ItemIDs)
+	})
+
+	if err != nil {
+		log.Printf("Error creating lot: %v", err)
+		return lot, err
+	}
+
+	return l.GetLotByID(lot.ID, sellerID)
+}
+
+// Replace the items of a lot and their order, only until the lot's auction has taken a bid
+func (l *LotService) SetLotItems(lotID int64, userID int64, itemIDs []int64) (models.Lot, error) {
+	err := repositories.WithTx(l.LotRepository.DB, func(tx pgx.Tx) error {
+		lot, err := l.LotRepository.GetLotByIDTx(tx, lotID)
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrLotNotFound
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if lot.SellerID != userID {
+			return ErrNotItemOwner
+		}
+
+		// Locking the auction item serializes this with bids and the closer
+		auction, err := l.ItemRepository.GetItemByIDForUpdate(tx, lot.ItemID)
+
+		if err != nil {
+			return err
+		}
+
+		status := effectiveStatus(auction, time.Now())
+
+		if status != models.ItemStatusDraft && status != models.ItemStatusScheduled {
+			return ErrLotNotEditable
+		}
+
+		err = l.ItemRepository.ReleaseLotItems(tx, lot.ID)
+
+		if err != nil {
+			return err
+		}
+
+		return l.groupItems(tx, lot, itemIDs)
+	})
+
+	if err != nil {
+		log.Printf("Error updating lot items: %v", err)
+		return models.Lot{}, err
+	}
+
+	return l.GetLotByID(lotID, userID)
+}
+
+// Lock each item and put it into the lot at its position in itemIDs
+func (l *LotService) groupItems(tx pgx.Tx, lot models.Lot, itemIDs []int64) error {
+	for position, itemID := range itemIDs {
+		item, err := l.ItemRepository.GetItemByIDForUpdate(tx, itemID)
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrItemNotFound
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if item.AuctionedBy != lot.SellerID {
+			return ErrNotItemOwner
+		}
+
+		if item.Status != models.ItemStatusDraft || item.LotID != nil || item.ID == lot.ItemID {
+			return ErrItemNotGroupable
+		}
+
+		isLot, err := l.LotRepository.IsLotAuctionItem(tx, item.ID)
+
+		if err != nil {
+			return err
+		}
+
+		if isLot {
+			return ErrItemNotGroupable
+		}
+
+		err = l.ItemRepository.AssignItemToLot(tx, item.ID, lot.ID, position+1)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Retrieve a page of lots, newest first, each with its auction item and its items in lot order
+func (l *LotService) GetLots(viewerID int64, page, limit int) (models.LotPage, error) {
+	lotPage := models.LotPage{Lots: []models.Lot{}, Page: page, Limit: limit}
+
+	total, err := l.LotRepository.CountLots()
+
+	if err != nil {
+		log.Printf("Error retrieving lots: %v", err)
+		return lotPage, err
+	}
+
+	lots, err := l.LotRepository.GetLots(limit, (page-1)*limit)
+
+	if err != nil {
+		log.Printf("Error retrieving lots: %v", err)
+		return lotPage, err
+	}
+
+	lotPage.Total = total
+
+	if len(lots) == 0 {
+		return lotPage, nil
+	}
+
+	lotIDs := make([]int64, len(lots))
+	auctionIDs := make([]int64, len(lots))
+
+	for idx, lot := range lots {
+		lotIDs[idx] = lot.ID
+		auctionIDs[idx] = lot.ItemID
+	}
+
+	auctions, err := l.ItemRepository.GetItemsByIDs(auctionIDs)
+
+	if err != nil {
+		log.Printf("Error retrieving lots: %v", err)
+		return lotPage, err
+	}
+
+	items, err := l.ItemRepository.GetItemsByLotIDs(lotIDs)
+
+	if err != nil {
+		log.Printf("Error retrieving lots: %v", err)
+		return lotPage, err
+	}
+
+	now := time.Now()
+	auctionsByID := make(map[int64]models.Item, len(auctions))
+	itemsByLot := make(map[int64][]models.Item, len(lots))
+
+	for _, auction := range auctions {
+		auctionsByID[auction.ID] = presentItem(auction, viewerID, now)
+	}
+
+	for _, item := range items {
+		itemsByLot[*item.LotID] = append(itemsByLot[*item.LotID], presentItem(item, viewerID, now))
+	}
+
+	for idx := range lots {
+		if auction, ok := auctionsByID[lots[idx].ItemID]; ok {
+			lots[idx].Auction = &auction
+		}
+
+		lots[idx].Items = itemsByLot[lots[idx].ID]
+
+		if lots[idx].Items == nil {
+			lots[idx].Items = []models.Item{}
+		}
+	}
+
+	lotPage.Lots = lots
+
+	return lotPage, nil
+}
+
+// Retrieve a lot with its auction item and its items in lot order
+func (l *LotService) GetLotByID(lotID int64, viewerID int64) (models.Lot, error) {
+	lot, err := l.LotRepository.GetLotByID(lotID)
+
+	if err != nil {
+		log.Printf("Error retrieving lot: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return lot, ErrLotNotFound
+		}
+		return lot, err
+	}
+
+	auction, err := l.ItemRepository.GetItemByID(lot.ItemID)
+
+	if err != nil {
+		log.Printf("Error retrieving lot: %v", err)
+		return lot, err
+	}
+
+	items, err := l.ItemRepository.GetLotItems(lot.ID)
+
+	if err != nil {
+		log.Printf("Error retrieving lot: %v", err)
+		return lot, err
+	}
+
+	now := time.Now()
+	auction = presentItem(auction, viewerID, now)
+	lot.Auction = &auction
+	lot.Items = []models.Item{}
+
+	for _, item := range items {
+		lot.Items = append(lot.Items, presentItem(item, viewerID, now))
+	}
+
+	return lot, nil
+}
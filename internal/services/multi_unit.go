@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrInvalidQuantity = errors.New("bid quantity must be between 1 and the number of units for sale")
+
+// Place a bid for a number of units on a multi-unit auction inside the bid transaction.
+// While units are left over any bid at the starting price gets some, once every unit is spoken for
+// a new bid has to beat the lowest winning bid by one increment. Bids below the reserve win no units,
+// the same as at the close. Bids are never merged or raised by proxy.
+func (b *BidService) placeMultiUnitBid(tx pgx.Tx, item models.Item, bidderID int64, request models.PlaceBidRequest) (models.BidPlacement, []models.Bid, error) {
+	if request.MaxAmount != nil {
+		return models.BidPlacement{}, nil, ErrProxyUnavailable
+	}
+
+	increments, err := b.IncrementService.TableFor(item)
+
+	if err != nil {
+		return models.BidPlacement{}, nil, err
+	}
+
+	ranked, err := b.BidRepository.GetTopBidsTx(tx, item.ID, 0)
+
+	if err != nil {
+		return models.BidPlacement{}, nil, err
+	}
+
+	minimum := item.BidAmount
+	allocations := models.AllocateUnits(item.Quantity, ranked, models.PricingRulePayAsBid, item.ReservePrice)
+
+	if allocatedUnits(allocations) == item.Quantity {
+		lowest := allocations[len(allocations)-1].UnitPrice
+		minimum = lowest + increments.StepFor(lowest)
+	}
+
+	if request.Amount < minimum {
+		return models.BidPlacement{}, nil, &BidTooLowError{MinimumBid: minimum}
+	}
+
+	bid, err := b.BidRepository.CreateBid(tx, models.Bid{ItemID: item.ID, BidderID: bidderID, Amount: request.Amount, Kind: models.BidKindBid, Quantity: request.Quantity})
+
+	if err != nil {
+		return models.BidPlacement{}, nil, err
+	}
+
+	// The starting price stays in bid_amount, so only the bid count is recorded
+	err = b.ItemRepository.RecordBid(tx, item.ID, 0)
+
+	if err != nil {
+		return models.BidPlacement{}, nil, err
+	}
+
+	ranked, err = b.BidRepository.GetTopBidsTx(tx, item.ID, 0)
+
+	if err != nil {
+		return models.BidPlacement{}, nil, err
+	}
+
+	placement := models.BidPlacement{Bid: bid}
+	allocations = models.AllocateUnits(item.Quantity, ranked, item.PricingRule, item.ReservePrice)
+
+	for _, allocation := range allocations {
+		placement.Leading = placement.Leading || allocation.BidID == bid.ID
+		placement.CurrentBid = allocation.UnitPrice
+	}
+
+	return placement, []models.Bid{bid}, nil
+}
+
+// Count the units handed out by a set of allocations
+func allocatedUnits(allocations []models.Allocation) int {
+	units := 0
+
+	for _, allocation := range allocations {
+		units += allocation.Quantity
+	}
+
+	return units
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bangueco/auction-api/internal/events"
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/repositories"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// NotificationService keeps each user's inbox of outbid, won, lost, reserve-not-met and ending-soon notifications
+type NotificationService struct {
+	NotificationRepository *repositories.NotificationRepository
+	BidRepository          *repositories.BidRepository
+	AllocationRepository   *repositories.AllocationRepository
+	// How long before an auction ends its bidders are told it is ending soon
+	EndingSoonWindow time.Duration
+}
+
+func NewNotificationService(NotificationRepository *repositories.NotificationRepository, BidRepository *repositories.BidRepository, AllocationRepository *repositories.AllocationRepository, EndingSoonWindow time.Duration) *NotificationService {
+	return &NotificationService{NotificationRepository, BidRepository, AllocationRepository, EndingSoonWindow}
+}
+
+// Create notifications from the events relayed on this instance.
+// Events that arrived from another instance were already handled by the instance that relayed them.
+func (n *NotificationService) Subscribe(dispatcher *events.Dispatcher) {
+	dispatcher.Subscribe(events.Outbid, func(event events.Event) {
+		outbid, ok := event.Data.(models.Outbid)
+
+		if !ok || event.Remote {
+			return
+		}
+
+		n.notify(models.Notification{
+			UserID:    outbid.BidderID,
+			Kind:      models.NotificationOutbid,
+			ItemID:    outbid.ItemID,
+			Message:   fmt.Sprintf("You have been outbid on %s, the leading bid is now %s %s", outbid.ItemName, outbid.LeadingAmount.Format(outbid.Currency), outbid.Currency),
+			DedupeKey: fmt.Sprintf("outbid:%d:%d:%d", outbid.ItemID, outbid.BidderID, outbid.LeadingBidID),
+		})
+	})
+
+	dispatcher.Subscribe(events.ItemClosed, func(event events.Event) {
+		item, ok := event.Data.(models.Item)
+
+		if !ok || event.Remote {
+			return
+		}
+
+		err := n.notifyClosed(item)
+
+		if err != nil {
+			log.Printf("Error creating notifications for closed item %d: %v", item.ID, err)
+		}
+	})
+}
+
+// Tell every bidder on a closed item whether they won, lost or the reserve was not met
+func (n *NotificationService) notifyClosed(item models.Item) error {
+	bidderIDs, err := n.BidRepository.GetItemBidderIDs(item.ID)
+
+	if err != nil || len(bidderIDs) == 0 {
+		return err
+	}
+
+	winners := make(map[int64]bool)
+
+	if item.Quantity > 1 {
+		allocations, err := n.AllocationRepository.GetAllocationsByItemID(item.ID, nil)
+
+		if err != nil {
+			return err
+		}
+
+		for _, allocation := range allocations {
+			winners[allocation.BidderID] = true
+		}
+	} else if item.WinnerID != nil {
+		winners[*item.WinnerID] = true
+	}
+
+	reserveNotMet := item.Status == models.ItemStatusUnsold && item.ReservePrice != nil
+
+	for _, bidderID := range bidderIDs {
+		notification := models.Notification{UserID: bidderID, ItemID: item.ID}
+
+		switch {
+		case reserveNotMet:
+			notification.Kind = models.NotificationReserveNotMet
+			notification.Message = fmt.Sprintf("%s closed without reaching its reserve price", item.ItemName)
+		case winners[bidderID] && item.HammerPrice != nil:
+			notification.Kind = models.NotificationWon
+			notification.Message = fmt.Sprintf("You won %s for %s %s", item.ItemName, item.HammerPrice.Format(item.Currency), item.Currency)
+		case winners[bidderID]:
+			notification.Kind = models.NotificationWon
+			notification.Message = fmt.Sprintf("You won %s", item.ItemName)
+		default:
+			notification.Kind = models.NotificationLost
+			notification.Message = fmt.Sprintf("%s closed and your bid did not win", item.ItemName)
+		}
+
+		notification.DedupeKey = fmt.Sprintf("%s:%d:%d", notification.Kind, item.ID, bidderID)
+
+		n.notify(notification)
+	}
+
+	return nil
+}
+
+// Store a notification, a user who turned its kind off does not get it
+func (n *NotificationService) notify(notification models.Notification) {
+	_, err := n.NotificationRepository.CreateNotification(notification)
+
+	if err != nil {
+		log.Printf("Error creating %s notification for user %d: %v", notification.Kind, notification.UserID, err)
+	}
+}
+
+// Tell the bidders of every auction ending within the window that it is about to close, once per auction
+func (n *NotificationService) NotifyEndingSoon(ctx context.Context) error {
+	now := time.Now()
+
+	created, err := n.NotificationRepository.CreateEndingSoonNotifications(now, now.Add(n.EndingSoonWindow))
+
+	if err != nil {
+		log.Printf("Error creating ending soon notifications: %v", err)
+		return err
+	}
+
+	if created > 0 {
+		log.Printf("Created %d ending soon notifications", created)
+	}
+
+	return nil
+}
+
+// Retrieve a page of the user's notifications, newest first, with their unread count
+func (n *NotificationService) GetNotifications(userID int64, unreadOnly bool, page, limit int) (models.NotificationPage, error) {
+	notificationPage := models.NotificationPage{Page: page, Limit: limit}
+
+	notifications, err := n.NotificationRepository.GetNotificationsByUserID(userID, unreadOnly, limit, (page-1)*limit)
+
+	if err != nil {
+		log.Printf("Error retrieving notifications: %v", err)
+		return notificationPage, err
+	}
+
+	unread, err := n.NotificationRepository.CountUnreadNotifications(userID)
+
+	if err != nil {
+		log.Printf("Error retrieving notifications: %v", err)
+		return notificationPage, err
+	}
+
+	notificationPage.Notifications = notifications
+	notificationPage.Unread = unread
+
+	return notificationPage, nil
+}
+
+func (n *NotificationService) GetUnreadCount(userID int64) (models.UnreadCount, error) {
+	unread, err := n.NotificationRepository.CountUnreadNotifications(userID)
+
+	if err != nil {
+		log.Printf("Error counting unread notifications: %v", err)
+	}
+
+	return models.UnreadCount{Unread: unread}, err
+}
+
+// Mark one of the user's notifications as read
+func (n *NotificationService) MarkRead(userID int64, notificationID int64) (models.Notification, error) {
+	notification, err := n.NotificationRepository.MarkNotificationRead(userID, notificationID, time.Now())
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return notification, ErrNotificationNotFound
+	}
+
+	if err != nil {
+		log.Printf("Error marking notification as read: %v", err)
+	}
+
+	return notification, err
+}
+
+// Mark all of the user's notifications as read
+func (n *NotificationService) MarkAllRead(userID int64) error {
+	_, err := n.NotificationRepository.MarkAllNotificationsRead(userID, time.Now())
+
+	if err != nil {
+		log.Printf("Error marking notifications as read: %v", err)
+	}
+
+	return err
+}
+
+// Retrieve whether the user receives each kind of notification, kinds they never set are enabled
+func (n *NotificationService) GetPreferences(userID int64) ([]models.NotificationPreference, error) {
+	stored, err := n.NotificationRepository.GetNotificationPreferences(userID)
+
+	if err != nil {
+		log.Printf("Error retrieving notification preferences: %v", err)
+		return nil, err
+	}
+
+	enabled := make(map[models.NotificationKind]bool)
+
+	for _, preference := range stored {
+		enabled[preference.Kind] = preference.Enabled
+	}
+
+	preferences := make([]models.NotificationPreference, 0, len(models.NotificationKinds))
+
+	for _, kind := range models.NotificationKinds {
+		isEnabled, ok := enabled[kind]
+		preferences = append(preferences, models.NotificationPreference{Kind: kind, Enabled: !ok || isEnabled})
+	}
+
+	return preferences, nil
+}
+
+// Turn kinds of notifications on or off for the user, kinds left out of the update keep their setting
+func (n *NotificationService) UpdatePreferences(userID int64, update models.NotificationPreferencesUpdate) ([]models.NotificationPreference, error) {
+	err := repositories.WithTx(n.NotificationRepository.DB, func(tx pgx.Tx) error {
+		for _, preference := range update.Preferences {
+			err := n.NotificationRepository.SetNotificationPreference(tx, userID, preference)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Error updating notification preferences: %v", err)
+		return nil, err
+	}
+
+	return n.GetPreferences(userID)
+}
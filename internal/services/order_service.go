@@ -0,0 +1,823 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bangueco/auction-api/internal/events"
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/payments"
+	"github.com/bangueco/auction-api/internal/repositories"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrOrderNotFound            = errors.New("order not found")
+	ErrNotOrderParty            = errors.New("only the buyer or the seller can access this order")
+	ErrInvalidPaymentTransition = errors.New("order cannot move to this payment status")
+	ErrOrderSettled             = errors.New("the sale has already been paid")
+	ErrPaymentDeclined          = errors.New("payment was declined")
+	ErrRefundFailed             = errors.New("refund could not be completed")
+	ErrPaidByProvider           = errors.New("orders are only marked paid by a captured payment")
+)
+
+// What a buyer is charged on top of the hammer price
+type OrderCharges struct {
+	// Share of the hammer price, in basis points, added as the buyer's premium
+	BuyersPremiumBps int64
+	// Flat fee added to every order
+	Fee models.Money
+	// Tax, in basis points, charged on the hammer price, premium and fee together
+	TaxBps int64
+}
+
+// Return the lines of an order for a hammer price in the given currency.
+// Every charge is rounded down to the currency's minor unit and lines that come to nothing are left out.
+func (c OrderCharges) Lines(itemName string, hammerPrice models.Money, currency string) []models.OrderLine {
+	lines := []models.OrderLine{{Kind: models.OrderLineHammer, Description: itemName, Amount: hammerPrice}}
+
+	if premium := hammerPrice.MulBasisPoints(c.BuyersPremiumBps, currency); premium > 0 {
+		lines = append(lines, models.OrderLine{Kind: models.OrderLineBuyersPremium, Description: "Buyer's premium", Amount: premium})
+	}
+
+	if fee := c.Fee.RoundDown(currency); fee > 0 {
+		lines = append(lines, models.OrderLine{Kind: models.OrderLineFee, Description: "Processing fee", Amount: fee})
+	}
+
+	taxable := models.Money(0)
+
+	for _, line := range lines {
+		taxable += line.Amount
+	}
+
+	if tax := taxable.MulBasisPoints(c.TaxBps, currency); tax > 0 {
+		lines = append(lines, models.OrderLine{Kind: models.OrderLineTax, Description: "Tax", Amount: tax})
+	}
+
+	return lines
+}
+
+// OrderService records the sale of every winning bid and tracks its payment
+type OrderService struct {
+	OrderRepository *repositories.OrderRepository
+	ItemRepository  *repositories.ItemRepository
+	UserRepository  *repositories.UserRepository
+	WalletService   *WalletService
+	Provider        payments.PaymentProvider
+	Outbox          *OutboxService
+	Charges         OrderCharges
+}
+
+func NewOrderService(OrderRepository *repositories.OrderRepository, ItemRepository *repositories.ItemRepository, UserRepository *repositories.UserRepository, WalletService *WalletService, Provider payments.PaymentProvider, Outbox *OutboxService, Charges OrderCharges) *OrderService {
+	return &OrderService{OrderRepository, ItemRepository, UserRepository, WalletService, Provider, Outbox, Charges}
+}
+
+// Create the orders of a closed item inside the transaction that closed it.
+// A multi-unit auction gets one order per allocation, any other auction one order for its winning bid.
+// In a reverse auction the item's owner is the one buying from the winning bidder.
+// Funds already captured from a buyer's wallet for the item count towards their orders, an order they cover in full is paid.
+func (o *OrderService) CreateOrders(tx pgx.Tx, item models.Item, allocations []models.Allocation) ([]models.Order, error) {
+	var orders []models.Order
+
+	credits := make(map[int64]models.Money)
+
+	for _, allocation := range allocations {
+		order, err := o.createOrder(tx, item, allocation.BidID, allocation.BidderID, item.AuctionedBy, allocation.Quantity, allocation.TotalPrice, credits)
+
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, order)
+	}
+
+	if len(allocations) > 0 || item.WinnerID == nil || item.WinningBidID == nil || item.HammerPrice == nil {
+		return orders, nil
+	}
+
+	buyerID, sellerID := *item.WinnerID, item.AuctionedBy
+
+	if item.AuctionType == models.AuctionTypeReverse {
+		buyerID, sellerID = sellerID, buyerID
+	}
+
+	order, err := o.createOrder(tx, item, *item.WinningBidID, buyerID, sellerID, 1, *item.HammerPrice, credits)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append(orders, order), nil
+}
+
+// Replace the unpaid orders of an item whose winner changed, a sale that has been paid is left alone
+func (o *OrderService) ReplaceOrders(tx pgx.Tx, item models.Item) ([]models.Order, error) {
+	remaining, err := o.OrderRepository.DeleteUnpaidItemOrders(tx, item.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if remaining > 0 {
+		return nil, ErrOrderSettled
+	}
+
+	return o.CreateOrders(tx, item, nil)
+}
+
+// Create one order, taking what it can from the buyer's wallet credit for the item, which is looked up on first use
+func (o *OrderService) createOrder(tx pgx.Tx, item models.Item, bidID, buyerID, sellerID int64, quantity int, hammerPrice models.Money, credits map[int64]models.Money) (models.Order, error) {
+	order := models.Order{
+		ItemID:   item.ID,
+		BidID:    bidID,
+		BuyerID:  buyerID,
+		SellerID: sellerID,
+		Currency: item.Currency,
+		Quantity: quantity,
+		Lines:    o.Charges.Lines(item.ItemName, hammerPrice, item.Currency),
+	}
+
+	for _, line := range order.Lines {
+		order.Total += line.Amount
+	}
+
+	credit, ok := credits[buyerID]
+
+	if !ok {
+		captured, err := o.WalletService.CapturedAmount(tx, item.ID, buyerID)
+
+		if err != nil {
+			return order, err
+		}
+
+		credit = captured
+	}
+
+	order.WalletAmount = min(credit, order.Total)
+	credits[buyerID] = credit - order.WalletAmount
+
+	created, err := o.OrderRepository.CreateOrder(tx, order)
+
+	if err != nil {
+		return created, err
+	}
+
+	err = o.Outbox.RecordOrderEvent(tx, events.OrderCreated, created)
+
+	if err != nil || created.AmountDue() > 0 {
+		return created, err
+	}
+
+	note := "Paid from wallet"
+	err = o.transition(tx, created, models.PaymentStatusPaid, nil, &note)
+
+	return created, err
+}
+
+// Retrieve every order the user bought, newest first
+func (o *OrderService) GetPurchases(buyerID int64) ([]models.Order, error) {
+	orders, err := o.OrderRepository.GetOrdersByBuyerID(buyerID)
+
+	if err != nil {
+		log.Printf("Error retrieving purchases: %v", err)
+		return nil, err
+	}
+
+	return o.withLines(orders)
+}
+
+// Retrieve every order the user sold, newest first
+func (o *OrderService) GetSales(sellerID int64) ([]models.Order, error) {
+	orders, err := o.OrderRepository.GetOrdersBySellerID(sellerID)
+
+	if err != nil {
+		log.Printf("Error retrieving sales: %v", err)
+		return nil, err
+	}
+
+	return o.withLines(orders)
+}
+
+// Fill in the lines of every order with a single query
+func (o *OrderService) withLines(orders []models.Order) ([]models.Order, error) {
+	if len(orders) == 0 {
+		return []models.Order{}, nil
+	}
+
+	ids := make([]int64, 0, len(orders))
+
+	for _, order := range orders {
+		ids = append(ids, order.ID)
+	}
+
+	lines, err := o.OrderRepository.GetOrderLines(ids)
+
+	if err != nil {
+		log.Printf("Error retrieving order lines: %v", err)
+		return nil, err
+	}
+
+	for i := range orders {
+		orders[i].Lines = lines[orders[i].ID]
+	}
+
+	return orders, nil
+}
+
+// Retrieve an order the user bought, with its lines and payment history
+func (o *OrderService) GetPurchase(orderID int64, buyerID int64) (models.Order, error) {
+	return o.getOrder(orderID, func(order models.Order) bool { return order.BuyerID == buyerID })
+}
+
+// Retrieve an order the user sold, with its lines and payment history
+func (o *OrderService) GetSale(orderID int64, sellerID int64) (models.Order, error) {
+	return o.getOrder(orderID, func(order models.Order) bool { return order.SellerID == sellerID })
+}
+
+func (o *OrderService) getOrder(orderID int64, canView func(models.Order) bool) (models.Order, error) {
+	order, err := o.OrderRepository.GetOrderByID(orderID)
+
+	if err != nil {
+		log.Printf("Error retrieving order: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return order, ErrOrderNotFound
+		}
+		return order, err
+	}
+
+	if !canView(order) {
+		return order, ErrNotOrderParty
+	}
+
+	orders, err := o.withLines([]models.Order{order})
+
+	if err != nil {
+		return order, err
+	}
+
+	order = orders[0]
+	order.PaymentHistory, err = o.OrderRepository.GetPaymentTransitions(order.ID)
+
+	if err != nil {
+		log.Printf("Error retrieving order: %v", err)
+		return order, err
+	}
+
+	order.Transactions, err = o.OrderRepository.GetPaymentTransactions(order.ID)
+
+	if err != nil {
+		log.Printf("Error retrieving order: %v", err)
+		return order, err
+	}
+
+	return order, nil
+}
+
+// Build the invoice of an order, only its buyer and seller may read it
+func (o *OrderService) GetInvoice(orderID int64, userID int64) (models.Invoice, error) {
+	order, err := o.getOrder(orderID, func(order models.Order) bool { return order.BuyerID == userID || order.SellerID == userID })
+
+	if err != nil {
+		return models.Invoice{}, err
+	}
+
+	item, err := o.ItemRepository.GetItemByID(order.ItemID)
+
+	if err != nil {
+		log.Printf("Error retrieving invoice: %v", err)
+		return models.Invoice{}, err
+	}
+
+	usernames, err := o.UserRepository.GetUsernamesByIDs([]int64{order.BuyerID, order.SellerID})
+
+	if err != nil {
+		log.Printf("Error retrieving invoice: %v", err)
+		return models.Invoice{}, err
+	}
+
+	return models.Invoice{
+		Number:         fmt.Sprintf("INV-%08d", order.ID),
+		IssuedAt:       order.CreatedAt,
+		OrderID:        order.ID,
+		ItemID:         item.ID,
+		ItemName:       item.ItemName,
+		Quantity:       order.Quantity,
+		BuyerID:        order.BuyerID,
+		BuyerUsername:  usernames[order.BuyerID],
+		SellerID:       order.SellerID,
+		SellerUsername: usernames[order.SellerID],
+		Currency:       order.Currency,
+		Lines:          order.Lines,
+		Total:          order.Total,
+		WalletAmount:   order.WalletAmount,
+		AmountDue:      order.AmountDue(),
+		PaymentStatus:  order.PaymentStatus,
+		PaidAt:         order.PaidAt,
+	}, nil
+}
+
+// Move an order the user sold to a new payment status and record the change in its payment history.
+// Refunding an order gives back what it was paid through the provider and from the buyer's wallet.
+// Only a captured payment marks an order paid, a seller can not do it on their own.
+func (o *OrderService) UpdatePaymentStatus(orderID int64, sellerID int64, update models.PaymentStatusUpdate) (models.Order, error) {
+	if update.Status == models.PaymentStatusPaid {
+		return models.Order{}, ErrPaidByProvider
+	}
+
+	if update.Status == models.PaymentStatusRefunded {
+		return o.Refund(orderID, sellerID, update.Note)
+	}
+
+	err := repositories.WithTx(o.OrderRepository.DB, func(tx pgx.Tx) error {
+		order, err := o.lockSale(tx, orderID, sellerID)
+
+		if err != nil {
+			return err
+		}
+
+		return o.transition(tx, order, update.Status, &sellerID, update.Note)
+	})
+
+	if err != nil {
+		log.Printf("Error updating payment status: %v", err)
+		return models.Order{}, err
+	}
+
+	o.Outbox.Wake()
+
+	return o.GetSale(orderID, sellerID)
+}
+
+// Refund a paid order the user sold, through the provider and from their wallet to the buyer's.
+// Like a payment, the refund is recorded as pending before the provider is called and completed with its answer
+// afterwards, and the attempt's reference keeps the provider from paying out twice. When giving back the wallet
+// part fails after the provider refunded, the attempt stays pending and refunding again completes it.
+func (o *OrderService) Refund(orderID int64, sellerID int64, note *string) (models.Order, error) {
+	attempt, capture, err := o.startRefund(orderID, sellerID, note)
+
+	if err != nil {
+		log.Printf("Error refunding order: %v", err)
+		return models.Order{}, err
+	}
+
+	// Nothing was paid through the provider, startRefund already gave back the wallet part
+	if attempt.ID == 0 {
+		o.Outbox.Wake()
+		return o.GetSale(orderID, sellerID)
+	}
+
+	refund, err := o.Provider.Refund(context.Background(), payments.RefundRequest{
+		Reference: fmt.Sprintf("order-%d-refund-%d", attempt.OrderID, attempt.ID),
+		CaptureID: capture.ProviderTransactionID,
+		Amount:    attempt.Amount,
+	})
+
+	// The provider will never accept this refund, so the attempt is failed instead of left for a retry
+	if errors.Is(err, payments.ErrUnknownTransaction) || errors.Is(err, payments.ErrInvalidAmount) {
+		refund, err = payments.Transaction{Kind: payments.TransactionRefund, Status: payments.TransactionFailed, Amount: attempt.Amount, Currency: attempt.Currency, FailureReason: err.Error()}, nil
+	}
+
+	if err != nil {
+		log.Printf("Error refunding order: %v", err)
+		return models.Order{}, err
+	}
+
+	failed, err := o.finishRefund(attempt, sellerID, refund, note)
+
+	if err != nil {
+		log.Printf("Error refunding order: %v", err)
+		return models.Order{}, err
+	}
+
+	o.Outbox.Wake()
+
+	if failed {
+		return models.Order{}, ErrRefundFailed
+	}
+
+	return o.GetSale(orderID, sellerID)
+}
+
+// Check that the seller can refund the order and return its pending refund attempt with the capture it refunds,
+// recording a new attempt when there is none. An order paid only from the wallet is refunded right away and gets no attempt.
+func (o *OrderService) startRefund(orderID int64, sellerID int64, note *string) (models.PaymentTransaction, models.PaymentTransaction, error) {
+	var attempt, capture models.PaymentTransaction
+
+	err := repositories.WithTx(o.OrderRepository.DB, func(tx pgx.Tx) error {
+		order, err := o.lockSale(tx, orderID, sellerID)
+
+		if err != nil {
+			return err
+		}
+
+		if !order.PaymentStatus.CanTransitionTo(models.PaymentStatusRefunded) {
+			return ErrInvalidPaymentTransition
+		}
+
+		capture, err = o.OrderRepository.GetCaptureTransaction(tx, order.ID)
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = o.refundWallet(tx, order)
+
+			if err != nil {
+				return err
+			}
+
+			return o.transition(tx, order, models.PaymentStatusRefunded, &sellerID, note)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		attempt, err = o.OrderRepository.GetPendingPaymentTransaction(tx, order.ID, string(payments.TransactionRefund))
+
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		attempt, err = o.OrderRepository.CreatePaymentTransaction(tx, models.PaymentTransaction{
+			OrderID:  order.ID,
+			Provider: o.Provider.Name(),
+			Kind:     string(payments.TransactionRefund),
+			Amount:   capture.Amount,
+			Currency: capture.Currency,
+			Status:   string(payments.TransactionPending),
+		})
+
+		return err
+	})
+
+	return attempt, capture, err
+}
+
+// Complete a pending refund attempt with the provider's answer and, when it succeeded, give back the wallet part
+// and move the order to refunded. An attempt another request has completed already is left alone.
+func (o *OrderService) finishRefund(attempt models.PaymentTransaction, sellerID int64, refund payments.Transaction, note *string) (bool, error) {
+	failed := false
+
+	err := repositories.WithTx(o.OrderRepository.DB, func(tx pgx.Tx) error {
+		order, err := o.OrderRepository.GetOrderByIDForUpdate(tx, attempt.OrderID)
+
+		if err != nil {
+			return err
+		}
+
+		pending, err := o.OrderRepository.GetPaymentTransactionByIDForUpdate(tx, attempt.ID)
+
+		if err != nil {
+			return err
+		}
+
+		if pending.Status != string(payments.TransactionPending) {
+			failed = pending.Status == string(payments.TransactionFailed)
+			return nil
+		}
+
+		_, err = o.OrderRepository.CompletePaymentTransaction(tx, attempt.ID, refund.ID, string(refund.Status), failureReason(refund))
+
+		if err != nil {
+			return err
+		}
+
+		if refund.Status != payments.TransactionSucceeded {
+			failed = true
+			return nil
+		}
+
+		// A refund webhook may have moved the order and given back the wallet part already
+		if !order.PaymentStatus.CanTransitionTo(models.PaymentStatusRefunded) {
+			return nil
+		}
+
+		err = o.refundWallet(tx, order)
+
+		if err != nil {
+			return err
+		}
+
+		return o.transition(tx, order, models.PaymentStatusRefunded, &sellerID, note)
+	})
+
+	return failed, err
+}
+
+// Lock an order the user sold inside an existing transaction
+func (o *OrderService) lockSale(tx pgx.Tx, orderID int64, sellerID int64) (models.Order, error) {
+	order, err := o.OrderRepository.GetOrderByIDForUpdate(tx, orderID)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return order, ErrOrderNotFound
+	}
+
+	if err != nil {
+		return order, err
+	}
+
+	if order.SellerID != sellerID {
+		return order, ErrNotOrderParty
+	}
+
+	return order, nil
+}
+
+// Move a locked order to a new payment status inside an existing transaction
+func (o *OrderService) transition(tx pgx.Tx, order models.Order, status models.PaymentStatus, changedBy *int64, note *string) error {
+	if !order.PaymentStatus.CanTransitionTo(status) {
+		return ErrInvalidPaymentTransition
+	}
+
+	updated, err := o.OrderRepository.UpdatePaymentStatus(tx, order.ID, status, time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	_, err = o.OrderRepository.CreatePaymentTransition(tx, models.PaymentTransition{OrderID: order.ID, FromStatus: order.PaymentStatus, ToStatus: status, ChangedBy: changedBy, Note: note})
+
+	if err != nil {
+		return err
+	}
+
+	updated.Lines = order.Lines
+
+	return o.Outbox.RecordOrderEvent(tx, events.OrderPaymentChanged, updated)
+}
+
+// Pay what is left due on an order the user bought through the payment provider, authorizing and capturing it in one go.
+// The attempt is recorded as pending before the provider is called and completed with its answer afterwards,
+// so the order is never locked while the provider works and a capture is never rolled back.
+// The attempt's reference makes the provider calls idempotent: a retried or concurrent request for the same
+// pending attempt gets the original authorization and capture back instead of charging the buyer twice.
+// A declined payment is recorded and fails the order, the buyer can try again with another payment method.
+func (o *OrderService) Pay(orderID int64, buyerID int64, request models.PayOrderRequest) (models.Order, error) {
+	attempt, err := o.startPayment(orderID, buyerID)
+
+	if err != nil {
+		log.Printf("Error paying order: %v", err)
+		return models.Order{}, err
+	}
+
+	ctx := context.Background()
+
+	// A failed call leaves the attempt pending, paying again retries it under the same reference
+	authorization, err := o.Provider.Authorize(ctx, payments.AuthorizeRequest{
+		Reference:     fmt.Sprintf("order-%d-payment-%d", attempt.OrderID, attempt.ID),
+		Amount:        attempt.Amount,
+		Currency:      attempt.Currency,
+		PaymentMethod: request.PaymentMethod,
+	})
+
+	if err != nil {
+		log.Printf("Error paying order: %v", err)
+		return models.Order{}, err
+	}
+
+	transaction := authorization
+
+	if authorization.Status == payments.TransactionSucceeded {
+		transaction, err = o.Provider.Capture(ctx, authorization.ID, authorization.Amount)
+
+		if err != nil {
+			log.Printf("Error paying order: %v", err)
+			return models.Order{}, err
+		}
+	}
+
+	declined, err := o.finishPayment(attempt, buyerID, authorization, transaction)
+
+	if err != nil {
+		log.Printf("Error paying order: %v", err)
+		return models.Order{}, err
+	}
+
+	o.Outbox.Wake()
+
+	order, err := o.GetPurchase(orderID, buyerID)
+
+	if err == nil && declined {
+		return order, ErrPaymentDeclined
+	}
+
+	return order, err
+}
+
+// Check that the buyer can pay the order and return its pending payment attempt, recording a new one when there is none
+func (o *OrderService) startPayment(orderID int64, buyerID int64) (models.PaymentTransaction, error) {
+	var attempt models.PaymentTransaction
+
+	err := repositories.WithTx(o.OrderRepository.DB, func(tx pgx.Tx) error {
+		order, err := o.OrderRepository.GetOrderByIDForUpdate(tx, orderID)
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrOrderNotFound
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if order.BuyerID != buyerID {
+			return ErrNotOrderParty
+		}
+
+		if !order.PaymentStatus.CanTransitionTo(models.PaymentStatusPaid) {
+			return ErrInvalidPaymentTransition
+		}
+
+		attempt, err = o.OrderRepository.GetPendingPaymentTransaction(tx, order.ID, string(payments.TransactionAuthorize))
+
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		attempt, err = o.OrderRepository.CreatePaymentTransaction(tx, models.PaymentTransaction{
+			OrderID:  order.ID,
+			Provider: o.Provider.Name(),
+			Kind:     string(payments.TransactionAuthorize),
+			Amount:   order.AmountDue(),
+			Currency: order.Currency,
+			Status:   string(payments.TransactionPending),
+		})
+
+		return err
+	})
+
+	return attempt, err
+}
+
+// Complete a pending payment attempt with the provider's authorization and the capture that followed it,
+// and move the order to paid or failed. An attempt another request has completed already is left alone.
+func (o *OrderService) finishPayment(attempt models.PaymentTransaction, buyerID int64, authorization payments.Transaction, transaction payments.Transaction) (bool, error) {
+	declined := false
+
+	err := repositories.WithTx(o.OrderRepository.DB, func(tx pgx.Tx) error {
+		order, err := o.OrderRepository.GetOrderByIDForUpdate(tx, attempt.OrderID)
+
+		if err != nil {
+			return err
+		}
+
+		pending, err := o.OrderRepository.GetPaymentTransactionByIDForUpdate(tx, attempt.ID)
+
+		if err != nil {
+			return err
+		}
+
+		if pending.Status != string(payments.TransactionPending) {
+			declined = order.PaymentStatus == models.PaymentStatusFailed
+			return nil
+		}
+
+		_, err = o.OrderRepository.CompletePaymentTransaction(tx, attempt.ID, authorization.ID, string(authorization.Status), failureReason(authorization))
+
+		if err != nil {
+			return err
+		}
+
+		if authorization.Status == payments.TransactionSucceeded {
+			err = o.recordTransaction(tx, order, transaction)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if transaction.Status == payments.TransactionSucceeded {
+			if !order.PaymentStatus.CanTransitionTo(models.PaymentStatusPaid) {
+				return nil
+			}
+
+			return o.transition(tx, order, models.PaymentStatusPaid, &buyerID, nil)
+		}
+
+		declined = true
+
+		if order.PaymentStatus == models.PaymentStatusFailed {
+			return nil
+		}
+
+		return o.transition(tx, order, models.PaymentStatusFailed, &buyerID, &transaction.FailureReason)
+	})
+
+	return declined, err
+}
+
+// Apply a payment provider webhook to the order its transaction belongs to.
+// Webhooks may arrive more than once and out of order, an event that would not move the order forward is ignored.
+// A refund made on the provider's side also gives back what the buyer paid from their wallet.
+func (o *OrderService) HandleWebhook(payload []byte, signature string) error {
+	event, err := o.Provider.VerifyWebhook(payload, signature)
+
+	if err != nil {
+		log.Printf("Error verifying payment webhook: %v", err)
+		return err
+	}
+
+	if _, ok := webhookStatuses[event.Type]; !ok {
+		return nil
+	}
+
+	transaction, err := o.OrderRepository.GetPaymentTransactionByProviderID(o.Provider.Name(), event.TransactionID)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return payments.ErrUnknownTransaction
+	}
+
+	if err != nil {
+		log.Printf("Error handling payment webhook: %v", err)
+		return err
+	}
+
+	err = repositories.WithTx(o.OrderRepository.DB, func(tx pgx.Tx) error {
+		order, err := o.OrderRepository.GetOrderByIDForUpdate(tx, transaction.OrderID)
+
+		if err != nil {
+			return err
+		}
+
+		status, ok := webhookTransition(order.PaymentStatus, event.Type)
+
+		if !ok {
+			return nil
+		}
+
+		// The provider has already given its part back, what was paid from the wallet still has to be
+		if status == models.PaymentStatusRefunded {
+			err := o.refundWallet(tx, order)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		note := fmt.Sprintf("Payment provider event %s", event.ID)
+
+		return o.transition(tx, order, status, nil, &note)
+	})
+
+	if err != nil {
+		log.Printf("Error handling payment webhook: %v", err)
+		return err
+	}
+
+	o.Outbox.Wake()
+
+	return nil
+}
+
+// Payment status each provider webhook event moves an order to
+var webhookStatuses = map[payments.WebhookEventType]models.PaymentStatus{
+	payments.WebhookPaymentCaptured: models.PaymentStatusPaid,
+	payments.WebhookPaymentFailed:   models.PaymentStatusFailed,
+	payments.WebhookPaymentRefunded: models.PaymentStatusRefunded,
+}
+
+// Return the payment status a webhook event moves an order to, false when the event does not apply
+// to the order in its current status, as with a replayed or late event
+func webhookTransition(current models.PaymentStatus, eventType payments.WebhookEventType) (models.PaymentStatus, bool) {
+	status, ok := webhookStatuses[eventType]
+
+	if !ok || !current.CanTransitionTo(status) {
+		return "", false
+	}
+
+	return status, true
+}
+
+// Give back the part of an order the buyer paid from their wallet
+func (o *OrderService) refundWallet(tx pgx.Tx, order models.Order) error {
+	if order.WalletAmount == 0 {
+		return nil
+	}
+
+	return o.WalletService.RefundCapture(tx, order.ItemID, order.BuyerID, order.SellerID, order.Currency, order.WalletAmount)
+}
+
+// Record a call made to the payment provider for an order inside an existing transaction
+func (o *OrderService) recordTransaction(tx pgx.Tx, order models.Order, transaction payments.Transaction) error {
+	_, err := o.OrderRepository.CreatePaymentTransaction(tx, models.PaymentTransaction{
+		OrderID:               order.ID,
+		Provider:              o.Provider.Name(),
+		Kind:                  string(transaction.Kind),
+		ProviderTransactionID: transaction.ID,
+		Amount:                transaction.Amount,
+		Currency:              transaction.Currency,
+		Status:                string(transaction.Status),
+		FailureReason:         failureReason(transaction),
+	})
+
+	return err
+}
+
+// Return why the provider failed a transaction, nil when it did not
+func failureReason(transaction payments.Transaction) *string {
+	if transaction.FailureReason == "" {
+		return nil
+	}
+
+	return &transaction.FailureReason
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/payments"
+)
+
+func TestWebhookTransition(t *testing.T) {
+	tests := []struct {
+		name      string
+		current   models.PaymentStatus
+		eventType payments.WebhookEventType
+		want      models.PaymentStatus
+		wantOK    bool
+	}{
+		{"capture pays a pending order", models.PaymentStatusPending, payments.WebhookPaymentCaptured, models.PaymentStatusPaid, true},
+		{"capture pays a failed order", models.PaymentStatusFailed, payments.WebhookPaymentCaptured, models.PaymentStatusPaid, true},
+		{"failure fails a pending order", models.PaymentStatusPending, payments.WebhookPaymentFailed, models.PaymentStatusFailed, true},
+		{"refund refunds a paid order", models.PaymentStatusPaid, payments.WebhookPaymentRefunded, models.PaymentStatusRefunded, true},
+		{"replayed capture is ignored", models.PaymentStatusPaid, payments.WebhookPaymentCaptured, "", false},
+		{"replayed refund is ignored", models.PaymentStatusRefunded, payments.WebhookPaymentRefunded, "", false},
+		{"late failure does not undo a payment", models.PaymentStatusPaid, payments.WebhookPaymentFailed, "", false},
+		{"refund of an unpaid order is ignored", models.PaymentStatusPending, payments.WebhookPaymentRefunded, "", false},
+		{"unknown event is ignored", models.PaymentStatusPending, "payment.disputed", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := webhookTransition(tt.current, tt.eventType)
+
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("webhookTransition(%s, %s) = %s, %v, want %s, %v", tt.current, tt.eventType, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/bangueco/auction-api/internal/events"
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/repositories"
+	"github.com/bangueco/auction-api/internal/webhooks"
+	"github.com/jackc/pgx/v5"
+)
+
+// Longest wait between two publication or webhook delivery attempts of an event
+const maxOutboxRetryDelay = time.Hour
+
+// How long claimed events are left to one relay for publishing to the event bus
+const outboxPublishLease = 30 * time.Second
+
+// How long claimed webhook deliveries are left to one relay, posts still running when it runs out are cancelled
+const outboxWebhookLease = time.Minute
+
+// OutboxService records domain events in the transaction that caused them and relays them once committed.
+// Each event goes to two sinks that are tracked separately, so a failing webhook never holds back the event bus.
+// Both get every event at least once: an event is leased, handed over and only then marked done, failures are
+// retried, and the events of an aggregate go out one at a time in the order they were recorded.
+// No transaction or row lock is held while an event is published or posted.
+type OutboxService struct {
+	OutboxRepository *repositories.OutboxRepository
+	Dispatcher       *events.Dispatcher
+	Webhooks         *webhooks.Sender
+	BatchSize        int
+	PollInterval     time.Duration
+	// How long delivered events are kept before they are cleaned up
+	Retention time.Duration
+
+	wake chan struct{}
+}
+
+func NewOutboxService(OutboxRepository *repositories.OutboxRepository, Dispatcher *events.Dispatcher, Webhooks *webhooks.Sender, BatchSize int, PollInterval time.Duration, Retention time.Duration) *OutboxService {
+	return &OutboxService{
+		OutboxRepository: OutboxRepository,
+		Dispatcher:       Dispatcher,
+		Webhooks:         Webhooks,
+		BatchSize:        BatchSize,
+		PollInterval:     PollInterval,
+		Retention:        Retention,
+		wake:             make(chan struct{}, 1),
+	}
+}
+
+// Record an event about an item inside an existing transaction
+func (o *OutboxService) RecordItemEvent(tx pgx.Tx, eventType events.EventType, itemID int64, data any) error {
+	return o.record(tx, eventType, models.AggregateItem, itemID, itemID, data)
+}
+
+// Record an event about an order inside an existing transaction
+func (o *OutboxService) RecordOrderEvent(tx pgx.Tx, eventType events.EventType, order models.Order) error {
+	return o.record(tx, eventType, models.AggregateOrder, order.ID, order.ItemID, order)
+}
+
+func (o *OutboxService) record(tx pgx.Tx, eventType events.EventType, aggregateType models.AggregateType, aggregateID int64, itemID int64, data any) error {
+	payload, err := json.Marshal(data)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = o.OutboxRepository.CreateOutboxEvent(tx, models.OutboxEvent{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		ItemID:        itemID,
+		EventType:     string(eventType),
+		Payload:       payload,
+	})
+
+	return err
+}
+
+// Have the relay look for new events right away instead of at its next poll, called once the events were committed
+func (o *OutboxService) Wake() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Relay events until the context is cancelled, polling for them so events recorded by other instances
+// or left behind by a crash are delivered too
+func (o *OutboxService) Run(ctx context.Context) {
+	ticker := time.NewTicker(o.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		err := o.publish(ctx)
+
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Error publishing outbox events: %v", err)
+		}
+
+		err = o.deliverWebhooks(ctx)
+
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Error delivering outbox webhooks: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-o.wake:
+		}
+	}
+}
+
+// Hand due events to the event bus, a batch at a time, until none are left that can be published
+func (o *OutboxService) publish(ctx context.Context) error {
+	for ctx.Err() == nil {
+		published, err := o.publishBatch()
+
+		if err != nil || published == 0 {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Lease one batch of events, publish them and record the outcome, reporting how many were published.
+// An event whose lease runs out before it is marked published, because the relay crashed, is published again.
+func (o *OutboxService) publishBatch() (int, error) {
+	claimed, err := o.OutboxRepository.ClaimUnpublishedOutboxEvents(time.Now(), time.Now().Add(outboxPublishLease), o.BatchSize)
+
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+
+	for _, event := range claimed {
+		data, err := decodeEventData(events.EventType(event.EventType), event.Payload)
+
+		// Kept unpublished so the events queued behind it wait, and retried in case a newer version of the relay can read it
+		if err != nil {
+			log.Printf("Error publishing outbox event %d (%s), attempt %d: %v", event.ID, event.EventType, event.PublishAttempts+1, err)
+
+			err = o.OutboxRepository.MarkOutboxPublishFailed(event.ID, err.Error(), time.Now().Add(outboxRetryDelay(event.PublishAttempts)))
+
+			if err != nil {
+				return published, err
+			}
+
+			continue
+		}
+
+		o.Dispatcher.Publish(events.Event{Type: events.EventType(event.EventType), ItemID: event.ItemID, Data: data, OccurredAt: event.CreatedAt})
+
+		err = o.OutboxRepository.MarkOutboxEventPublished(event.ID, time.Now())
+
+		if err != nil {
+			return published, err
+		}
+
+		published++
+	}
+
+	return published, nil
+}
+
+// Post due events to the webhooks, a batch at a time, until none are left that can be posted
+func (o *OutboxService) deliverWebhooks(ctx context.Context) error {
+	if len(o.Webhooks.URLs) == 0 {
+		return o.OutboxRepository.SkipOutboxWebhooks(time.Now())
+	}
+
+	for ctx.Err() == nil {
+		delivered, err := o.deliverWebhookBatch(ctx)
+
+		if err != nil || delivered == 0 {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Lease one batch of events, post them and record the outcome, reporting how many were delivered.
+// An event whose lease runs out before its outcome is recorded is posted again.
+func (o *OutboxService) deliverWebhookBatch(ctx context.Context) (int, error) {
+	leaseUntil := time.Now().Add(outboxWebhookLease)
+
+	claimed, err := o.OutboxRepository.ClaimOutboxWebhooks(time.Now(), leaseUntil, o.BatchSize)
+
+	if err != nil {
+		return 0, err
+	}
+
+	// Stop posting shortly before the lease runs out so the outcome is recorded while the events are still ours
+	batchCtx, cancel := context.WithDeadline(ctx, leaseUntil.Add(-outboxWebhookLease/10))
+	defer cancel()
+
+	delivered := 0
+
+	for _, event := range claimed {
+		if batchCtx.Err() != nil {
+			break
+		}
+
+		err := o.postWebhook(batchCtx, event)
+
+		if err == nil {
+			err = o.OutboxRepository.MarkOutboxWebhookDelivered(event.ID, time.Now())
+
+			if err != nil {
+				return delivered, err
+			}
+
+			delivered++
+			continue
+		}
+
+		// Events left unposted by a shutdown or the end of the lease are posted again once the lease runs out
+		if batchCtx.Err() != nil {
+			break
+		}
+
+		log.Printf("Error delivering outbox event %d (%s) to webhooks, attempt %d: %v", event.ID, event.EventType, event.WebhookAttempts+1, err)
+
+		err = o.OutboxRepository.MarkOutboxWebhookFailed(event.ID, err.Error(), time.Now().Add(outboxRetryDelay(event.WebhookAttempts)))
+
+		if err != nil {
+			return delivered, err
+		}
+	}
+
+	return delivered, nil
+}
+
+func (o *OutboxService) postWebhook(ctx context.Context, event models.OutboxEvent) error {
+	body, err := json.Marshal(event)
+
+	if err != nil {
+		return err
+	}
+
+	return o.Webhooks.Send(ctx, event.ID, body)
+}
+
+// Delete events once they were published and delivered longer ago than the retention period
+func (o *OutboxService) CleanDelivered(ctx context.Context) error {
+	deleted, err := o.OutboxRepository.DeleteDeliveredOutboxEvents(time.Now().Add(-o.Retention))
+
+	if err != nil {
+		log.Printf("Error cleaning up outbox events: %v", err)
+		return err
+	}
+
+	if deleted > 0 {
+		log.Printf("Cleaned up %d delivered outbox events", deleted)
+	}
+
+	return nil
+}
+
+// Double the wait after every failed attempt, starting at a second
+func outboxRetryDelay(attempts int) time.Duration {
+	if attempts >= 12 {
+		return maxOutboxRetryDelay
+	}
+
+	return min(time.Second<<attempts, maxOutboxRetryDelay)
+}
+
+// Turn an event's payload back into the model its subscribers expect
+func decodeEventData(eventType events.EventType, payload []byte) (any, error) {
+	switch eventType {
+	case events.BidPlaced:
+		return decodeAs[models.Bid](payload)
+	case events.ItemClosed, events.ItemExtended:
+		return decodeAs[models.Item](payload)
+	case events.Outbid:
+		return decodeAs[models.Outbid](payload)
+	case events.OrderCreated, events.OrderPaymentChanged:
+		return decodeAs[models.Order](payload)
+	default:
+		return nil, errors.New("unknown outbox event type " + string(eventType))
+	}
+}
+
+func decodeAs[T any](payload []byte) (any, error) {
+	var data T
+
+	err := json.Unmarshal(payload, &data)
+
+	return data, err
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Return the lowest amount the next bid on an item may be placed at
+func minimumBid(item models.Item, increments models.IncrementTable) models.Money {
+	return item.BidAmount + increments.StepFor(item.BidAmount)
+}
+
+// Return what a proxy bid that beats the leader's maximum bids: one increment above that maximum,
+// capped at the proxy's own maximum and never below the minimum bid
+func proxyLeadAmount(minimum, maxAmount, leaderMax models.Money, increments models.IncrementTable) models.Money {
+	return max(minimum, min(maxAmount, leaderMax+increments.StepFor(leaderMax)))
+}
+
+// Return the leader's answer to a lower maximum: one increment above it, capped at the leader's own maximum
+func proxyResponse(leaderMax, maxAmount models.Money, increments models.IncrementTable) models.Money {
+	return min(leaderMax, maxAmount+increments.StepFor(maxAmount))
+}
+
+// Resolve a bid request against the current leader and their proxy bid inside the bid transaction.
+//
+// Every request carries a maximum, the max_amount of a proxy bid or the amount of a plain bid.
+// When the new maximum beats the leader's, the leader's proxy is first played out up to its maximum
+// and the new bidder takes the lead one increment above it, or at their own amount for a plain bid.
+// Otherwise the new bid is recorded and the leader's proxy answers one increment above it,
+// capped at the leader's maximum. Ties go to the leader because their maximum was placed first.
+//
+// It returns the placement as the bidder sees it and every bid recorded along the way.
+func (b *BidService) resolveBid(tx pgx.Tx, item models.Item, bidderID int64, request models.PlaceBidRequest) (models.BidPlacement, []models.Bid, error) {
+	var placed []models.Bid
+
+	isProxy := request.MaxAmount != nil
+	maxAmount := request.Amount
+
+	if isProxy {
+		maxAmount = *request.MaxAmount
+
+		if request.Amount > maxAmount {
+			return models.BidPlacement{}, nil, ErrInvalidMaxAmount
+		}
+	}
+
+	increments, err := b.IncrementService.TableFor(item)
+
+	if err != nil {
+		return models.BidPlacement{}, nil, err
+	}
+
+	minimum := minimumBid(item, increments)
+
+	if maxAmount < minimum {
+		return models.BidPlacement{}, nil, &BidTooLowError{MinimumBid: minimum}
+	}
+
+	leader, err := b.BidRepository.GetHighestBidTx(tx, item.ID)
+	hasLeader := err == nil
+
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return models.BidPlacement{}, nil, err
+	}
+
+	if hasLeader && leader.BidderID == bidderID {
+		placement, err := b.raiseLeaderMaximum(tx, item, leader, increments, isProxy, maxAmount)
+		return placement, nil, err
+	}
+
+	leaderMax := models.Money(0)
+
+	if hasLeader {
+		leaderMax, err = b.proxyMaximum(tx, leader)
+
+		if err != nil {
+			return models.BidPlacement{}, nil, err
+		}
+	}
+
+	record := func(bid models.Bid) (models.Bid, error) {
+		created, err := b.BidRepository.CreateBid(tx, bid)
+
+		if err != nil {
+			return created, err
+		}
+
+		err = b.ItemRepository.RecordBid(tx, item.ID, created.Amount)
+
+		if err != nil {
+			return created, err
+		}
+
+		placed = append(placed, created)
+
+		return created, nil
+	}
+
+	// The new bidder takes the lead
+	if !hasLeader || maxAmount > leaderMax {
+		amount := request.Amount
+
+		if hasLeader && leaderMax > leader.Amount {
+			_, err := record(models.Bid{ItemID: item.ID, BidderID: leader.BidderID, Amount: leaderMax, Kind: models.BidKindProxy})
+
+			if err != nil {
+				return models.BidPlacement{}, nil, err
+			}
+		}
+
+		if isProxy {
+			amount = minimum
+
+			if hasLeader {
+				amount = proxyLeadAmount(minimum, maxAmount, leaderMax, increments)
+			}
+		}
+
+		newBid, err := record(models.Bid{ItemID: item.ID, BidderID: bidderID, Amount: amount, Kind: models.BidKindBid})
+
+		if err != nil {
+			return models.BidPlacement{}, nil, err
+		}
+
+		if isProxy && maxAmount > newBid.Amount {
+			_, err = b.ProxyBidRepository.UpsertProxyBid(tx, models.ProxyBid{ItemID: item.ID, BidderID: bidderID, MaxAmount: maxAmount})
+
+			if err != nil {
+				return models.BidPlacement{}, nil, err
+			}
+		}
+
+		return models.BidPlacement{Bid: newBid, Leading: true, CurrentBid: newBid.Amount, MaxAmount: request.MaxAmount}, placed, nil
+	}
+
+	// The leader's proxy outbids the new bidder straight away
+	response := proxyResponse(leaderMax, maxAmount, increments)
+	leaderBid := models.Bid{ItemID: item.ID, BidderID: leader.BidderID, Amount: response, Kind: models.BidKindProxy}
+	newBid := models.Bid{ItemID: item.ID, BidderID: bidderID, Amount: maxAmount, Kind: models.BidKindBid}
+
+	// On a tie the leader's answer is recorded first so it stays the earliest bid at that amount
+	order := []*models.Bid{&newBid, &leaderBid}
+
+	if response == maxAmount {
+		order = []*models.Bid{&leaderBid, &newBid}
+	}
+
+	for _, bid := range order {
+		*bid, err = record(*bid)
+
+		if err != nil {
+			return models.BidPlacement{}, nil, err
+		}
+	}
+
+	return models.BidPlacement{Bid: newBid, Leading: false, CurrentBid: response, MaxAmount: request.MaxAmount}, placed, nil
+}
+
+// Return the highest amount the leading bidder has committed to, their proxy maximum or their bid
+func (b *BidService) proxyMaximum(tx pgx.Tx, leader models.Bid) (models.Money, error) {
+	proxy, err := b.ProxyBidRepository.GetProxyBid(tx, leader.ItemID, leader.BidderID)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return leader.Amount, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return max(leader.Amount, proxy.MaxAmount), nil
+}
+
+// Let the leading bidder raise their own proxy maximum without bidding against themselves
+func (b *BidService) raiseLeaderMaximum(tx pgx.Tx, item models.Item, leader models.Bid, increments models.IncrementTable, isProxy bool, maxAmount models.Money) (models.BidPlacement, error) {
+	if !isProxy {
+		return models.BidPlacement{}, ErrAlreadyLeading
+	}
+
+	currentMax, err := b.proxyMaximum(tx, leader)
+
+	if err != nil {
+		return models.BidPlacement{}, err
+	}
+
+	if maxAmount <= currentMax {
+		return models.BidPlacement{}, &BidTooLowError{MinimumBid: currentMax + increments.StepFor(currentMax)}
+	}
+
+	_, err = b.ProxyBidRepository.UpsertProxyBid(tx, models.ProxyBid{ItemID: item.ID, BidderID: leader.BidderID, MaxAmount: maxAmount})
+
+	if err != nil {
+		return models.BidPlacement{}, err
+	}
+
+	return models.BidPlacement{Bid: leader, Leading: true, CurrentBid: item.BidAmount, MaxAmount: &maxAmount}, nil
+}
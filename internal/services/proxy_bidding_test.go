@@ -0,0 +1,109 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/bangueco/auction-api/internal/models"
+)
+
+func mustMoney(t *testing.T, s string) models.Money {
+	t.Helper()
+
+	m, err := models.ParseMoney(s)
+
+	if err != nil {
+		t.Fatalf("ParseMoney(%q): %v", s, err)
+	}
+
+	return m
+}
+
+func testIncrements(t *testing.T) models.IncrementTable {
+	t.Helper()
+
+	table, err := models.ParseIncrementTable("0:1,100:5,1000:10")
+
+	if err != nil {
+		t.Fatalf("ParseIncrementTable: %v", err)
+	}
+
+	return table
+}
+
+func TestMinimumBid(t *testing.T) {
+	increments := testIncrements(t)
+
+	tests := []struct {
+		name      string
+		bidAmount string
+		want      string
+	}{
+		{"starting price", "0", "1"},
+		{"below first tier change", "99", "100"},
+		{"at tier boundary", "100", "105"},
+		{"upper tier", "1500", "1510"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := models.Item{BidAmount: mustMoney(t, tt.bidAmount)}
+
+			if got := minimumBid(item, increments); got != mustMoney(t, tt.want) {
+				t.Errorf("minimumBid() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProxyLeadAmount(t *testing.T) {
+	increments := testIncrements(t)
+
+	tests := []struct {
+		name      string
+		minimum   string
+		maxAmount string
+		leaderMax string
+		want      string
+	}{
+		{"one increment above the leader", "51", "200", "50", "51"},
+		{"step of the leader's tier", "101", "500", "120", "125"},
+		{"capped at own maximum", "101", "122", "120", "122"},
+		{"never below the minimum", "130", "500", "120", "130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := proxyLeadAmount(mustMoney(t, tt.minimum), mustMoney(t, tt.maxAmount), mustMoney(t, tt.leaderMax), increments)
+
+			if got != mustMoney(t, tt.want) {
+				t.Errorf("proxyLeadAmount() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProxyResponse(t *testing.T) {
+	increments := testIncrements(t)
+
+	tests := []struct {
+		name      string
+		leaderMax string
+		maxAmount string
+		want      string
+	}{
+		{"one increment above the challenger", "500", "60", "61"},
+		{"step of the challenger's tier", "500", "200", "205"},
+		{"capped at the leader's maximum", "500", "498", "500"},
+		{"tie stays at the leader's maximum", "500", "500", "500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := proxyResponse(mustMoney(t, tt.leaderMax), mustMoney(t, tt.maxAmount), increments)
+
+			if got != mustMoney(t, tt.want) {
+				t.Errorf("proxyResponse() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Return the highest amount the next bid on a reverse auction may be placed at
+func maximumBid(item models.Item, increments models.IncrementTable) models.Money {
+	return item.BidAmount - increments.StepFor(item.BidAmount)
+}
+
+// Place a bid on a reverse auction inside the bid transaction.
+// It mirrors a plain bid on an english auction: the bid has to undercut the current price by at least
+// one increment and the lowest bid leads. Proxy bids are not offered since suppliers bid on their own terms.
+func (b *BidService) placeReverseBid(tx pgx.Tx, item models.Item, bidderID int64, request models.PlaceBidRequest) (models.BidPlacement, []models.Bid, error) {
+	if request.MaxAmount != nil {
+		return models.BidPlacement{}, nil, ErrProxyUnavailable
+	}
+
+	increments, err := b.IncrementService.TableFor(item)
+
+	if err != nil {
+		return models.BidPlacement{}, nil, err
+	}
+
+	maximum := maximumBid(item, increments)
+
+	if request.Amount > maximum {
+		return models.BidPlacement{}, nil, &BidTooHighError{MaximumBid: maximum}
+	}
+
+	leader, err := b.BidRepository.GetLowestBidTx(tx, item.ID)
+
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return models.BidPlacement{}, nil, err
+	}
+
+	if err == nil && leader.BidderID == bidderID {
+		return models.BidPlacement{}, nil, ErrAlreadyLeading
+	}
+
+	bid, err := b.BidRepository.CreateBid(tx, models.Bid{ItemID: item.ID, BidderID: bidderID, Amount: request.Amount, Kind: models.BidKindBid})
+
+	if err != nil {
+		return models.BidPlacement{}, nil, err
+	}
+
+	err = b.ItemRepository.RecordLowerBid(tx, item.ID, bid.Amount)
+
+	if err != nil {
+		return models.BidPlacement{}, nil, err
+	}
+
+	return models.BidPlacement{Bid: bid, Leading: true, CurrentBid: bid.Amount}, []models.Bid{bid}, nil
+}
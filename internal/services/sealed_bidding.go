@@ -0,0 +1,81 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrBidsSealed       = errors.New("bids stay sealed until the auction closes")
+	ErrSealedBidPlaced  = errors.New("bidder already holds a sealed bid on this item")
+	ErrSealedBidLowered = errors.New("a sealed bid can only be revised upwards")
+)
+
+// Place or revise a bidder's single sealed bid inside the bid transaction.
+// The item's current price is never touched so nothing about the bids leaks before the close,
+// only the bid count moves when a new bidder joins.
+func (b *BidService) placeSealedBid(tx pgx.Tx, item models.Item, bidderID int64, request models.PlaceBidRequest) (models.BidPlacement, error) {
+	if request.MaxAmount != nil {
+		return models.BidPlacement{}, ErrProxyUnavailable
+	}
+
+	if request.Amount < item.BidAmount {
+		return models.BidPlacement{}, &BidTooLowError{MinimumBid: item.BidAmount}
+	}
+
+	placement := models.BidPlacement{CurrentBid: item.BidAmount, Sealed: true}
+
+	existing, err := b.BidRepository.GetBidderBidTx(tx, item.ID, bidderID, models.BidKindSealed)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		placement.Bid, err = b.BidRepository.CreateBid(tx, models.Bid{ItemID: item.ID, BidderID: bidderID, Amount: request.Amount, Kind: models.BidKindSealed})
+
+		if err != nil {
+			return placement, err
+		}
+
+		// The starting price stays in bid_amount, so only the bid count is recorded
+		return placement, b.ItemRepository.RecordBid(tx, item.ID, 0)
+	}
+
+	if err != nil {
+		return placement, err
+	}
+
+	switch b.Rules.SealedRevisions {
+	case models.SealedRevisionAny:
+	case models.SealedRevisionIncrease:
+		if request.Amount <= existing.Amount {
+			return placement, ErrSealedBidLowered
+		}
+	default:
+		return placement, ErrSealedBidPlaced
+	}
+
+	placement.Bid, err = b.BidRepository.ReviseBid(tx, existing.ID, request.Amount)
+
+	return placement, err
+}
+
+// Work out the price the winner of a sealed auction pays.
+// In a first-price auction that is their own bid, in a vickrey auction it is the runner-up's bid,
+// raised to the starting price and the reserve when those are higher.
+func sealedHammerPrice(item models.Item, ranked []models.Bid) models.Money {
+	if item.AuctionType != models.AuctionTypeVickrey {
+		return ranked[0].Amount
+	}
+
+	price := item.BidAmount
+
+	if len(ranked) > 1 {
+		price = max(price, ranked[1].Amount)
+	}
+
+	if item.ReservePrice != nil {
+		price = max(price, *item.ReservePrice)
+	}
+
+	return min(price, ranked[0].Amount)
+}
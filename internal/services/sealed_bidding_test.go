@@ -0,0 +1,39 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/bangueco/auction-api/internal/models"
+)
+
+func TestSealedHammerPrice(t *testing.T) {
+	reserve := models.Money(700000)
+	highReserve := models.Money(1200000)
+
+	ranked := []models.Bid{{BidderID: 10, Amount: 1000000}, {BidderID: 11, Amount: 600000}, {BidderID: 12, Amount: 300000}}
+	single := []models.Bid{{BidderID: 10, Amount: 1000000}}
+
+	tests := []struct {
+		name   string
+		item   models.Item
+		ranked []models.Bid
+		want   models.Money
+	}{
+		{"first price pays own bid", models.Item{AuctionType: models.AuctionTypeSealedFirstPrice, BidAmount: 100000}, ranked, 1000000},
+		{"first price ignores the runner-up", models.Item{AuctionType: models.AuctionTypeSealedFirstPrice, BidAmount: 100000, ReservePrice: &reserve}, ranked, 1000000},
+		{"vickrey pays the runner-up", models.Item{AuctionType: models.AuctionTypeVickrey, BidAmount: 100000}, ranked, 600000},
+		{"vickrey with a single bid pays the start price", models.Item{AuctionType: models.AuctionTypeVickrey, BidAmount: 100000}, single, 100000},
+		{"vickrey start price above the runner-up", models.Item{AuctionType: models.AuctionTypeVickrey, BidAmount: 800000}, ranked, 800000},
+		{"vickrey raised to the reserve", models.Item{AuctionType: models.AuctionTypeVickrey, BidAmount: 100000, ReservePrice: &reserve}, ranked, 700000},
+		{"vickrey single bid raised to the reserve", models.Item{AuctionType: models.AuctionTypeVickrey, BidAmount: 100000, ReservePrice: &reserve}, single, 700000},
+		{"vickrey never above the winning bid", models.Item{AuctionType: models.AuctionTypeVickrey, BidAmount: 100000, ReservePrice: &highReserve}, ranked, 1000000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sealedHammerPrice(tt.item, tt.ranked); got != tt.want {
+				t.Errorf("sealedHammerPrice() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/repositories"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrInvalidStreamTicket = errors.New("stream ticket is invalid, expired or already used")
+
+// StreamTicketService hands out the single-use tickets live connections authenticate with.
+// Tickets live in the database, so one issued by any instance opens a connection to any other.
+type StreamTicketService struct {
+	StreamTicketRepository *repositories.StreamTicketRepository
+	// How long a ticket can be used for after it is issued
+	TTL time.Duration
+}
+
+func NewStreamTicketService(StreamTicketRepository *repositories.StreamTicketRepository, TTL time.Duration) *StreamTicketService {
+	return &StreamTicketService{StreamTicketRepository, TTL}
+}
+
+// Issue a new ticket for a user, only its hash is stored
+func (s *StreamTicketService) IssueTicket(userID int64) (models.StreamTicket, error) {
+	secret := make([]byte, 32)
+	rand.Read(secret)
+
+	ticket := models.StreamTicket{Ticket: hex.EncodeToString(secret), ExpiresAt: time.Now().Add(s.TTL)}
+
+	err := s.StreamTicketRepository.CreateStreamTicket(hashTicket(ticket.Ticket), userID, ticket.ExpiresAt)
+
+	if err != nil {
+		log.Printf("Error issuing stream ticket: %v", err)
+		return models.StreamTicket{}, err
+	}
+
+	return ticket, nil
+}
+
+// Use up a ticket and return the user it was issued to
+func (s *StreamTicketService) RedeemTicket(ticket string) (int64, error) {
+	userID, err := s.StreamTicketRepository.RedeemStreamTicket(hashTicket(ticket), time.Now())
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrInvalidStreamTicket
+	}
+
+	if err != nil {
+		log.Printf("Error redeeming stream ticket: %v", err)
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+// Background job that deletes tickets which expired without being used
+func (s *StreamTicketService) CleanExpired(ctx context.Context) error {
+	deleted, err := s.StreamTicketRepository.DeleteExpiredStreamTickets(time.Now())
+
+	if err != nil {
+		log.Printf("Error cleaning up stream tickets: %v", err)
+		return err
+	}
+
+	if deleted > 0 {
+		log.Printf("Cleaned up %d expired stream tickets", deleted)
+	}
+
+	return nil
+}
+
+func hashTicket(ticket string) string {
+	sum := sha256.Sum256([]byte(ticket))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -80,6 +80,11 @@ func (u *UserService) CreateUser(user models.User) (models.User, error) {
 	var userDetails = models.User{
 		Username: user.Username,
 		Password: hashedPassword,
+		Currency: user.Currency,
+	}
+
+	if userDetails.Currency == "" {
+		userDetails.Currency = models.DefaultCurrency
 	}
 
 	newUser, err := u.UserRepo.CreateUser(userDetails)
@@ -0,0 +1,450 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+
+	"github.com/bangueco/auction-api/internal/models"
+	"github.com/bangueco/auction-api/internal/repositories"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrInsufficientFunds = errors.New("not enough available funds in the wallet")
+	ErrLedgerUnbalanced  = errors.New("ledger invariants violated")
+)
+
+// WalletService keeps user balances in a double-entry ledger and sets funds aside for bids.
+//
+// Balances are never stored, they are the sum of an account's journal lines. Spending from an account
+// locks only that account, funds moving into an account never lock it, so two bids can not wait on each other's wallets.
+type WalletService struct {
+	LedgerRepository *repositories.LedgerRepository
+	// Whether bids need funds on hold, when off holds are never placed and every release or capture finds nothing to do
+	RequireFunds bool
+}
+
+func NewWalletService(LedgerRepository *repositories.LedgerRepository, RequireFunds bool) *WalletService {
+	return &WalletService{LedgerRepository, RequireFunds}
+}
+
+// Retrieve a user's available and held balances in every currency they have used
+func (w *WalletService) GetWallets(userID int64) ([]models.Wallet, error) {
+	wallets := []models.Wallet{}
+
+	accounts, err := w.LedgerRepository.GetUserAccounts(userID)
+
+	if err != nil {
+		log.Printf("Error retrieving wallet: %v", err)
+		return nil, err
+	}
+
+	for _, account := range accounts {
+		if len(wallets) == 0 || wallets[len(wallets)-1].Currency != account.Currency {
+			wallets = append(wallets, models.Wallet{Currency: account.Currency})
+		}
+
+		wallet := &wallets[len(wallets)-1]
+
+		switch account.Kind {
+		case models.AccountKindAvailable:
+			wallet.Available = account.Balance
+		case models.AccountKindHeld:
+			wallet.Held = account.Balance
+		}
+	}
+
+	return wallets, nil
+}
+
+// Retrieve a user's holds, newest first
+func (w *WalletService) GetHolds(userID int64, activeOnly bool) ([]models.Hold, error) {
+	holds, err := w.LedgerRepository.GetUserHolds(userID, activeOnly)
+
+	if err != nil {
+		log.Printf("Error retrieving holds: %v", err)
+		return nil, err
+	}
+
+	if holds == nil {
+		holds = []models.Hold{}
+	}
+
+	return holds, nil
+}
+
+// Credit a user's wallet with money paid into the platform
+func (w *WalletService) Deposit(request models.DepositRequest) ([]models.Wallet, error) {
+	if !request.Amount.FitsCurrency(request.Currency) {
+		return nil, models.ErrInvalidMoneyForCurrency
+	}
+
+	err := repositories.WithTx(w.LedgerRepository.DB, func(tx pgx.Tx) error {
+		funding, err := w.LedgerRepository.GetOrCreateAccount(tx, nil, models.AccountKindFunding, request.Currency)
+
+		if err != nil {
+			return err
+		}
+
+		available, err := w.LedgerRepository.GetOrCreateAccount(tx, &request.UserID, models.AccountKindAvailable, request.Currency)
+
+		if err != nil {
+			return err
+		}
+
+		return w.post(tx, models.Transfer(models.JournalEntryDeposit, nil, fmt.Sprintf("Deposit for user %d", request.UserID), funding.ID, available.ID, request.Amount))
+	})
+
+	if err != nil {
+		log.Printf("Error depositing funds: %v", err)
+		return nil, err
+	}
+
+	return w.GetWallets(request.UserID)
+}
+
+// Pay available funds out of a user's wallet, funds on hold can not be withdrawn
+func (w *WalletService) Withdraw(userID int64, request models.WithdrawalRequest) ([]models.Wallet, error) {
+	if !request.Amount.FitsCurrency(request.Currency) {
+		return nil, models.ErrInvalidMoneyForCurrency
+	}
+
+	err := repositories.WithTx(w.LedgerRepository.DB, func(tx pgx.Tx) error {
+		available, err := w.spendableAccount(tx, userID, request.Currency, request.Amount)
+
+		if err != nil {
+			return err
+		}
+
+		funding, err := w.LedgerRepository.GetOrCreateAccount(tx, nil, models.AccountKindFunding, request.Currency)
+
+		if err != nil {
+			return err
+		}
+
+		return w.post(tx, models.Transfer(models.JournalEntryWithdrawal, nil, fmt.Sprintf("Withdrawal for user %d", userID), available.ID, funding.ID, request.Amount))
+	})
+
+	if err != nil {
+		log.Printf("Error withdrawing funds: %v", err)
+		return nil, err
+	}
+
+	return w.GetWallets(userID)
+}
+
+// Set a user's hold on an item to amount inside the bid transaction, taking more from or
+// giving back to their available funds as needed. Does nothing unless bids require funds.
+func (w *WalletService) Reserve(tx pgx.Tx, userID int64, item models.Item, amount models.Money) error {
+	if !w.RequireFunds {
+		return nil
+	}
+
+	hold, err := w.LedgerRepository.GetActiveHold(tx, item.ID, userID)
+	hasHold := err == nil
+
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	if hasHold && hold.Amount == amount {
+		return nil
+	}
+
+	if hasHold && amount < hold.Amount {
+		return w.releasePart(tx, hold, hold.Amount-amount)
+	}
+
+	increase := amount
+
+	if hasHold {
+		increase = amount - hold.Amount
+	}
+
+	available, err := w.spendableAccount(tx, userID, item.Currency, increase)
+
+	if err != nil {
+		return err
+	}
+
+	held, err := w.LedgerRepository.GetOrCreateAccount(tx, &userID, models.AccountKindHeld, item.Currency)
+
+	if err != nil {
+		return err
+	}
+
+	err = w.post(tx, models.Transfer(models.JournalEntryHold, &item.ID, fmt.Sprintf("Hold for bids on item %d", item.ID), available.ID, held.ID, increase))
+
+	if err != nil {
+		return err
+	}
+
+	if hasHold {
+		_, err = w.LedgerRepository.UpdateHoldAmount(tx, hold.ID, amount)
+		return err
+	}
+
+	_, err = w.LedgerRepository.CreateHold(tx, models.Hold{UserID: userID, ItemID: item.ID, Currency: item.Currency, Amount: amount})
+
+	return err
+}
+
+// Release the holds on an item of every user not in keep, inside the transaction that outbid them
+func (w *WalletService) ReleaseHolds(tx pgx.Tx, itemID int64, keep ...int64) error {
+	holds, err := w.LedgerRepository.GetActiveItemHolds(tx, itemID)
+
+	if err != nil {
+		return err
+	}
+
+	for _, hold := range holds {
+		if slices.Contains(keep, hold.UserID) {
+			continue
+		}
+
+		err := w.releasePart(tx, hold, hold.Amount)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Lower every hold on an item to what its user can still owe, inside the bid transaction that changed it.
+// Users missing from owed have their holds released in full, holds are never raised here.
+func (w *WalletService) TrimHolds(tx pgx.Tx, itemID int64, owed map[int64]models.Money) error {
+	holds, err := w.LedgerRepository.GetActiveItemHolds(tx, itemID)
+
+	if err != nil {
+		return err
+	}
+
+	for _, hold := range holds {
+		if excess := holdExcess(hold, owed); excess > 0 {
+			err := w.releasePart(tx, hold, excess)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Return how much of a hold is more than its user can still owe
+func holdExcess(hold models.Hold, owed map[int64]models.Money) models.Money {
+	return max(hold.Amount-owed[hold.UserID], 0)
+}
+
+// Pay amount from a winner's hold on an item to the seller and release whatever is left of the hold.
+// A winner without a hold, because bids did not require funds when they bid, is left to pay for their order.
+func (w *WalletService) Capture(tx pgx.Tx, item models.Item, buyerID int64, sellerID int64, amount models.Money) error {
+	hold, err := w.LedgerRepository.GetActiveHold(tx, item.ID, buyerID)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	captured := min(amount, hold.Amount)
+
+	held, err := w.LedgerRepository.GetOrCreateAccount(tx, &buyerID, models.AccountKindHeld, hold.Currency)
+
+	if err != nil {
+		return err
+	}
+
+	seller, err := w.LedgerRepository.GetOrCreateAccount(tx, &sellerID, models.AccountKindAvailable, hold.Currency)
+
+	if err != nil {
+		return err
+	}
+
+	err = w.post(tx, models.Transfer(models.JournalEntryCapture, &item.ID, fmt.Sprintf("Payment for item %d", item.ID), held.ID, seller.ID, captured))
+
+	if err != nil {
+		return err
+	}
+
+	if captured < hold.Amount {
+		available, err := w.LedgerRepository.GetOrCreateAccount(tx, &buyerID, models.AccountKindAvailable, hold.Currency)
+
+		if err != nil {
+			return err
+		}
+
+		err = w.post(tx, models.Transfer(models.JournalEntryRelease, &item.ID, fmt.Sprintf("Release of the rest of the hold on item %d", item.ID), held.ID, available.ID, hold.Amount-captured))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = w.LedgerRepository.UpdateHoldStatus(tx, hold.ID, models.HoldStatusCaptured)
+
+	return err
+}
+
+// Capture what every winner of a closed item owes at the closing prices, release whatever their holds
+// had on top of that and every other hold on the item, inside the transaction that closed it. The owner of a reverse auction is not holding any funds.
+func (w *WalletService) Settle(tx pgx.Tx, item models.Item, allocations []models.Allocation) error {
+	owed := amountsOwed(item, allocations)
+
+	for _, buyerID := range slices.Sorted(maps.Keys(owed)) {
+		err := w.Capture(tx, item, buyerID, item.AuctionedBy, owed[buyerID])
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.ReleaseHolds(tx, item.ID)
+}
+
+// Sum what each winner of a closed item owes, per allocation for a multi-unit auction or the hammer price for a single winner
+func amountsOwed(item models.Item, allocations []models.Allocation) map[int64]models.Money {
+	owed := make(map[int64]models.Money)
+
+	for _, allocation := range allocations {
+		owed[allocation.BidderID] += allocation.TotalPrice
+	}
+
+	if len(allocations) == 0 && item.WinnerID != nil && item.HammerPrice != nil && item.AuctionType != models.AuctionTypeReverse {
+		owed[*item.WinnerID] = *item.HammerPrice
+	}
+
+	return owed
+}
+
+// Sum what has been captured from a buyer's holds on an item inside an existing transaction
+func (w *WalletService) CapturedAmount(tx pgx.Tx, itemID int64, buyerID int64) (models.Money, error) {
+	return w.LedgerRepository.GetCapturedAmount(tx, itemID, buyerID)
+}
+
+// Pay a captured amount back from the seller's available funds to the buyer's, inside the refund transaction
+func (w *WalletService) RefundCapture(tx pgx.Tx, itemID int64, buyerID int64, sellerID int64, currency string, amount models.Money) error {
+	seller, err := w.spendableAccount(tx, sellerID, currency, amount)
+
+	if err != nil {
+		return err
+	}
+
+	buyer, err := w.LedgerRepository.GetOrCreateAccount(tx, &buyerID, models.AccountKindAvailable, currency)
+
+	if err != nil {
+		return err
+	}
+
+	return w.post(tx, models.Transfer(models.JournalEntryRefund, &itemID, fmt.Sprintf("Refund for item %d", itemID), seller.ID, buyer.ID, amount))
+}
+
+// Background job that fails when the ledger's invariants no longer hold
+func (w *WalletService) VerifyLedger(ctx context.Context) error {
+	check, err := w.CheckLedger()
+
+	if err != nil {
+		return err
+	}
+
+	if !check.Balanced {
+		return ErrLedgerUnbalanced
+	}
+
+	return nil
+}
+
+// Check that every journal entry balances and held balances match the active holds
+func (w *WalletService) CheckLedger() (models.LedgerCheck, error) {
+	check, err := w.LedgerRepository.CheckLedger()
+
+	if err != nil {
+		log.Printf("Error checking ledger: %v", err)
+		return check, err
+	}
+
+	if !check.Balanced {
+		log.Printf("Ledger invariants violated: debits %s, credits %s, unbalanced entries %v, mismatched held accounts %v", check.TotalDebits, check.TotalCredits, check.UnbalancedEntries, check.MismatchedHeldAccounts)
+	}
+
+	return check, nil
+}
+
+// Move part of a hold back to its user's available funds, the hold is released once nothing is left of it
+func (w *WalletService) releasePart(tx pgx.Tx, hold models.Hold, amount models.Money) error {
+	held, err := w.LedgerRepository.GetOrCreateAccount(tx, &hold.UserID, models.AccountKindHeld, hold.Currency)
+
+	if err != nil {
+		return err
+	}
+
+	available, err := w.LedgerRepository.GetOrCreateAccount(tx, &hold.UserID, models.AccountKindAvailable, hold.Currency)
+
+	if err != nil {
+		return err
+	}
+
+	err = w.post(tx, models.Transfer(models.JournalEntryRelease, &hold.ItemID, fmt.Sprintf("Release of the hold on item %d", hold.ItemID), held.ID, available.ID, amount))
+
+	if err != nil {
+		return err
+	}
+
+	if amount < hold.Amount {
+		_, err = w.LedgerRepository.UpdateHoldAmount(tx, hold.ID, hold.Amount-amount)
+		return err
+	}
+
+	_, err = w.LedgerRepository.UpdateHoldStatus(tx, hold.ID, models.HoldStatusReleased)
+
+	return err
+}
+
+// Lock a user's available account and make sure amount can be taken from it
+func (w *WalletService) spendableAccount(tx pgx.Tx, userID int64, currency string, amount models.Money) (models.LedgerAccount, error) {
+	available, err := w.LedgerRepository.GetOrCreateAccount(tx, &userID, models.AccountKindAvailable, currency)
+
+	if err != nil {
+		return available, err
+	}
+
+	err = w.LedgerRepository.LockAccount(tx, available.ID)
+
+	if err != nil {
+		return available, err
+	}
+
+	balance, err := w.LedgerRepository.GetAccountBalance(tx, available.ID)
+
+	if err != nil {
+		return available, err
+	}
+
+	if balance < amount {
+		return available, ErrInsufficientFunds
+	}
+
+	return available, nil
+}
+
+// Check the entry balances before it is written, the database checks it again at commit
+func (w *WalletService) post(tx pgx.Tx, entry models.JournalEntry) error {
+	err := entry.Validate()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.LedgerRepository.CreateJournalEntry(tx, entry)
+
+	return err
+}
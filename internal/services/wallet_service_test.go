@@ -0,0 +1,77 @@
+package services
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/bangueco/auction-api/internal/models"
+)
+
+func TestAmountsOwed(t *testing.T) {
+	winnerID := int64(10)
+	hammerPrice := models.Money(1250000)
+
+	tests := []struct {
+		name        string
+		item        models.Item
+		allocations []models.Allocation
+		want        map[int64]models.Money
+	}{
+		{
+			name: "single winner pays the hammer price",
+			item: models.Item{AuctionType: models.AuctionTypeEnglish, WinnerID: &winnerID, HammerPrice: &hammerPrice},
+			want: map[int64]models.Money{10: 1250000},
+		},
+		{
+			name: "allocations summed per bidder",
+			item: models.Item{AuctionType: models.AuctionTypeEnglish, WinnerID: &winnerID, HammerPrice: &hammerPrice},
+			allocations: []models.Allocation{
+				{BidderID: 10, TotalPrice: 800000},
+				{BidderID: 11, TotalPrice: 400000},
+				{BidderID: 10, TotalPrice: 200000},
+			},
+			want: map[int64]models.Money{10: 1000000, 11: 400000},
+		},
+		{
+			name: "no winner owes nothing",
+			item: models.Item{AuctionType: models.AuctionTypeEnglish},
+			want: map[int64]models.Money{},
+		},
+		{
+			name: "reverse auction owner holds no funds",
+			item: models.Item{AuctionType: models.AuctionTypeReverse, WinnerID: &winnerID, HammerPrice: &hammerPrice},
+			want: map[int64]models.Money{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := amountsOwed(tt.item, tt.allocations); !maps.Equal(got, tt.want) {
+				t.Errorf("amountsOwed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHoldExcess(t *testing.T) {
+	owed := map[int64]models.Money{10: 800000, 11: 400000}
+
+	tests := []struct {
+		name string
+		hold models.Hold
+		want models.Money
+	}{
+		{"hold above what is owed", models.Hold{UserID: 10, Amount: 1000000}, 200000},
+		{"hold matching what is owed", models.Hold{UserID: 11, Amount: 400000}, 0},
+		{"hold below what is owed is never raised", models.Hold{UserID: 11, Amount: 300000}, 0},
+		{"user owing nothing is released in full", models.Hold{UserID: 12, Amount: 500000}, 500000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := holdExcess(tt.hold, owed); got != tt.want {
+				t.Errorf("holdExcess() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
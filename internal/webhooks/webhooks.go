@@ -0,0 +1,80 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Sender posts events to the endpoints configured to receive them.
+// Events can arrive more than once, receivers should use the X-Event-ID header to drop repeats.
+type Sender struct {
+	URLs   []string
+	Secret string
+	Client *http.Client
+}
+
+func NewSender(URLs []string, Secret string) *Sender {
+	return &Sender{URLs: URLs, Secret: Secret, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Post an event body to every endpoint, it only counts as sent once all of them answered with a 2xx status
+func (s *Sender) Send(ctx context.Context, eventID int64, body []byte) error {
+	var errs []error
+
+	for _, url := range s.URLs {
+		err := s.post(ctx, url, eventID, body)
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", url, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Sender) post(ctx context.Context, url string, eventID int64, body []byte) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Event-ID", strconv.FormatInt(eventID, 10))
+
+	if s.Secret != "" {
+		request.Header.Set("X-Webhook-Signature", Sign(s.Secret, body))
+	}
+
+	response, err := s.Client.Do(request)
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+
+	return nil
+}
+
+// Hex HMAC-SHA256 of a body, receivers compute the same with the shared secret to check it came from us
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
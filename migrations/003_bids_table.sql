@@ -0,0 +1,13 @@
+-- Write your migrate up statements here
+create table bids(
+  id serial primary key,
+  item_id integer not null references items(id) on delete cascade,
+  bidder_id integer not null references users(id),
+  amount float not null,
+  created_at timestamptz not null default now()
+);
+
+create index bids_item_id_amount_idx on bids(item_id, amount desc);
+
+---- create above / drop below ----
+drop table bids;
@@ -0,0 +1,16 @@
+-- Write your migrate up statements here
+alter table items
+  add column starts_at timestamptz,
+  add column ends_at timestamptz,
+  add column status varchar(20) not null default 'draft'
+    check (status in ('draft', 'scheduled', 'live', 'ended', 'cancelled', 'sold'));
+
+create index items_status_ends_at_idx on items(status, ends_at);
+
+---- create above / drop below ----
+drop index items_status_ends_at_idx;
+
+alter table items
+  drop column status,
+  drop column ends_at,
+  drop column starts_at;
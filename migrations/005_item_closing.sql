@@ -0,0 +1,11 @@
+-- Write your migrate up statements here
+alter table items
+  add column winner_id integer references users(id),
+  add column winning_bid_id integer references bids(id),
+  add column closed_at timestamptz;
+
+---- create above / drop below ----
+alter table items
+  drop column closed_at,
+  drop column winning_bid_id,
+  drop column winner_id;
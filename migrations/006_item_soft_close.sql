@@ -0,0 +1,11 @@
+-- Write your migrate up statements here
+alter table items
+  add column original_ends_at timestamptz,
+  add column extension_count integer not null default 0;
+
+update items set original_ends_at = ends_at;
+
+---- create above / drop below ----
+alter table items
+  drop column extension_count,
+  drop column original_ends_at;
@@ -0,0 +1,21 @@
+-- Write your migrate up statements here
+alter table items
+  add column reserve_price float,
+  add column bid_count integer not null default 0;
+
+update items set bid_count = (select count(*) from bids where bids.item_id = items.id);
+
+alter table items drop constraint items_status_check;
+alter table items add constraint items_status_check
+  check (status in ('draft', 'scheduled', 'live', 'ended', 'cancelled', 'sold', 'unsold'));
+
+---- create above / drop below ----
+update items set status = 'ended' where status = 'unsold';
+
+alter table items drop constraint items_status_check;
+alter table items add constraint items_status_check
+  check (status in ('draft', 'scheduled', 'live', 'ended', 'cancelled', 'sold'));
+
+alter table items
+  drop column bid_count,
+  drop column reserve_price;
@@ -0,0 +1,10 @@
+-- Write your migrate up statements here
+alter table items add column buy_now_price float;
+
+alter table bids add column kind varchar(20) not null default 'bid'
+  check (kind in ('bid', 'buy_now'));
+
+---- create above / drop below ----
+alter table bids drop column kind;
+
+alter table items drop column buy_now_price;
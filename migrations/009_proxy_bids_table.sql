@@ -0,0 +1,27 @@
+-- Write your migrate up statements here
+create table proxy_bids(
+  id serial primary key,
+  item_id integer not null references items(id) on delete cascade,
+  bidder_id integer not null references users(id),
+  max_amount float not null,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  unique (item_id, bidder_id)
+);
+
+alter table bids drop constraint bids_kind_check;
+alter table bids add constraint bids_kind_check check (kind in ('bid', 'proxy', 'buy_now'));
+
+---- create above / drop below ----
+-- Refuse to roll back over proxy bids rather than lose them, winners and orders may point at them
+do $$
+begin
+  if exists (select 1 from bids where kind = 'proxy') then
+    raise exception 'cannot roll back: bids placed by proxy exist';
+  end if;
+end $$;
+
+alter table bids drop constraint bids_kind_check;
+alter table bids add constraint bids_kind_check check (kind in ('bid', 'buy_now'));
+
+drop table proxy_bids;
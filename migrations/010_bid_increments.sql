@@ -0,0 +1,17 @@
+-- Write your migrate up statements here
+create table category_increments(
+  category varchar(100) primary key,
+  tiers jsonb not null,
+  updated_at timestamptz not null default now()
+);
+
+alter table items
+  add column category varchar(100),
+  add column increment_table jsonb;
+
+---- create above / drop below ----
+alter table items
+  drop column increment_table,
+  drop column category;
+
+drop table category_increments;
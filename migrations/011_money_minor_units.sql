@@ -0,0 +1,24 @@
+-- Write your migrate up statements here
+-- Amounts are stored as exact integers in minor units (hundredths of the currency unit)
+alter table items
+  alter column bid_amount type bigint using round(bid_amount * 100)::bigint,
+  alter column reserve_price type bigint using round(reserve_price * 100)::bigint,
+  alter column buy_now_price type bigint using round(buy_now_price * 100)::bigint;
+
+alter table bids
+  alter column amount type bigint using round(amount * 100)::bigint;
+
+alter table proxy_bids
+  alter column max_amount type bigint using round(max_amount * 100)::bigint;
+
+---- create above / drop below ----
+alter table proxy_bids
+  alter column max_amount type float using max_amount / 100.0;
+
+alter table bids
+  alter column amount type float using amount / 100.0;
+
+alter table items
+  alter column buy_now_price type float using buy_now_price / 100.0,
+  alter column reserve_price type float using reserve_price / 100.0,
+  alter column bid_amount type float using bid_amount / 100.0;
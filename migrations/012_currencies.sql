@@ -0,0 +1,21 @@
+-- Write your migrate up statements here
+alter table users add column currency char(3) not null default 'USD';
+
+alter table items add column currency char(3) not null default 'USD';
+
+update items set currency = users.currency from users where users.id = items.auctioned_by;
+
+-- Display only exchange rates, each rate is the number of units of the currency per unit of the base currency
+create table exchange_rates(
+  currency char(3) primary key,
+  rate numeric(20, 10) not null check (rate > 0),
+  is_base boolean not null default false,
+  updated_at timestamptz not null default now()
+);
+
+---- create above / drop below ----
+drop table exchange_rates;
+
+alter table items drop column currency;
+
+alter table users drop column currency;
@@ -0,0 +1,30 @@
+-- Write your migrate up statements here
+alter table items
+  add column auction_type varchar(20) not null default 'english',
+  add column floor_price bigint,
+  add column price_drop bigint,
+  add column price_drop_interval integer;
+
+alter table items add constraint items_auction_type_check check (auction_type in ('english', 'dutch'));
+
+alter table bids drop constraint bids_kind_check;
+alter table bids add constraint bids_kind_check check (kind in ('bid', 'proxy', 'buy_now', 'accept'));
+
+---- create above / drop below ----
+-- Refuse to roll back over dutch auctions rather than lose them, they have to be removed or converted by hand first
+do $$
+begin
+  if exists (select 1 from items where auction_type <> 'english') then
+    raise exception 'cannot roll back: items with an auction type other than english exist';
+  end if;
+end $$;
+
+alter table bids drop constraint bids_kind_check;
+alter table bids add constraint bids_kind_check check (kind in ('bid', 'proxy', 'buy_now'));
+
+alter table items
+  drop constraint items_auction_type_check,
+  drop column price_drop_interval,
+  drop column price_drop,
+  drop column floor_price,
+  drop column auction_type;
@@ -0,0 +1,34 @@
+-- Write your migrate up statements here
+alter table items drop constraint items_auction_type_check;
+alter table items add constraint items_auction_type_check
+  check (auction_type in ('english', 'dutch', 'sealed_first_price', 'vickrey'));
+
+-- Price the winner pays, which is not the winning bid in a vickrey auction
+alter table items add column hammer_price bigint;
+
+update items set hammer_price = bids.amount from bids where bids.id = items.winning_bid_id;
+
+alter table bids drop constraint bids_kind_check;
+alter table bids add constraint bids_kind_check check (kind in ('bid', 'proxy', 'buy_now', 'accept', 'sealed'));
+
+-- Every bidder gets a single sealed bid per auction
+create unique index bids_sealed_bidder_idx on bids (item_id, bidder_id) where kind = 'sealed';
+
+---- create above / drop below ----
+-- Refuse to roll back over sealed auctions rather than lose them, they have to be removed or converted by hand first
+do $$
+begin
+  if exists (select 1 from items where auction_type in ('sealed_first_price', 'vickrey')) or exists (select 1 from bids where kind = 'sealed') then
+    raise exception 'cannot roll back: sealed first-price or vickrey auctions exist';
+  end if;
+end $$;
+
+drop index bids_sealed_bidder_idx;
+
+alter table bids drop constraint bids_kind_check;
+alter table bids add constraint bids_kind_check check (kind in ('bid', 'proxy', 'buy_now', 'accept'));
+
+alter table items drop column hammer_price;
+
+alter table items drop constraint items_auction_type_check;
+alter table items add constraint items_auction_type_check check (auction_type in ('english', 'dutch'));
@@ -0,0 +1,32 @@
+-- Write your migrate up statements here
+alter table items drop constraint items_auction_type_check;
+alter table items add constraint items_auction_type_check
+  check (auction_type in ('english', 'dutch', 'sealed_first_price', 'vickrey', 'reverse'));
+
+-- Audit trail of every contract award a reverse auction owner makes
+create table awards(
+  id serial primary key,
+  item_id integer not null references items(id) on delete cascade,
+  bid_id integer not null references bids(id) on delete cascade,
+  previous_bid_id integer references bids(id) on delete set null,
+  awarded_by integer not null references users(id),
+  reason text,
+  created_at timestamptz not null default now()
+);
+
+create index awards_item_id_idx on awards (item_id, created_at);
+
+---- create above / drop below ----
+-- Refuse to roll back over reverse auctions rather than lose them, they have to be removed or converted by hand first
+do $$
+begin
+  if exists (select 1 from items where auction_type = 'reverse') then
+    raise exception 'cannot roll back: reverse auctions exist';
+  end if;
+end $$;
+
+drop table awards;
+
+alter table items drop constraint items_auction_type_check;
+alter table items add constraint items_auction_type_check
+  check (auction_type in ('english', 'dutch', 'sealed_first_price', 'vickrey'));
@@ -0,0 +1,30 @@
+-- Write your migrate up statements here
+alter table items
+  add column quantity integer not null default 1 check (quantity >= 1),
+  add column pricing_rule varchar(20) not null default 'uniform' check (pricing_rule in ('uniform', 'pay_as_bid'));
+
+alter table bids add column quantity integer not null default 1 check (quantity >= 1);
+
+-- Units of a multi-unit auction handed to each winning bid at the close
+create table allocations(
+  id serial primary key,
+  item_id integer not null references items(id) on delete cascade,
+  bid_id integer not null references bids(id) on delete cascade,
+  bidder_id integer not null references users(id),
+  quantity integer not null check (quantity >= 1),
+  unit_price bigint not null,
+  created_at timestamptz not null default now(),
+  unique (bid_id)
+);
+
+create index allocations_item_id_idx on allocations (item_id, bidder_id);
+create index allocations_bidder_id_idx on allocations (bidder_id, created_at);
+
+---- create above / drop below ----
+drop table allocations;
+
+alter table bids drop column quantity;
+
+alter table items
+  drop column pricing_rule,
+  drop column quantity;
@@ -0,0 +1,24 @@
+-- Write your migrate up statements here
+-- A lot sells several items together, its own auction item carries the bids and the timing
+create table lots(
+  id serial primary key,
+  name varchar(100) not null,
+  seller_id integer not null references users(id),
+  item_id integer not null unique references items(id) on delete cascade,
+  created_at timestamptz not null default now()
+);
+
+alter table items
+  add column lot_id integer references lots(id) on delete set null,
+  add column lot_position integer;
+
+create unique index items_lot_position_idx on items (lot_id, lot_position) where lot_id is not null;
+
+---- create above / drop below ----
+drop index items_lot_position_idx;
+
+alter table items
+  drop column lot_position,
+  drop column lot_id;
+
+drop table lots;
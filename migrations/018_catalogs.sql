@@ -0,0 +1,27 @@
+-- Write your migrate up statements here
+-- A catalog runs many lots as one sale, the lots open together and close one after another
+create table catalogs(
+  id serial primary key,
+  name varchar(100) not null,
+  seller_id integer not null references users(id),
+  starts_at timestamptz not null,
+  first_lot_ends_at timestamptz not null,
+  stagger_interval integer not null check (stagger_interval >= 1),
+  created_at timestamptz not null default now(),
+  check (first_lot_ends_at > starts_at)
+);
+
+alter table lots
+  add column catalog_id integer references catalogs(id) on delete set null,
+  add column catalog_position integer;
+
+create unique index lots_catalog_position_idx on lots (catalog_id, catalog_position) where catalog_id is not null;
+
+---- create above / drop below ----
+drop index lots_catalog_position_idx;
+
+alter table lots
+  drop column catalog_position,
+  drop column catalog_id;
+
+drop table catalogs;
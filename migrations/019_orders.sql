@@ -0,0 +1,51 @@
+-- Write your migrate up statements here
+-- The sale recorded for every winning bid once its auction has closed
+create table orders(
+  id serial primary key,
+  item_id integer not null references items(id),
+  bid_id integer not null references bids(id),
+  buyer_id integer not null references users(id),
+  seller_id integer not null references users(id),
+  currency char(3) not null,
+  quantity integer not null default 1 check (quantity >= 1),
+  total bigint not null,
+  payment_status varchar(20) not null default 'pending' check (payment_status in ('pending', 'paid', 'refunded', 'failed')),
+  paid_at timestamptz,
+  refunded_at timestamptz,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  unique (bid_id)
+);
+
+create index orders_item_id_idx on orders (item_id);
+create index orders_buyer_id_idx on orders (buyer_id, created_at);
+create index orders_seller_id_idx on orders (seller_id, created_at);
+
+-- What the buyer is charged, the lines of an order add up to its total
+create table order_lines(
+  id serial primary key,
+  order_id integer not null references orders(id) on delete cascade,
+  kind varchar(20) not null check (kind in ('hammer', 'buyers_premium', 'fee', 'tax')),
+  description text not null,
+  amount bigint not null
+);
+
+create index order_lines_order_id_idx on order_lines (order_id, id);
+
+-- Audit trail of every payment status change of an order
+create table payment_transitions(
+  id serial primary key,
+  order_id integer not null references orders(id) on delete cascade,
+  from_status varchar(20) not null,
+  to_status varchar(20) not null,
+  changed_by integer references users(id),
+  note text,
+  created_at timestamptz not null default now()
+);
+
+create index payment_transitions_order_id_idx on payment_transitions (order_id, created_at);
+
+---- create above / drop below ----
+drop table payment_transitions;
+drop table order_lines;
+drop table orders;
@@ -0,0 +1,71 @@
+-- Write your migrate up statements here
+-- Accounts of the double-entry ledger, the funding account with no user stands for money held outside the platform
+create table ledger_accounts(
+  id serial primary key,
+  user_id integer references users(id),
+  kind varchar(20) not null check (kind in ('available', 'held', 'funding')),
+  currency char(3) not null,
+  created_at timestamptz not null default now(),
+  unique nulls not distinct (user_id, kind, currency)
+);
+
+create table journal_entries(
+  id serial primary key,
+  kind varchar(20) not null check (kind in ('deposit', 'withdrawal', 'hold', 'release', 'capture')),
+  item_id integer references items(id),
+  description text not null,
+  created_at timestamptz not null default now()
+);
+
+create index journal_entries_item_id_idx on journal_entries (item_id);
+
+create table journal_lines(
+  id serial primary key,
+  entry_id integer not null references journal_entries(id),
+  account_id integer not null references ledger_accounts(id),
+  debit bigint not null default 0 check (debit >= 0),
+  credit bigint not null default 0 check (credit >= 0),
+  check ((debit = 0) <> (credit = 0))
+);
+
+create index journal_lines_entry_id_idx on journal_lines (entry_id);
+create index journal_lines_account_id_idx on journal_lines (account_id);
+
+-- Every journal entry has to balance by the time its transaction commits
+create function check_journal_entry_balanced() returns trigger as $$
+begin
+  if (select sum(debit) - sum(credit) from journal_lines where entry_id = new.entry_id) <> 0 then
+    raise exception 'journal entry % does not balance', new.entry_id;
+  end if;
+
+  return null;
+end;
+$$ language plpgsql;
+
+create constraint trigger journal_lines_balanced
+  after insert on journal_lines
+  deferrable initially deferred
+  for each row execute function check_journal_entry_balanced();
+
+-- Funds set aside from a bidder's wallet while their bid can still win
+create table holds(
+  id serial primary key,
+  user_id integer not null references users(id),
+  item_id integer not null references items(id),
+  currency char(3) not null,
+  amount bigint not null check (amount > 0),
+  status varchar(20) not null default 'active' check (status in ('active', 'released', 'captured')),
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create unique index holds_active_idx on holds (item_id, user_id) where status = 'active';
+create index holds_user_id_idx on holds (user_id, created_at);
+
+---- create above / drop below ----
+drop table holds;
+drop trigger journal_lines_balanced on journal_lines;
+drop function check_journal_entry_balanced();
+drop table journal_lines;
+drop table journal_entries;
+drop table ledger_accounts;
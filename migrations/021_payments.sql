@@ -0,0 +1,33 @@
+-- Write your migrate up statements here
+-- Part of the order already paid from funds captured out of the buyer's wallet at the close
+alter table orders add column wallet_amount bigint not null default 0 check (wallet_amount >= 0);
+
+-- Every call made to the payment provider for an order
+create table payment_transactions(
+  id serial primary key,
+  order_id integer not null references orders(id) on delete cascade,
+  provider varchar(50) not null,
+  kind varchar(20) not null check (kind in ('authorize', 'capture', 'refund')),
+  provider_transaction_id text not null,
+  amount bigint not null,
+  currency char(3) not null,
+  status varchar(20) not null check (status in ('succeeded', 'failed')),
+  failure_reason text,
+  created_at timestamptz not null default now(),
+  unique (provider, provider_transaction_id)
+);
+
+create index payment_transactions_order_id_idx on payment_transactions (order_id, id);
+
+alter table journal_entries drop constraint journal_entries_kind_check;
+alter table journal_entries add constraint journal_entries_kind_check
+  check (kind in ('deposit', 'withdrawal', 'hold', 'release', 'capture', 'refund'));
+
+---- create above / drop below ----
+alter table journal_entries drop constraint journal_entries_kind_check;
+alter table journal_entries add constraint journal_entries_kind_check
+  check (kind in ('deposit', 'withdrawal', 'hold', 'release', 'capture'));
+
+drop table payment_transactions;
+
+alter table orders drop column wallet_amount;
@@ -0,0 +1,24 @@
+-- Write your migrate up statements here
+-- Domain events written in the same transaction as the change they describe, the relay delivers them afterwards
+create table outbox(
+  id bigserial primary key,
+  -- Events of one aggregate are delivered in id order
+  aggregate_type varchar(20) not null check (aggregate_type in ('item', 'order')),
+  aggregate_id integer not null,
+  item_id integer not null,
+  event_type varchar(50) not null,
+  payload jsonb not null,
+  attempts integer not null default 0,
+  last_error text,
+  next_attempt_at timestamptz not null default now(),
+  -- When the event was handed to the event bus, webhooks may still be pending
+  published_at timestamptz,
+  delivered_at timestamptz,
+  created_at timestamptz not null default now()
+);
+
+create index outbox_pending_idx on outbox (aggregate_type, aggregate_id, id) where delivered_at is null;
+create index outbox_delivered_at_idx on outbox (delivered_at) where delivered_at is not null;
+
+---- create above / drop below ----
+drop table outbox;
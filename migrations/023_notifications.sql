@@ -0,0 +1,28 @@
+-- Write your migrate up statements here
+create table notifications(
+  id serial primary key,
+  user_id integer not null references users(id) on delete cascade,
+  kind varchar(20) not null check (kind in ('outbid', 'won', 'lost', 'reserve_not_met', 'ending_soon')),
+  item_id integer not null references items(id) on delete cascade,
+  message text not null,
+  -- Events are delivered at least once, the key keeps a repeated event from notifying twice
+  dedupe_key text not null unique,
+  read_at timestamptz,
+  created_at timestamptz not null default now()
+);
+
+create index notifications_user_id_idx on notifications (user_id, id desc);
+create index notifications_unread_idx on notifications (user_id) where read_at is null;
+
+-- Kinds a user opted out of or back into, every kind is on until a user turns it off
+create table notification_preferences(
+  user_id integer not null references users(id) on delete cascade,
+  kind varchar(20) not null check (kind in ('outbid', 'won', 'lost', 'reserve_not_met', 'ending_soon')),
+  enabled boolean not null,
+  updated_at timestamptz not null default now(),
+  primary key (user_id, kind)
+);
+
+---- create above / drop below ----
+drop table notification_preferences;
+drop table notifications;
@@ -0,0 +1,67 @@
+-- Write your migrate up statements here
+-- Amounts move from hundredths to ten-thousandths of the currency unit so that currencies with
+-- three or four decimal places, such as BHD and KWD, can be stored exactly
+update items set
+  bid_amount = bid_amount * 100,
+  reserve_price = reserve_price * 100,
+  buy_now_price = buy_now_price * 100,
+  floor_price = floor_price * 100,
+  price_drop = price_drop * 100,
+  hammer_price = hammer_price * 100;
+
+update bids set amount = amount * 100;
+
+update proxy_bids set max_amount = max_amount * 100;
+
+update allocations set unit_price = unit_price * 100;
+
+update orders set total = total * 100, wallet_amount = wallet_amount * 100;
+
+update order_lines set amount = amount * 100;
+
+update journal_lines set debit = debit * 100, credit = credit * 100;
+
+update holds set amount = amount * 100;
+
+update payment_transactions set amount = amount * 100;
+
+---- create above / drop below ----
+do $$
+begin
+  if exists (select 1 from items where (bid_amount % 100) <> 0 or (reserve_price % 100) <> 0 or (buy_now_price % 100) <> 0
+      or (floor_price % 100) <> 0 or (price_drop % 100) <> 0 or (hammer_price % 100) <> 0)
+    or exists (select 1 from bids where (amount % 100) <> 0)
+    or exists (select 1 from proxy_bids where (max_amount % 100) <> 0)
+    or exists (select 1 from allocations where (unit_price % 100) <> 0)
+    or exists (select 1 from orders where (total % 100) <> 0 or (wallet_amount % 100) <> 0)
+    or exists (select 1 from order_lines where (amount % 100) <> 0)
+    or exists (select 1 from journal_lines where (debit % 100) <> 0 or (credit % 100) <> 0)
+    or exists (select 1 from holds where (amount % 100) <> 0)
+    or exists (select 1 from payment_transactions where (amount % 100) <> 0) then
+    raise exception 'cannot roll back: some amounts have more than two decimal places';
+  end if;
+end $$;
+
+update payment_transactions set amount = amount / 100;
+
+update holds set amount = amount / 100;
+
+update journal_lines set debit = debit / 100, credit = credit / 100;
+
+update order_lines set amount = amount / 100;
+
+update orders set total = total / 100, wallet_amount = wallet_amount / 100;
+
+update allocations set unit_price = unit_price / 100;
+
+update proxy_bids set max_amount = max_amount / 100;
+
+update bids set amount = amount / 100;
+
+update items set
+  bid_amount = bid_amount / 100,
+  reserve_price = reserve_price / 100,
+  buy_now_price = buy_now_price / 100,
+  floor_price = floor_price / 100,
+  price_drop = price_drop / 100,
+  hammer_price = hammer_price / 100;
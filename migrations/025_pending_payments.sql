@@ -0,0 +1,26 @@
+-- Write your migrate up statements here
+-- A payment attempt is recorded as pending before the provider is called and completed with its answer afterwards,
+-- so the provider is never called while the order is locked and a retried attempt reuses its reference
+alter table payment_transactions alter column provider_transaction_id drop not null;
+
+alter table payment_transactions drop constraint payment_transactions_status_check;
+alter table payment_transactions add constraint payment_transactions_status_check
+  check (status in ('pending', 'succeeded', 'failed'));
+
+create unique index payment_transactions_pending_idx on payment_transactions (order_id) where status = 'pending';
+
+---- create above / drop below ----
+do $$
+begin
+  if exists (select 1 from payment_transactions where status = 'pending') then
+    raise exception 'cannot roll back: payment attempts are still pending';
+  end if;
+end $$;
+
+drop index payment_transactions_pending_idx;
+
+alter table payment_transactions drop constraint payment_transactions_status_check;
+alter table payment_transactions add constraint payment_transactions_status_check
+  check (status in ('succeeded', 'failed'));
+
+alter table payment_transactions alter column provider_transaction_id set not null;
@@ -0,0 +1,15 @@
+-- Write your migrate up statements here
+-- Short-lived single-use tickets that authenticate WebSocket and event stream connections,
+-- browsers cannot send an Authorization header on those and a token in the URL would end up in logs
+create table stream_tickets(
+  -- Only a hash of the ticket is kept, the ticket itself is handed to the client once
+  ticket_hash text primary key,
+  user_id integer not null references users(id) on delete cascade,
+  expires_at timestamptz not null,
+  created_at timestamptz not null default now()
+);
+
+create index stream_tickets_expires_at_idx on stream_tickets (expires_at);
+
+---- create above / drop below ----
+drop table stream_tickets;
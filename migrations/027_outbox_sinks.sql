@@ -0,0 +1,27 @@
+-- Write your migrate up statements here
+-- The event bus and the webhooks are tracked separately. Events are published to the bus once, in any order,
+-- while webhook deliveries are retried and kept in order per aggregate, so a failing endpoint no longer holds back live events
+alter table outbox rename column attempts to webhook_attempts;
+alter table outbox rename column last_error to webhook_last_error;
+alter table outbox rename column next_attempt_at to webhook_next_attempt_at;
+alter table outbox rename column delivered_at to webhook_delivered_at;
+
+drop index outbox_pending_idx;
+drop index outbox_delivered_at_idx;
+
+create index outbox_unpublished_idx on outbox (id) where published_at is null;
+create index outbox_webhook_pending_idx on outbox (aggregate_type, aggregate_id, id) where webhook_delivered_at is null;
+create index outbox_webhook_delivered_at_idx on outbox (webhook_delivered_at) where webhook_delivered_at is not null;
+
+---- create above / drop below ----
+drop index outbox_webhook_delivered_at_idx;
+drop index outbox_webhook_pending_idx;
+drop index outbox_unpublished_idx;
+
+alter table outbox rename column webhook_delivered_at to delivered_at;
+alter table outbox rename column webhook_next_attempt_at to next_attempt_at;
+alter table outbox rename column webhook_last_error to last_error;
+alter table outbox rename column webhook_attempts to attempts;
+
+create index outbox_pending_idx on outbox (aggregate_type, aggregate_id, id) where delivered_at is null;
+create index outbox_delivered_at_idx on outbox (delivered_at) where delivered_at is not null;
@@ -0,0 +1,19 @@
+-- Write your migrate up statements here
+-- Events are published to the bus at least once and in order per aggregate, like webhook deliveries:
+-- a relay leases an event, publishes it and only then marks it published, failures are retried
+alter table outbox add column publish_attempts integer not null default 0;
+alter table outbox add column publish_last_error text;
+alter table outbox add column publish_next_attempt_at timestamptz not null default now();
+
+drop index outbox_unpublished_idx;
+
+create index outbox_unpublished_idx on outbox (aggregate_type, aggregate_id, id) where published_at is null;
+
+---- create above / drop below ----
+drop index outbox_unpublished_idx;
+
+create index outbox_unpublished_idx on outbox (id) where published_at is null;
+
+alter table outbox drop column publish_next_attempt_at;
+alter table outbox drop column publish_last_error;
+alter table outbox drop column publish_attempts;
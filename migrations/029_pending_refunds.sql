@@ -0,0 +1,17 @@
+-- Write your migrate up statements here
+-- Refunds are recorded as pending before the provider is called too, an order can have one pending attempt of each kind
+drop index payment_transactions_pending_idx;
+
+create unique index payment_transactions_pending_idx on payment_transactions (order_id, kind) where status = 'pending';
+
+---- create above / drop below ----
+do $$
+begin
+  if exists (select 1 from payment_transactions where status = 'pending' group by order_id having count(*) > 1) then
+    raise exception 'cannot roll back: orders have both a payment and a refund pending';
+  end if;
+end $$;
+
+drop index payment_transactions_pending_idx;
+
+create unique index payment_transactions_pending_idx on payment_transactions (order_id) where status = 'pending';